@@ -2,14 +2,21 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Totus-Floreo/asperitas-on-go/internal/application"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/application/helpers"
 	"github.com/Totus-Floreo/asperitas-on-go/internal/middleware"
 	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	filesystem_repository "github.com/Totus-Floreo/asperitas-on-go/internal/repository/filesystem"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/repository/inmemory"
 	mongo_repository "github.com/Totus-Floreo/asperitas-on-go/internal/repository/mongo"
 	pgx_repository "github.com/Totus-Floreo/asperitas-on-go/internal/repository/pgx"
 	redis_repository "github.com/Totus-Floreo/asperitas-on-go/internal/repository/redis"
@@ -25,6 +32,9 @@ import (
 )
 
 func main() {
+	rebuildKarma := flag.Bool("rebuild-karma", false, "recompute the karma of every user from stored votes and exit")
+	flag.Parse()
+
 	zapLogger, _ := zap.NewProduction()
 	defer zapLogger.Sync()
 	logger := zapLogger.Sugar()
@@ -82,6 +92,13 @@ func main() {
 		return time.Now()
 	})
 
+	if err := pgx_repository.Migrate(context.Background(), pgxdb); err != nil {
+		logger.Panicln("Postgre migration error: ", err.Error())
+	}
+	if err := mongo_repository.Migrate(context.Background(), client.Database("asperitas")); err != nil {
+		logger.Panicln("Mongo migration error: ", err.Error())
+	}
+
 	userRepository := pgx_repository.NewUserStorage(pgxdb)
 	tokenRepository := redis_repository.NewTokenRepository(rdb)
 	JWTService := application.NewJWTService(os.Getenv("signature"), jwt.SigningMethodHS256, timeController)
@@ -92,41 +109,370 @@ func main() {
 		AuthService: authService,
 	}
 
+	moderatorStorage := inmemory.NewModeratorStorage(strings.Split(os.Getenv("moderators"), ",")...)
+
 	postStorage := mongo_repository.NewPostStorage(mongoClient, poolScheduler)
-	postService := application.NewPostService(postStorage)
+	banStorage := mongo_repository.NewBanStorage(mongoClient)
+	banService := application.NewBanService(banStorage, userRepository, tokenRepository, moderatorStorage, timeController)
+	if *rebuildKarma {
+		karmaService := application.NewKarmaService(postStorage, userRepository)
+		karmaService.SetBanChecker(banService)
+		users, err := karmaService.Rebuild(context.Background())
+		if err != nil {
+			logger.Panicln("Karma rebuild error: ", err.Error())
+		}
+		logger.Infow("karma rebuilt", "users", users)
+		return
+	}
+
+	postService := application.NewPostService(postStorage, moderatorStorage, timeController)
+	postService.SetKarmaStorage(userRepository)
+	postService.SetFetcher(helpers.NewFetcher(helpers.FetchPolicy{
+		AllowedHosts: strings.FieldsFunc(os.Getenv("fetch_allow_hosts"), isComma),
+		DeniedHosts:  strings.FieldsFunc(os.Getenv("fetch_deny_hosts"), isComma),
+	}))
+	if archiveAfter := os.Getenv("archive_after"); archiveAfter != "" {
+		age, err := time.ParseDuration(archiveAfter)
+		if err != nil {
+			logger.Panicln("Invalid archive_after: ", err.Error())
+		}
+		postService.SetArchiveAfter(age)
+	}
+	if duplicateWindow := os.Getenv("duplicate_window"); duplicateWindow != "" {
+		window, err := time.ParseDuration(duplicateWindow)
+		if err != nil {
+			logger.Panicln("Invalid duplicate_window: ", err.Error())
+		}
+		postService.SetDuplicateWindow(window)
+	}
+	if restoreWindow := os.Getenv("restore_window"); restoreWindow != "" {
+		window, err := time.ParseDuration(restoreWindow)
+		if err != nil {
+			logger.Panicln("Invalid restore_window: ", err.Error())
+		}
+		postService.SetRestoreWindow(window)
+	}
+	purgeInterval := time.Hour
+	if interval := os.Getenv("purge_interval"); interval != "" {
+		purgeInterval, err = time.ParseDuration(interval)
+		if err != nil || purgeInterval <= 0 {
+			logger.Panicln("Invalid purge_interval: ", interval)
+		}
+	}
+	go application.NewPurger(postService, logger).Run(context.Background(), purgeInterval)
+	postService.SetContentFilters(contentFilters(logger, timeController)...)
+
+	var eventBroker model.IEventBroker = inmemory.NewEventBroker()
+	if os.Getenv("broker") == "redis" {
+		redisBroker := redis_repository.NewEventBroker(rdb, eventBroker)
+		go func() {
+			if err := redisBroker.Run(context.Background()); err != nil {
+				logger.Errorw("redis event broker stopped", "error", err)
+			}
+		}()
+		eventBroker = redisBroker
+	}
+	postService.SetBroker(eventBroker)
+
+	eventHandler := &route.EventHandler{
+		Logger:      logger,
+		PostService: postService,
+		Broker:      eventBroker,
+	}
+
+	webSocketHandler := &route.WebSocketHandler{
+		Logger:       logger,
+		PostService:  postService,
+		Broker:       eventBroker,
+		JWTService:   JWTService,
+		TokenStorage: tokenRepository,
+	}
+
+	notificationStorage := mongo_repository.NewNotificationStorage(mongoClient)
+	notificationService := application.NewNotificationService(notificationStorage, userRepository, logger)
+	postService.SetNotifier(notificationService)
+
+	var linkQueue model.ILinkQueue = inmemory.NewLinkQueue()
+	if os.Getenv("link_queue") == "redis" {
+		linkQueue = redis_repository.NewLinkQueue(rdb)
+	}
+	linkWorkers := 4
+	if workers := os.Getenv("link_workers"); workers != "" {
+		linkWorkers, err = strconv.Atoi(workers)
+		if err != nil || linkWorkers < 1 {
+			logger.Panicln("Invalid link_workers: ", workers)
+		}
+	}
+	postService.SetLinkQueue(linkQueue)
+	if queued, err := postService.RequeuePendingLinks(context.Background()); err != nil {
+		logger.Errorw("failed to requeue pending link checks", "error", err)
+	} else if queued > 0 {
+		logger.Infow("requeued pending link checks", "posts", queued)
+	}
+	go application.NewLinkValidator(postService, linkQueue, logger).Run(context.Background(), linkWorkers)
+
+	notificationHandler := &route.NotificationHandler{
+		Logger:              logger,
+		NotificationService: notificationService,
+	}
+
+	profileService := application.NewProfileService(userRepository, postStorage)
+	profileService.SetBanChecker(banService)
+
+	profileHandler := &route.ProfileHandler{
+		Logger:         logger,
+		ProfileService: profileService,
+	}
+
+	blockStorage := mongo_repository.NewBlockStorage(mongoClient)
+	blockService := application.NewBlockService(blockStorage, userRepository)
+	postService.SetBlockStorage(blockStorage)
+	notificationService.SetBlockStorage(blockStorage)
+
+	blockHandler := &route.BlockHandler{
+		Logger:       logger,
+		BlockService: blockService,
+	}
+
+	modLogStorage := mongo_repository.NewModLogStorage(mongoClient)
+	modLogService := application.NewModLogService(modLogStorage, moderatorStorage)
+	postService.SetModLogStorage(modLogStorage)
+
+	modLogHandler := &route.ModLogHandler{
+		Logger:        logger,
+		ModLogService: modLogService,
+	}
+
+	banService.SetModLogStorage(modLogStorage)
+	banService.SetVoteRecounter(postService)
+	postService.SetBanChecker(banService)
+	authService.SetBanChecker(banService)
+
+	banHandler := &route.BanHandler{
+		Logger:     logger,
+		BanService: banService,
+	}
+
+	categoryStorage := mongo_repository.NewCategoryStorage(mongoClient)
+	categoryService := application.NewCategoryService(categoryStorage, userRepository, moderatorStorage)
+	categoryService.SetModLogStorage(modLogStorage)
+	postService.SetCategoryStorage(categoryStorage)
+	banService.SetCategoryStorage(categoryStorage)
+	modLogService.SetCategoryStorage(categoryStorage)
+
+	categoryHandler := &route.CategoryHandler{
+		Logger:          logger,
+		CategoryService: categoryService,
+	}
+
+	reportStorage := mongo_repository.NewReportStorage(mongoClient)
+	reportService := application.NewReportService(reportStorage, postService)
+	postService.SetReportStorage(reportStorage)
+
+	reportHandler := &route.ReportHandler{
+		Logger:        logger,
+		ReportService: reportService,
+	}
+
+	messageStorage := mongo_repository.NewMessageStorage(mongoClient)
+	messageService := application.NewMessageService(messageStorage, userRepository)
+	messageService.SetBlockStorage(blockStorage)
+
+	messageHandler := &route.MessageHandler{
+		Logger:         logger,
+		MessageService: messageService,
+	}
 
 	postHandler := &route.PostHandler{
 		Logger:      logger,
 		PostService: postService,
 	}
 
+	uploadsDir := os.Getenv("uploads_dir")
+	if uploadsDir == "" {
+		uploadsDir = "./web/uploads"
+	}
+	blobStorage, err := filesystem_repository.NewBlobStorage(uploadsDir)
+	if err != nil {
+		logger.Panicln("Uploads directory error: ", err.Error())
+	}
+
+	var uploadMaxSize int64 = 5 << 20
+	if size := os.Getenv("upload_max_size"); size != "" {
+		uploadMaxSize, err = strconv.ParseInt(size, 10, 64)
+		if err != nil {
+			logger.Panicln("Invalid upload_max_size: ", err.Error())
+		}
+	}
+	imageService := application.NewImageService(blobStorage, uploadMaxSize)
+	postService.SetImageService(imageService)
+
+	uploadHandler := &route.UploadHandler{
+		Logger:       logger,
+		ImageService: imageService,
+	}
+
+	// feeds and pages are cached publicly, so their links can't come from the request's Host header
+	publicURL, err := url.Parse(os.Getenv("public_url"))
+	if err != nil || publicURL.Scheme != "http" && publicURL.Scheme != "https" || publicURL.Host == "" {
+		logger.Panicln("Invalid public_url: ", os.Getenv("public_url"))
+	}
+
+	feedHandler := &route.FeedHandler{
+		Logger:      logger,
+		PostService: postService,
+		BaseURL:     publicURL.String(),
+	}
+
+	webHandler := &route.WebHandler{
+		Logger:      logger,
+		PostService: postService,
+		BaseURL:     publicURL.String(),
+	}
+
 	router := mux.NewRouter()
 	router.Use(middleware.Panic)
 	router.Use(middleware.AccessLog(logger))
 	router.PathPrefix("/static/").Handler(route.StaticHandler())
-	router.HandleFunc("/", route.WebHandler)
+	router.PathPrefix(application.UploadsPath).Handler(route.UploadsHandler(application.UploadsPath, blobStorage))
+	router.HandleFunc("/", webHandler.Serve)
+	router.HandleFunc("/sitemap.xml", webHandler.Sitemap).Methods("GET")
+	router.HandleFunc("/feeds/{format:rss|atom}", feedHandler.FrontPage).Methods("GET")
+	router.HandleFunc("/feeds/{format:rss|atom}/a/{category}", feedHandler.Category).Methods("GET")
+	router.HandleFunc("/feeds/{format:rss|atom}/u/{user}", feedHandler.User).Methods("GET")
+	router.HandleFunc("/feeds/{format:rss|atom}/post/{postID}", feedHandler.Comments).Methods("GET")
 
 	api := router.PathPrefix("/api").Subrouter()
+	api.Use(middleware.OptionalAuth(JWTService, tokenRepository))
 	api.HandleFunc("/register", userHandler.SignUp).Methods("POST")
 	api.HandleFunc("/login", userHandler.LogIn).Methods("POST")
 	api.HandleFunc("/posts/", postHandler.GetAllPosts).Methods("GET")
 	api.HandleFunc("/posts/{category}", postHandler.GetPostsByCategory).Methods("GET")
 	api.HandleFunc("/post/{postID}", postHandler.GetPostByID).Methods("GET")
 	api.HandleFunc("/user/{user}", postHandler.GetPostsByUser).Methods("GET")
+	api.HandleFunc("/user/{user}/comments", postHandler.GetCommentsByUser).Methods("GET")
+	api.HandleFunc("/duplicates", postHandler.GetDuplicates).Methods("GET")
+	api.HandleFunc("/categories", categoryHandler.GetCategories).Methods("GET")
+	api.HandleFunc("/categories/{category}", categoryHandler.GetCategory).Methods("GET")
+	api.HandleFunc("/profile/{user}", profileHandler.GetProfile).Methods("GET")
+	api.HandleFunc("/events", eventHandler.PostsEvents).Methods("GET")
+	api.HandleFunc("/post/{postID}/events", eventHandler.PostEvents).Methods("GET")
+	api.HandleFunc("/ws", webSocketHandler.Serve).Methods("GET")
 
 	apiAuth := router.PathPrefix("/api").Subrouter()
 
-	apiAuth.Use(middleware.Auth(JWTService, tokenRepository))
+	apiAuth.Use(middleware.Auth(JWTService, tokenRepository, banService))
 	apiAuth.HandleFunc("/posts", postHandler.AddPost).Methods("POST")
+	apiAuth.HandleFunc("/uploads", uploadHandler.Upload).Methods("POST")
+	apiAuth.HandleFunc("/notifications", notificationHandler.GetNotifications).Methods("GET")
+	apiAuth.HandleFunc("/notifications/read", notificationHandler.MarkRead).Methods("POST")
+	apiAuth.HandleFunc("/notifications/unread", notificationHandler.CountUnread).Methods("GET")
+	apiAuth.HandleFunc("/me/profile", profileHandler.UpdateProfile).Methods("PUT")
+	apiAuth.HandleFunc("/me/blocks", blockHandler.GetBlocks).Methods("GET")
+	apiAuth.HandleFunc("/me/blocks", blockHandler.Block).Methods("POST")
+	apiAuth.HandleFunc("/me/blocks/{user}", blockHandler.Unblock).Methods("DELETE")
+	apiAuth.HandleFunc("/conversations", messageHandler.GetConversations).Methods("GET")
+	apiAuth.HandleFunc("/conversations", messageHandler.SendMessage).Methods("POST")
+	apiAuth.HandleFunc("/conversations/unread", messageHandler.CountUnread).Methods("GET")
+	apiAuth.HandleFunc("/conversations/{conversationID}", messageHandler.GetMessages).Methods("GET")
+	apiAuth.HandleFunc("/conversations/{conversationID}", messageHandler.Reply).Methods("POST")
+	apiAuth.HandleFunc("/conversations/{conversationID}/read", messageHandler.MarkRead).Methods("POST")
 	apiAuth.HandleFunc("/post/{id}", postHandler.DeletePost).Methods("DELETE")
 	apiAuth.HandleFunc("/post/{id}", postHandler.AddComment).Methods("POST")
 	apiAuth.HandleFunc("/post/{postID}/{commentID}", postHandler.DeleteComment).Methods("DELETE")
+	apiAuth.HandleFunc("/post/{postID}/restore", postHandler.RestorePost).Methods("POST")
+	apiAuth.HandleFunc("/post/{postID}/comment/{commentID}/restore", postHandler.RestoreComment).Methods("POST")
 	apiAuth.HandleFunc("/post/{postID}/upvote", postHandler.Vote).Methods("GET")
 	apiAuth.HandleFunc("/post/{postID}/unvote", postHandler.Vote).Methods("GET")
 	apiAuth.HandleFunc("/post/{postID}/downvote", postHandler.Vote).Methods("GET")
+	apiAuth.HandleFunc("/post/{postID}/comment/{commentID}/upvote", postHandler.VoteComment).Methods("GET")
+	apiAuth.HandleFunc("/post/{postID}/comment/{commentID}/unvote", postHandler.VoteComment).Methods("GET")
+	apiAuth.HandleFunc("/post/{postID}/comment/{commentID}/downvote", postHandler.VoteComment).Methods("GET")
+	apiAuth.HandleFunc("/post/{postID}/poll", postHandler.VotePoll).Methods("POST")
+	apiAuth.HandleFunc("/post/{postID}/lock", postHandler.Moderate).Methods("POST")
+	apiAuth.HandleFunc("/post/{postID}/unlock", postHandler.Moderate).Methods("POST")
+	apiAuth.HandleFunc("/post/{postID}/archive", postHandler.Moderate).Methods("POST")
+	apiAuth.HandleFunc("/post/{postID}/unarchive", postHandler.Moderate).Methods("POST")
+	apiAuth.HandleFunc("/post/{postID}/approve", postHandler.Moderate).Methods("POST")
+	apiAuth.HandleFunc("/post/{postID}/reject", postHandler.Moderate).Methods("POST")
+	apiAuth.HandleFunc("/post/{postID}/comment/{commentID}/approve", postHandler.ModerateComment).Methods("POST")
+	apiAuth.HandleFunc("/post/{postID}/comment/{commentID}/reject", postHandler.ModerateComment).Methods("POST")
+	apiAuth.HandleFunc("/moderation/held", postHandler.GetHeld).Methods("GET")
+	apiAuth.HandleFunc("/moderation/train", postHandler.TrainFilters).Methods("POST")
+	apiAuth.HandleFunc("/report", reportHandler.Report).Methods("POST")
+	apiAuth.HandleFunc("/modlog", modLogHandler.GetModLog).Methods("GET")
+	apiAuth.HandleFunc("/moderation/bans", banHandler.GetBans).Methods("GET")
+	apiAuth.HandleFunc("/moderation/bans", banHandler.Ban).Methods("POST")
+	apiAuth.HandleFunc("/moderation/bans/{user}", banHandler.Unban).Methods("DELETE")
+	apiAuth.HandleFunc("/categories/{category}/rules", categoryHandler.SetRules).Methods("PUT")
+	apiAuth.HandleFunc("/categories/{category}/moderators", categoryHandler.AddModerator).Methods("POST")
+	apiAuth.HandleFunc("/categories/{category}/moderators/{user}", categoryHandler.RemoveModerator).Methods("DELETE")
+	apiAuth.HandleFunc("/moderation/reports", reportHandler.GetQueue).Methods("GET")
+	apiAuth.HandleFunc("/moderation/reports/post/{postID}/dismiss", reportHandler.Resolve).Methods("POST")
+	apiAuth.HandleFunc("/moderation/reports/post/{postID}/remove", reportHandler.Resolve).Methods("POST")
+	apiAuth.HandleFunc("/moderation/reports/post/{postID}/warn", reportHandler.Resolve).Methods("POST")
+	apiAuth.HandleFunc("/moderation/reports/post/{postID}/comment/{commentID}/dismiss", reportHandler.Resolve).Methods("POST")
+	apiAuth.HandleFunc("/moderation/reports/post/{postID}/comment/{commentID}/remove", reportHandler.Resolve).Methods("POST")
+	apiAuth.HandleFunc("/moderation/reports/post/{postID}/comment/{commentID}/warn", reportHandler.Resolve).Methods("POST")
 
-	router.NotFoundHandler = http.HandlerFunc(route.WebHandler)
+	router.NotFoundHandler = http.HandlerFunc(webHandler.Serve)
 
 	log.Println("Starting server on port" + os.Getenv("port"))
 	http.ListenAndServe(os.Getenv("port"), router)
 }
+
+// contentFilters builds the spam filter chain from the environment, cheap filters first.
+func contentFilters(logger *zap.SugaredLogger, timeController model.ITimeController) []model.IContentFilter {
+	filters := []model.IContentFilter{}
+
+	for _, action := range []string{model.FilterReject, model.FilterHold} {
+		if path := os.Getenv("filter_words_" + action); path != "" {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				logger.Panicln("Invalid filter_words_"+action+": ", err.Error())
+			}
+			wordFilter, err := application.NewWordFilter(application.ParseWordList(string(data)), action)
+			if err != nil {
+				logger.Panicln("Invalid filter_words_"+action+": ", err.Error())
+			}
+			filters = append(filters, wordFilter)
+		}
+		if domains := strings.FieldsFunc(os.Getenv("filter_domains_"+action), isComma); len(domains) > 0 {
+			filters = append(filters, application.NewDomainFilter(domains, action))
+		}
+	}
+
+	repeatLimit, repeatWindow := 3, 10*time.Minute
+	if limit := os.Getenv("filter_repeat_limit"); limit != "" {
+		var err error
+		if repeatLimit, err = strconv.Atoi(limit); err != nil {
+			logger.Panicln("Invalid filter_repeat_limit: ", err.Error())
+		}
+	}
+	if window := os.Getenv("filter_repeat_window"); window != "" {
+		var err error
+		if repeatWindow, err = time.ParseDuration(window); err != nil {
+			logger.Panicln("Invalid filter_repeat_window: ", err.Error())
+		}
+	}
+	if repeatLimit > 0 {
+		filters = append(filters, application.NewRepeatFilter(repeatLimit, repeatWindow, model.FilterHold, timeController))
+	}
+
+	if path := os.Getenv("spam_model"); path != "" {
+		spamModelStorage, err := filesystem_repository.NewSpamModelStorage(path)
+		if err != nil {
+			logger.Panicln("Invalid spam_model: ", err.Error())
+		}
+		bayesFilter, err := application.NewBayesFilter(context.Background(), spamModelStorage)
+		if err != nil {
+			logger.Panicln("Invalid spam_model: ", err.Error())
+		}
+		filters = append(filters, bayesFilter)
+	}
+	return filters
+}
+
+func isComma(r rune) bool {
+	return r == ','
+}
@@ -12,6 +12,7 @@ type AuthService struct {
 	userStorage  model.IUserStorage
 	tokenStorage model.ITokenStorage
 	jwtService   model.IJWTService
+	banChecker   model.IBanChecker
 }
 
 func NewAuthService(userStorage model.IUserStorage, tokenStorage model.ITokenStorage, jwtService model.IJWTService) *AuthService {
@@ -22,6 +23,11 @@ func NewAuthService(userStorage model.IUserStorage, tokenStorage model.ITokenSto
 	}
 }
 
+// SetBanChecker enables refusing to log in users banned site-wide.
+func (s *AuthService) SetBanChecker(banChecker model.IBanChecker) {
+	s.banChecker = banChecker
+}
+
 func (s *AuthService) SignUp(ctx context.Context, username string, password string) (string, error) {
 	if _, err := s.userStorage.GetUser(ctx, username); err == nil {
 		return "", model.ErrUserExist
@@ -54,6 +60,11 @@ func (s *AuthService) LogIn(ctx context.Context, username, password string) (str
 	if user.Password != password {
 		return "", model.ErrInvalidCredentials
 	}
+	if s.banChecker != nil {
+		if err := s.banChecker.CheckBanned(ctx, user.ID, ""); err != nil {
+			return "", err
+		}
+	}
 
 	token, err := s.tokenStorage.GetToken(ctx, user.ID)
 	if errors.Is(err, redis.Nil) {
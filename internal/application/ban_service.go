@@ -0,0 +1,262 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/middleware"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+)
+
+// BanService bans users site-wide or from a category, it is the IBanChecker the auth middleware and PostService enforce bans with.
+type BanService struct {
+	banStorage       model.IBanStorage
+	userStorage      model.IUserStorage
+	tokenStorage     model.ITokenStorage
+	moderatorStorage model.IModeratorStorage
+	timeController   model.ITimeController
+	modLogStorage    model.IModLogStorage
+	voteRecounter    model.IVoteRecounter
+	categoryStorage  model.ICategoryStorage
+}
+
+func NewBanService(banStorage model.IBanStorage, userStorage model.IUserStorage, tokenStorage model.ITokenStorage, moderatorStorage model.IModeratorStorage, timeController model.ITimeController) *BanService {
+	return &BanService{
+		banStorage:       banStorage,
+		userStorage:      userStorage,
+		tokenStorage:     tokenStorage,
+		moderatorStorage: moderatorStorage,
+		timeController:   timeController,
+	}
+}
+
+// SetModLogStorage enables recording bans in the moderation log.
+func (s *BanService) SetModLogStorage(modLogStorage model.IModLogStorage) {
+	s.modLogStorage = modLogStorage
+}
+
+// SetVoteRecounter enables recounting the posts a user voted on when a shadow ban starts or stops discounting their votes.
+func (s *BanService) SetVoteRecounter(voteRecounter model.IVoteRecounter) {
+	s.voteRecounter = voteRecounter
+}
+
+// SetCategoryStorage lets the moderators of a category ban users from it and list its bans.
+func (s *BanService) SetCategoryStorage(categoryStorage model.ICategoryStorage) {
+	s.categoryStorage = categoryStorage
+}
+
+// Ban bans the named user from the category, or site-wide when it is empty, for duration or for good when it is zero.
+// The moderators of a category may only ban from it, site-wide and shadow bans are for site moderators.
+// A site-wide ban also revokes the user's session token. A shadow ban is always site-wide and leaves the user logged in.
+func (s *BanService) Ban(ctx context.Context, username string, category string, shadow bool, reason string, duration time.Duration) (*model.Ban, error) {
+	moderator := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+	category = strings.TrimSpace(category)
+	if shadow {
+		category = ""
+	}
+	if err := s.checkModerator(ctx, category); err != nil {
+		return nil, err
+	}
+
+	reason = strings.TrimSpace(reason)
+	if utf8.RuneCountInString(reason) > model.ReportReasonMaxLength {
+		return nil, model.ErrReasonTooLong
+	}
+	if duration < 0 {
+		return nil, model.ErrInvalidBanDuration
+	}
+
+	user, err := s.getTarget(ctx, username, moderator)
+	if err != nil {
+		return nil, err
+	}
+	// moderators answer to the site owner, not to each other
+	if ok, err := moderates(ctx, s.moderatorStorage, s.categoryStorage, user, category); err != nil {
+		return nil, err
+	} else if ok {
+		return nil, model.ErrInvalidBanTarget
+	}
+
+	var expires *time.Time
+	if duration > 0 {
+		until := s.timeController.Now().Add(duration).UTC()
+		expires = &until
+	}
+	wasShadowBanned, err := s.isShadowBanned(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	ban := model.NewBan(user, category, shadow, reason, moderator, expires)
+	if err := s.banStorage.AddBan(ctx, ban); err != nil {
+		return nil, err
+	}
+	if shadow && !wasShadowBanned {
+		if err := s.recountVotes(ctx, user.ID, false); err != nil {
+			return nil, err
+		}
+	}
+
+	if ban.IsSiteWide() && !ban.Shadow {
+		if err := s.tokenStorage.DeleteToken(ctx, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	action := model.ModActionBan
+	if shadow {
+		action = model.ModActionShadowBan
+	}
+	if err := s.recordModAction(ctx, action, ban); err != nil {
+		return nil, err
+	}
+	return ban, nil
+}
+
+// Unban lifts the named user's ban from the category, or the site-wide one when it is empty, or their shadow ban.
+func (s *BanService) Unban(ctx context.Context, username string, category string, shadow bool) error {
+	moderator := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+	category = strings.TrimSpace(category)
+	if shadow {
+		category = ""
+	}
+	if err := s.checkModerator(ctx, category); err != nil {
+		return err
+	}
+
+	user, err := s.getTarget(ctx, username, moderator)
+	if err != nil {
+		return err
+	}
+	wasShadowBanned, err := s.isShadowBanned(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.banStorage.RemoveBan(ctx, user.ID, category, shadow); err != nil {
+		return err
+	}
+	if shadow && wasShadowBanned {
+		if err := s.recountVotes(ctx, user.ID, true); err != nil {
+			return err
+		}
+	}
+
+	action := model.ModActionUnban
+	if shadow {
+		action = model.ModActionShadowUnban
+	}
+	return s.recordModAction(ctx, action, &model.Ban{User: user, Category: category, Shadow: shadow})
+}
+
+// GetBans lists the bans in force, newest first. The moderators of a category only see the bans from their categories.
+func (s *BanService) GetBans(ctx context.Context) ([]*model.Ban, error) {
+	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+	all, moderated, err := moderatedCategories(ctx, s.moderatorStorage, s.categoryStorage, author)
+	if err != nil {
+		return nil, err
+	}
+	if !all && len(moderated) == 0 {
+		return nil, model.ErrUnAuthorized
+	}
+
+	bans, err := s.banStorage.GetBans(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := s.timeController.Now()
+	active := make([]*model.Ban, 0, len(bans))
+	for _, ban := range bans {
+		if ban.IsActive(now) && (all || moderated[ban.Category]) {
+			active = append(active, ban)
+		}
+	}
+	return active, nil
+}
+
+// CheckBanned returns a *model.BanError when the user is banned site-wide, or from the category when it is set.
+// Shadow bans are not reported, the user must not find out about them.
+func (s *BanService) CheckBanned(ctx context.Context, userID string, category string) error {
+	bans, err := s.banStorage.GetUserBans(ctx, userID)
+	if err != nil {
+		return err
+	}
+	now := s.timeController.Now()
+	for _, ban := range bans {
+		if ban.Shadow || !ban.IsActive(now) {
+			continue
+		}
+		if ban.IsSiteWide() || category != "" && ban.Category == category {
+			return &model.BanError{Ban: ban}
+		}
+	}
+	return nil
+}
+
+// ShadowBanned returns the IDs of the users with a shadow ban in force.
+func (s *BanService) ShadowBanned(ctx context.Context) (map[string]bool, error) {
+	bans, err := s.banStorage.GetShadowBans(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := s.timeController.Now()
+	shadowBanned := make(map[string]bool, len(bans))
+	for _, ban := range bans {
+		if ban.IsActive(now) {
+			shadowBanned[ban.User.ID] = true
+		}
+	}
+	return shadowBanned, nil
+}
+
+func (s *BanService) isShadowBanned(ctx context.Context, userID string) (bool, error) {
+	shadowBanned, err := s.ShadowBanned(ctx)
+	if err != nil {
+		return false, err
+	}
+	return shadowBanned[userID], nil
+}
+
+func (s *BanService) recountVotes(ctx context.Context, userID string, counted bool) error {
+	if s.voteRecounter == nil {
+		return nil
+	}
+	return s.voteRecounter.RecountVotes(ctx, userID, counted)
+}
+
+func (s *BanService) getTarget(ctx context.Context, username string, moderator *model.Author) (*model.Author, error) {
+	user, err := s.userStorage.GetUser(ctx, username)
+	if err == model.ErrUserNotFound {
+		return nil, model.ErrInvalidBanTarget
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == moderator.ID {
+		return nil, model.ErrInvalidBanTarget
+	}
+	return &model.Author{ID: user.ID, Username: user.Username}, nil
+}
+
+func (s *BanService) recordModAction(ctx context.Context, action string, ban *model.Ban) error {
+	if s.modLogStorage == nil {
+		return nil
+	}
+	moderator := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+
+	entry := model.NewModAction(action, moderator, ban.Reason)
+	entry.Category = ban.Category
+	entry.Target = ban.User
+	return s.modLogStorage.AddModAction(ctx, entry)
+}
+
+// checkModerator lets site moderators through, and the moderators of the category when it is set.
+func (s *BanService) checkModerator(ctx context.Context, category string) error {
+	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+	if ok, err := moderates(ctx, s.moderatorStorage, s.categoryStorage, author, category); err != nil {
+		return err
+	} else if !ok {
+		return model.ErrUnAuthorized
+	}
+	return nil
+}
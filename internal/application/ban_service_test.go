@@ -0,0 +1,197 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/middleware"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model/mocks"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/repository/inmemory"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBans(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userStorage := mocks.NewMockIUserStorage(ctrl)
+	userStorage.EXPECT().GetUser(gomock.Any(), "alice").Return(&model.User{ID: "id1", Username: "alice"}, nil).AnyTimes()
+	userStorage.EXPECT().GetUser(gomock.Any(), "nobody").Return(nil, model.ErrUserNotFound).AnyTimes()
+	userStorage.EXPECT().GetUser(gomock.Any(), "mod2").Return(&model.User{ID: "id4", Username: "mod2"}, nil).AnyTimes()
+	tokenStorage := mocks.NewMockITokenStorage(ctrl)
+	tokenStorage.EXPECT().DeleteToken(gomock.Any(), "id1").Return(nil).Times(1)
+
+	timeController := &FakeTimeController{fixedTime: time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)}
+	moderatorStorage := inmemory.NewModeratorStorage("mod", "mod2")
+	modLogStorage := inmemory.NewModLogStorage()
+	banService := NewBanService(inmemory.NewBanStorage(), userStorage, tokenStorage, moderatorStorage, timeController)
+	banService.SetModLogStorage(modLogStorage)
+	postService := NewPostService(new(FakePostStorage), moderatorStorage, timeController)
+	postService.SetBanChecker(banService)
+
+	alice := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id1", Username: "alice"})
+	mod := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id3", Username: "mod"})
+
+	_, err := banService.Ban(alice, "mod", "", false, "", 0)
+	require.Equal(t, model.ErrUnAuthorized, err)
+	_, err = banService.Ban(mod, "nobody", "", false, "", 0)
+	require.Equal(t, model.ErrInvalidBanTarget, err)
+	_, err = banService.Ban(mod, "mod2", "", false, "", 0)
+	require.Equal(t, model.ErrInvalidBanTarget, err)
+	_, err = banService.Ban(mod, "mod2", "news", true, "", 0)
+	require.Equal(t, model.ErrInvalidBanTarget, err)
+	_, err = banService.Ban(mod, "alice", "", false, "", -time.Hour)
+	require.Equal(t, model.ErrInvalidBanDuration, err)
+
+	music, err := postService.AddPost(alice, &model.Post{Type: "text", Title: "Music", Category: "music", Text: "hi"})
+	require.NoError(t, err)
+
+	ban, err := banService.Ban(mod, "alice", "news", false, "off topic", 0)
+	require.NoError(t, err)
+	require.Nil(t, ban.Expires)
+
+	_, err = postService.AddPost(alice, &model.Post{Type: "text", Title: "News", Category: "news", Text: "hi"})
+	var banErr *model.BanError
+	require.True(t, errors.As(err, &banErr))
+	require.JSONEq(t, `{"message":"you are banned from news","category":"news","reason":"off topic"}`, banErr.Error())
+	commented, err := postService.AddComment(alice, music.ID.Hex(), "still fine here", "")
+	require.NoError(t, err)
+	commentID := commented.Comments[len(commented.Comments)-1].ID.Hex()
+	require.NoError(t, banService.CheckBanned(context.Background(), "id1", ""))
+
+	ban, err = banService.Ban(mod, "alice", "", false, "spam", 24*time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, timeController.fixedTime.Add(24*time.Hour), *ban.Expires)
+
+	err = banService.CheckBanned(context.Background(), "id1", "")
+	require.True(t, errors.As(err, &banErr))
+	require.Equal(t, "spam", banErr.Ban.Reason)
+	_, err = postService.Vote(alice, music.ID.Hex(), "upvote")
+	require.True(t, errors.As(err, &banErr))
+
+	// banned users may take their content down but not bring it back
+	_, err = postService.DeleteComment(alice, music.ID.Hex(), commentID)
+	require.NoError(t, err)
+	_, err = postService.RestoreComment(alice, music.ID.Hex(), commentID)
+	require.True(t, errors.As(err, &banErr))
+	require.NoError(t, postService.DeletePost(alice, music.ID.Hex()))
+	_, err = postService.RestorePost(alice, music.ID.Hex())
+	require.True(t, errors.As(err, &banErr))
+
+	bans, err := banService.GetBans(mod)
+	require.NoError(t, err)
+	require.Len(t, bans, 2)
+
+	// the site-wide ban runs out, the category one is permanent
+	timeController.fixedTime = timeController.fixedTime.Add(25 * time.Hour)
+	require.NoError(t, banService.CheckBanned(context.Background(), "id1", ""))
+	bans, err = banService.GetBans(mod)
+	require.NoError(t, err)
+	require.Len(t, bans, 1)
+	_, err = postService.RestorePost(alice, music.ID.Hex())
+	require.NoError(t, err)
+	_, err = postService.RestoreComment(alice, music.ID.Hex(), commentID)
+	require.NoError(t, err)
+
+	require.NoError(t, banService.Unban(mod, "alice", "news", false))
+	require.Equal(t, model.ErrBanNotFound, banService.Unban(mod, "alice", "news", false))
+	_, err = postService.AddPost(alice, &model.Post{Type: "text", Title: "News", Category: "news", Text: "hi"})
+	require.NoError(t, err)
+
+	actions, err := modLogStorage.GetModActions(context.Background(), model.ModLogFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, actions, 3)
+	require.Equal(t, model.ModActionUnban, actions[0].Action)
+	require.Equal(t, model.ModActionBan, actions[1].Action)
+	require.Equal(t, "spam", actions[1].Reason)
+	require.Equal(t, "alice", actions[1].Target.Username)
+}
+
+func TestShadowBan(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userStorage := mocks.NewMockIUserStorage(ctrl)
+	userStorage.EXPECT().GetUser(gomock.Any(), "bob").Return(&model.User{ID: "id2", Username: "bob"}, nil).AnyTimes()
+	// a shadow ban must not log the user out
+	tokenStorage := mocks.NewMockITokenStorage(ctrl)
+
+	moderatorStorage := inmemory.NewModeratorStorage("mod")
+	modLogStorage := inmemory.NewModLogStorage()
+	banService := NewBanService(inmemory.NewBanStorage(), userStorage, tokenStorage, moderatorStorage, model.TimeControllerFunc(time.Now))
+	banService.SetModLogStorage(modLogStorage)
+	notifier := new(FakeNotifier)
+	postService := NewPostService(new(FakePostStorage), moderatorStorage, model.TimeControllerFunc(time.Now))
+	postService.SetBanChecker(banService)
+	postService.SetNotifier(notifier)
+	banService.SetVoteRecounter(postService)
+	modLogService := NewModLogService(modLogStorage, moderatorStorage)
+
+	alice := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id1", Username: "alice"})
+	bob := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id2", Username: "bob"})
+	mod := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id3", Username: "mod"})
+
+	ban, err := banService.Ban(mod, "bob", "news", true, "spam account", 0)
+	require.NoError(t, err)
+	require.True(t, ban.Shadow)
+	require.True(t, ban.IsSiteWide())
+	require.NoError(t, banService.CheckBanned(context.Background(), "id2", "news"))
+
+	news, err := postService.AddPost(alice, &model.Post{Type: "text", Title: "News", Category: "news", Text: "hi"})
+	require.NoError(t, err)
+	spam, err := postService.AddPost(bob, &model.Post{Type: "text", Title: "Spam", Category: "news", Text: "buy"})
+	require.NoError(t, err)
+	_, err = postService.AddComment(bob, news.ID.Hex(), "buy now", "")
+	require.NoError(t, err)
+	added, _ := notifier.counts()
+	require.Equal(t, 1, added)
+
+	posts, err := postService.GetAllPosts(alice)
+	require.NoError(t, err)
+	require.Len(t, posts, 1)
+	require.Empty(t, posts[0].Comments)
+	_, err = postService.GetPostComments(alice, spam.ID.Hex())
+	require.Equal(t, model.ErrPostNotFound, err)
+
+	posts, err = postService.GetAllPosts(bob)
+	require.NoError(t, err)
+	require.Len(t, posts, 2)
+	post, err := postService.GetPostComments(bob, news.ID.Hex())
+	require.NoError(t, err)
+	require.Len(t, post.Comments, 1)
+
+	post, err = postService.Vote(bob, news.ID.Hex(), "upvote")
+	require.NoError(t, err)
+	require.Len(t, post.Votes, 1)
+	require.Equal(t, int64(0), post.Score)
+	post, err = postService.Vote(alice, news.ID.Hex(), "upvote")
+	require.NoError(t, err)
+	require.Len(t, post.Votes, 2)
+	require.Equal(t, int64(1), post.Score)
+
+	actions, err := modLogService.GetModLog(bob, model.ModLogFilter{})
+	require.NoError(t, err)
+	require.Empty(t, actions)
+	actions, err = modLogService.GetModLog(mod, model.ModLogFilter{Action: model.ModActionShadowBan})
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+
+	require.NoError(t, banService.Unban(mod, "bob", "", true))
+	posts, err = postService.GetAllPosts(alice)
+	require.NoError(t, err)
+	require.Len(t, posts, 2)
+
+	// bob's vote counts again once the shadow ban is lifted, and stops counting again with a new shadow ban
+	post, err = postService.GetPostComments(alice, news.ID.Hex())
+	require.NoError(t, err)
+	require.Equal(t, int64(2), post.Score)
+	_, err = banService.Ban(mod, "bob", "", true, "", 0)
+	require.NoError(t, err)
+	post, err = postService.GetPostComments(alice, news.ID.Hex())
+	require.NoError(t, err)
+	require.Equal(t, int64(1), post.Score)
+}
@@ -0,0 +1,154 @@
+package application
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/application/helpers"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+)
+
+const (
+	defaultBayesHoldAt   = 0.9
+	defaultBayesRejectAt = 0.99
+	// defaultBayesMinDocs is how many examples of both spam and ham are needed before the classifier is trusted.
+	defaultBayesMinDocs = 20
+)
+
+// BayesFilter is a naive Bayes spam classifier trained on the moderators' decisions, its model is saved after every change.
+type BayesFilter struct {
+	storage  model.ISpamModelStorage
+	holdAt   float64
+	rejectAt float64
+	minDocs  int64
+
+	mu        sync.RWMutex
+	spamModel *model.SpamModel
+	// saveMu orders the saves, so the last one saved has every change made before it.
+	saveMu sync.Mutex
+}
+
+func NewBayesFilter(ctx context.Context, storage model.ISpamModelStorage) (*BayesFilter, error) {
+	spamModel, err := storage.LoadSpamModel(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &BayesFilter{
+		storage:   storage,
+		holdAt:    defaultBayesHoldAt,
+		rejectAt:  defaultBayesRejectAt,
+		minDocs:   defaultBayesMinDocs,
+		spamModel: spamModel,
+	}, nil
+}
+
+func (f *BayesFilter) Check(ctx context.Context, content *model.Content) (*model.FilterVerdict, error) {
+	f.mu.RLock()
+	trained := f.spamModel.SpamDocs >= f.minDocs && f.spamModel.HamDocs >= f.minDocs
+	f.mu.RUnlock()
+	if !trained {
+		return nil, nil
+	}
+
+	probability := f.SpamProbability(content)
+	reason := "spam probability " + strconv.FormatFloat(probability, 'f', 3, 64)
+	switch {
+	case probability >= f.rejectAt:
+		return &model.FilterVerdict{Action: model.FilterReject, Filter: "bayes", Reason: reason}, nil
+	case probability >= f.holdAt:
+		return &model.FilterVerdict{Action: model.FilterHold, Filter: "bayes", Reason: reason}, nil
+	}
+	return nil, nil
+}
+
+// SpamProbability returns how likely the content is spam, from the share of spam and ham examples containing its tokens.
+func (f *BayesFilter) SpamProbability(content *model.Content) float64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	spamDocs := float64(f.spamModel.SpamDocs)
+	hamDocs := float64(f.spamModel.HamDocs)
+	if spamDocs == 0 || hamDocs == 0 {
+		return 0.5
+	}
+
+	logSpam := math.Log(spamDocs / (spamDocs + hamDocs))
+	logHam := math.Log(hamDocs / (spamDocs + hamDocs))
+	for _, token := range tokenize(content) {
+		count, found := f.spamModel.Tokens[token]
+		if !found {
+			continue
+		}
+		logSpam += math.Log((float64(count.Spam) + 1) / (spamDocs + 2))
+		logHam += math.Log((float64(count.Ham) + 1) / (hamDocs + 2))
+	}
+	return 1 / (1 + math.Exp(logHam-logSpam))
+}
+
+// Train counts the content as an example of spam or ham and saves the model.
+func (f *BayesFilter) Train(ctx context.Context, content *model.Content, spam bool) error {
+	f.mu.Lock()
+	if spam {
+		f.spamModel.SpamDocs++
+	} else {
+		f.spamModel.HamDocs++
+	}
+	for _, token := range tokenize(content) {
+		count, found := f.spamModel.Tokens[token]
+		if !found {
+			count = new(model.TokenCount)
+			f.spamModel.Tokens[token] = count
+		}
+		if spam {
+			count.Spam++
+		} else {
+			count.Ham++
+		}
+	}
+	f.mu.Unlock()
+
+	return f.save(ctx)
+}
+
+// save writes a snapshot of the model, the classifier keeps working while it is written.
+func (f *BayesFilter) save(ctx context.Context) error {
+	f.saveMu.Lock()
+	defer f.saveMu.Unlock()
+
+	f.mu.RLock()
+	snapshot := f.spamModel.Copy()
+	f.mu.RUnlock()
+	return f.storage.SaveSpamModel(ctx, snapshot)
+}
+
+// tokenize returns the distinct lowercased words of the content and the hosts it links to.
+func tokenize(content *model.Content) []string {
+	seen := make(map[string]struct{})
+	tokens := []string{}
+	add := func(token string) {
+		if _, ok := seen[token]; ok {
+			return
+		}
+		seen[token] = struct{}{}
+		tokens = append(tokens, token)
+	}
+
+	text := content.Title + " " + content.Text
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		if length := utf8.RuneCountInString(word); length >= 2 && length <= 40 {
+			add(word)
+		}
+	}
+	for _, host := range helpers.LinkHosts(content.Url + " " + text) {
+		add("host:" + host)
+	}
+	return tokens
+}
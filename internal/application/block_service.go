@@ -0,0 +1,91 @@
+package application
+
+import (
+	"context"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/middleware"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+)
+
+type BlockService struct {
+	blockStorage model.IBlockStorage
+	userStorage  model.IUserStorage
+}
+
+func NewBlockService(blockStorage model.IBlockStorage, userStorage model.IUserStorage) *BlockService {
+	return &BlockService{
+		blockStorage: blockStorage,
+		userStorage:  userStorage,
+	}
+}
+
+// Block blocks or mutes the named user, calling it again switches between the two.
+func (s *BlockService) Block(ctx context.Context, username string, kind string) (*model.Block, error) {
+	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+
+	if kind == "" {
+		kind = model.BlockBlock
+	}
+	if kind != model.BlockBlock && kind != model.BlockMute {
+		return nil, model.ErrInvalidBlockType
+	}
+
+	target, err := s.getTarget(ctx, username, author)
+	if err != nil {
+		return nil, err
+	}
+
+	block := model.NewBlock(author.ID, target, kind)
+	if err := s.blockStorage.AddBlock(ctx, block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+func (s *BlockService) Unblock(ctx context.Context, username string) error {
+	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+
+	target, err := s.getTarget(ctx, username, author)
+	if err != nil {
+		return err
+	}
+	return s.blockStorage.RemoveBlock(ctx, author.ID, target.ID)
+}
+
+func (s *BlockService) GetBlocks(ctx context.Context) ([]*model.Block, error) {
+	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+	return s.blockStorage.GetBlocks(ctx, author.ID)
+}
+
+func (s *BlockService) getTarget(ctx context.Context, username string, author *model.Author) (*model.Author, error) {
+	user, err := s.userStorage.GetUser(ctx, username)
+	if err == model.ErrUserNotFound {
+		return nil, model.ErrInvalidBlockTarget
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == author.ID {
+		return nil, model.ErrInvalidBlockTarget
+	}
+	return &model.Author{ID: user.ID, Username: user.Username}, nil
+}
+
+// hiddenAuthors returns the IDs of users whose content the viewer in ctx does not want to see.
+func hiddenAuthors(ctx context.Context, blockStorage model.IBlockStorage) (map[string]bool, error) {
+	viewer, ok := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+	if blockStorage == nil || !ok {
+		return nil, nil
+	}
+
+	blocks, err := blockStorage.GetBlocks(ctx, viewer.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	hidden := make(map[string]bool, len(blocks))
+	for _, block := range blocks {
+		hidden[block.Target.ID] = true
+	}
+	return hidden, nil
+}
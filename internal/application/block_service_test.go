@@ -0,0 +1,115 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/middleware"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model/mocks"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/repository/inmemory"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestBlockService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userStorage := mocks.NewMockIUserStorage(ctrl)
+	blockStorage := inmemory.NewBlockStorage()
+	blockService := NewBlockService(blockStorage, userStorage)
+
+	alice := &model.Author{ID: "id1", Username: "alice"}
+	aliceCtx := context.WithValue(context.Background(), middleware.AuthorContextKey, alice)
+
+	userStorage.EXPECT().GetUser(gomock.Any(), "alice").Return(&model.User{ID: "id1", Username: "alice"}, nil).AnyTimes()
+	userStorage.EXPECT().GetUser(gomock.Any(), "troll").Return(&model.User{ID: "id2", Username: "troll"}, nil).AnyTimes()
+	userStorage.EXPECT().GetUser(gomock.Any(), "ghost").Return(nil, model.ErrUserNotFound).AnyTimes()
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := blockService.Block(aliceCtx, "alice", "")
+		require.Equal(t, model.ErrInvalidBlockTarget, err)
+
+		_, err = blockService.Block(aliceCtx, "ghost", "")
+		require.Equal(t, model.ErrInvalidBlockTarget, err)
+
+		_, err = blockService.Block(aliceCtx, "troll", "ignore")
+		require.Equal(t, model.ErrInvalidBlockType, err)
+	})
+
+	t.Run("Mute Then Block", func(t *testing.T) {
+		_, err := blockService.Block(aliceCtx, "troll", model.BlockMute)
+		require.NoError(t, err)
+
+		blocked, err := blockStorage.IsBlocked(context.Background(), "id1", "id2")
+		require.NoError(t, err)
+		require.False(t, blocked)
+
+		block, err := blockService.Block(aliceCtx, "troll", "")
+		require.NoError(t, err)
+		require.Equal(t, model.BlockBlock, block.Type)
+
+		blocked, err = blockStorage.IsBlocked(context.Background(), "id1", "id2")
+		require.NoError(t, err)
+		require.True(t, blocked)
+
+		blocks, err := blockService.GetBlocks(aliceCtx)
+		require.NoError(t, err)
+		require.Len(t, blocks, 1)
+		require.Equal(t, "troll", blocks[0].Target.Username)
+	})
+
+	t.Run("Blocked Messages", func(t *testing.T) {
+		messageService := NewMessageService(inmemory.NewMessageStorage(), userStorage)
+		messageService.SetBlockStorage(blockStorage)
+		trollCtx := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id2", Username: "troll"})
+
+		_, err := messageService.SendMessage(trollCtx, "alice", "hello")
+		require.Equal(t, model.ErrBlocked, err)
+
+		message, err := messageService.SendMessage(aliceCtx, "troll", "stop")
+		require.NoError(t, err)
+
+		_, err = messageService.Reply(trollCtx, message.ConversationID.Hex(), "no")
+		require.Equal(t, model.ErrBlocked, err)
+	})
+
+	t.Run("Filtered Listings", func(t *testing.T) {
+		postService := NewPostService(nil, nil, nil)
+		postService.SetBlockStorage(blockStorage)
+
+		troll := &model.Author{ID: "id2", Username: "troll"}
+		bob := &model.Author{ID: "id3", Username: "bob"}
+		posts := []*model.Post{
+			{ID: primitive.NewObjectID(), Author: troll},
+			{ID: primitive.NewObjectID(), Author: bob, Comments: []*model.Comment{
+				{ID: primitive.NewObjectID(), Author: troll},
+				{ID: primitive.NewObjectID(), Author: alice},
+			}},
+		}
+
+		anonymous, err := postService.filterVisible(context.Background(), posts)
+		require.NoError(t, err)
+		require.Len(t, anonymous, 2)
+
+		visible, err := postService.filterVisible(aliceCtx, posts)
+		require.NoError(t, err)
+		require.Len(t, visible, 1)
+		require.Equal(t, bob, visible[0].Author)
+		require.Len(t, visible[0].Comments, 1)
+		require.Equal(t, alice, visible[0].Comments[0].Author)
+
+		require.Equal(t, model.ErrBlocked, postService.checkBlocked(context.Background(), alice, troll))
+		require.NoError(t, postService.checkBlocked(context.Background(), troll, alice))
+	})
+
+	t.Run("Unblock", func(t *testing.T) {
+		require.NoError(t, blockService.Unblock(aliceCtx, "troll"))
+
+		blocks, err := blockService.GetBlocks(aliceCtx)
+		require.NoError(t, err)
+		require.Empty(t, blocks)
+	})
+}
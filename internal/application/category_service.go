@@ -0,0 +1,234 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/middleware"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+)
+
+// CategoryService manages the rules and the own moderators of the categories.
+// Site moderators appoint category moderators, both may change the rules.
+type CategoryService struct {
+	categoryStorage  model.ICategoryStorage
+	userStorage      model.IUserStorage
+	moderatorStorage model.IModeratorStorage
+	modLogStorage    model.IModLogStorage
+}
+
+func NewCategoryService(categoryStorage model.ICategoryStorage, userStorage model.IUserStorage, moderatorStorage model.IModeratorStorage) *CategoryService {
+	return &CategoryService{
+		categoryStorage:  categoryStorage,
+		userStorage:      userStorage,
+		moderatorStorage: moderatorStorage,
+	}
+}
+
+// SetModLogStorage enables recording changes of the categories in the moderation log.
+func (s *CategoryService) SetModLogStorage(modLogStorage model.IModLogStorage) {
+	s.modLogStorage = modLogStorage
+}
+
+// GetCategories lists the categories that have rules or moderators, by name.
+func (s *CategoryService) GetCategories(ctx context.Context) ([]*model.Category, error) {
+	categories, err := s.categoryStorage.GetCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, category := range categories {
+		normalizeCategory(category)
+	}
+	return categories, nil
+}
+
+// GetCategory returns the category, one that was never set up has no rules and no moderators.
+func (s *CategoryService) GetCategory(ctx context.Context, name string) (*model.Category, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, model.ErrInvalidCategory
+	}
+	return getCategory(ctx, s.categoryStorage, name)
+}
+
+// SetRules replaces the rules of the category, empty lines are dropped.
+func (s *CategoryService) SetRules(ctx context.Context, name string, rules []string) (*model.Category, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, model.ErrInvalidCategory
+	}
+	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+	if ok, err := moderates(ctx, s.moderatorStorage, s.categoryStorage, author, name); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, model.ErrUnAuthorized
+	}
+
+	cleaned := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		if utf8.RuneCountInString(rule) > model.CategoryRuleMaxLength {
+			return nil, model.ErrInvalidRules
+		}
+		cleaned = append(cleaned, rule)
+	}
+	if len(cleaned) > model.CategoryMaxRules {
+		return nil, model.ErrInvalidRules
+	}
+
+	if err := s.categoryStorage.SetRules(ctx, name, cleaned); err != nil {
+		return nil, err
+	}
+	if err := s.recordModAction(ctx, model.ModActionEditRules, name, nil); err != nil {
+		return nil, err
+	}
+	return getCategory(ctx, s.categoryStorage, name)
+}
+
+// AddModerator makes the named user a moderator of the category, for site moderators only.
+func (s *CategoryService) AddModerator(ctx context.Context, name string, username string) (*model.Category, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, model.ErrInvalidCategory
+	}
+	if err := s.checkSiteModerator(ctx); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userStorage.GetUser(ctx, username)
+	if err == model.ErrUserNotFound {
+		return nil, model.ErrInvalidModeratorTarget
+	}
+	if err != nil {
+		return nil, err
+	}
+	moderator := &model.Author{ID: user.ID, Username: user.Username}
+
+	if err := s.categoryStorage.AddModerator(ctx, name, moderator); err != nil {
+		return nil, err
+	}
+	if err := s.recordModAction(ctx, model.ModActionAddMod, name, moderator); err != nil {
+		return nil, err
+	}
+	return getCategory(ctx, s.categoryStorage, name)
+}
+
+// RemoveModerator takes the category away from the named moderator, for site moderators only.
+func (s *CategoryService) RemoveModerator(ctx context.Context, name string, username string) (*model.Category, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, model.ErrInvalidCategory
+	}
+	if err := s.checkSiteModerator(ctx); err != nil {
+		return nil, err
+	}
+
+	category, err := getCategory(ctx, s.categoryStorage, name)
+	if err != nil {
+		return nil, err
+	}
+	var moderator *model.Author
+	for _, candidate := range category.Moderators {
+		if candidate.Username == username {
+			moderator = candidate
+		}
+	}
+	if moderator == nil {
+		return nil, model.ErrModeratorNotFound
+	}
+
+	if err := s.categoryStorage.RemoveModerator(ctx, name, moderator.ID); err != nil {
+		return nil, err
+	}
+	if err := s.recordModAction(ctx, model.ModActionRemoveMod, name, moderator); err != nil {
+		return nil, err
+	}
+	return getCategory(ctx, s.categoryStorage, name)
+}
+
+func (s *CategoryService) recordModAction(ctx context.Context, action string, category string, target *model.Author) error {
+	if s.modLogStorage == nil {
+		return nil
+	}
+	moderator := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+
+	entry := model.NewModAction(action, moderator, "")
+	entry.Category = category
+	entry.Target = target
+	return s.modLogStorage.AddModAction(ctx, entry)
+}
+
+func (s *CategoryService) checkSiteModerator(ctx context.Context) error {
+	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+	if ok, err := s.moderatorStorage.IsModerator(ctx, author.Username); err != nil {
+		return err
+	} else if !ok {
+		return model.ErrUnAuthorized
+	}
+	return nil
+}
+
+func getCategory(ctx context.Context, categoryStorage model.ICategoryStorage, name string) (*model.Category, error) {
+	category, err := categoryStorage.GetCategory(ctx, name)
+	if err == model.ErrCategoryNotFound {
+		return model.NewCategory(name), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	normalizeCategory(category)
+	return category, nil
+}
+
+// normalizeCategory fills in what a category stored with only its rules or only its moderators lacks.
+func normalizeCategory(category *model.Category) {
+	if category.Rules == nil {
+		category.Rules = []string{}
+	}
+	if category.Moderators == nil {
+		category.Moderators = []*model.Author{}
+	}
+}
+
+// moderates reports whether the user is a site moderator or one of the category's own moderators.
+func moderates(ctx context.Context, moderatorStorage model.IModeratorStorage, categoryStorage model.ICategoryStorage, author *model.Author, category string) (bool, error) {
+	if ok, err := moderatorStorage.IsModerator(ctx, author.Username); err != nil || ok {
+		return ok, err
+	}
+	if categoryStorage == nil || category == "" {
+		return false, nil
+	}
+	stored, err := categoryStorage.GetCategory(ctx, category)
+	if err == model.ErrCategoryNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return stored.IsModerator(author.ID), nil
+}
+
+// moderatedCategories returns the categories the user moderates, or all as true for a site moderator.
+func moderatedCategories(ctx context.Context, moderatorStorage model.IModeratorStorage, categoryStorage model.ICategoryStorage, author *model.Author) (bool, map[string]bool, error) {
+	if ok, err := moderatorStorage.IsModerator(ctx, author.Username); err != nil || ok {
+		return ok, nil, err
+	}
+	moderated := make(map[string]bool)
+	if categoryStorage == nil {
+		return false, moderated, nil
+	}
+	categories, err := categoryStorage.GetCategories(ctx)
+	if err != nil {
+		return false, nil, err
+	}
+	for _, category := range categories {
+		if category.IsModerator(author.ID) {
+			moderated[category.Name] = true
+		}
+	}
+	return false, moderated, nil
+}
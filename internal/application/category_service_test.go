@@ -0,0 +1,153 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/middleware"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model/mocks"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/repository/inmemory"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategoryModerators(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userStorage := mocks.NewMockIUserStorage(ctrl)
+	userStorage.EXPECT().GetUser(gomock.Any(), "carol").Return(&model.User{ID: "id4", Username: "carol"}, nil).AnyTimes()
+	userStorage.EXPECT().GetUser(gomock.Any(), "nobody").Return(nil, model.ErrUserNotFound).AnyTimes()
+	userStorage.EXPECT().GetUser(gomock.Any(), "alice").Return(&model.User{ID: "id1", Username: "alice"}, nil).AnyTimes()
+
+	moderatorStorage := inmemory.NewModeratorStorage("mod")
+	categoryStorage := inmemory.NewCategoryStorage()
+	modLogStorage := inmemory.NewModLogStorage()
+	categoryService := NewCategoryService(categoryStorage, userStorage, moderatorStorage)
+	categoryService.SetModLogStorage(modLogStorage)
+	postService := NewPostService(new(FakePostStorage), moderatorStorage, model.TimeControllerFunc(time.Now))
+	postService.SetCategoryStorage(categoryStorage)
+	postService.SetModLogStorage(modLogStorage)
+	reportService := NewReportService(inmemory.NewReportStorage(), postService)
+	modLogService := NewModLogService(modLogStorage, moderatorStorage)
+	modLogService.SetCategoryStorage(categoryStorage)
+	banService := NewBanService(inmemory.NewBanStorage(), userStorage, nil, moderatorStorage, model.TimeControllerFunc(time.Now))
+	banService.SetCategoryStorage(categoryStorage)
+
+	alice := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id1", Username: "alice"})
+	bob := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id2", Username: "bob"})
+	mod := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id3", Username: "mod"})
+	carol := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id4", Username: "carol"})
+
+	category, err := categoryService.GetCategory(bob, "music")
+	require.NoError(t, err)
+	require.Equal(t, model.NewCategory("music"), category)
+
+	_, err = categoryService.AddModerator(carol, "music", "carol")
+	require.Equal(t, model.ErrUnAuthorized, err)
+	_, err = categoryService.AddModerator(mod, "music", "nobody")
+	require.Equal(t, model.ErrInvalidModeratorTarget, err)
+	category, err = categoryService.AddModerator(mod, "music", "carol")
+	require.NoError(t, err)
+	require.Equal(t, []*model.Author{{ID: "id4", Username: "carol"}}, category.Moderators)
+
+	_, err = categoryService.SetRules(carol, "news", []string{"No spam"})
+	require.Equal(t, model.ErrUnAuthorized, err)
+	category, err = categoryService.SetRules(carol, "music", []string{"No spam", " ", "Music only"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"No spam", "Music only"}, category.Rules)
+
+	categories, err := categoryService.GetCategories(bob)
+	require.NoError(t, err)
+	require.Len(t, categories, 1)
+	require.Equal(t, "music", categories[0].Name)
+
+	music, err := postService.AddPost(alice, &model.Post{Type: "text", Title: "Music", Category: "music", Text: "hi"})
+	require.NoError(t, err)
+	news, err := postService.AddPost(alice, &model.Post{Type: "text", Title: "News", Category: "news", Text: "hi"})
+	require.NoError(t, err)
+
+	_, err = postService.Moderate(carol, news.ID.Hex(), "lock", "", 0)
+	require.Equal(t, model.ErrUnAuthorized, err)
+	_, err = postService.Moderate(carol, music.ID.Hex(), "lock", "", 3)
+	require.Equal(t, model.ErrInvalidRule, err)
+	post, err := postService.Moderate(carol, music.ID.Hex(), "lock", "", 2)
+	require.NoError(t, err)
+	require.True(t, post.Locked)
+
+	entries, err := modLogStorage.GetModActions(context.Background(), model.ModLogFilter{Action: model.ModActionLock, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, &model.CitedRule{Number: 2, Text: "Music only"}, entries[0].Rule)
+
+	_, err = reportService.Report(bob, music.ID.Hex(), "", "spam")
+	require.NoError(t, err)
+	_, err = reportService.Report(bob, news.ID.Hex(), "", "spam")
+	require.NoError(t, err)
+	queue, err := reportService.GetQueue(carol, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, queue, 1)
+	require.Equal(t, music.ID, queue[0].Post.ID)
+	queue, err = reportService.GetQueue(mod, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, queue, 2)
+	_, err = reportService.GetQueue(bob, 0, 0)
+	require.Equal(t, model.ErrUnAuthorized, err)
+
+	_, err = reportService.Resolve(carol, news.ID.Hex(), "", model.ReportRemove, "", 0)
+	require.Equal(t, model.ErrUnAuthorized, err)
+	_, err = reportService.Resolve(carol, music.ID.Hex(), "", model.ReportRemove, "", 1)
+	require.NoError(t, err)
+
+	listing, err := postService.GetCategoryListing(bob, "music")
+	require.NoError(t, err)
+	require.Equal(t, []string{"No spam", "Music only"}, listing.Rules)
+	require.Empty(t, listing.Posts)
+	listing, err = postService.GetCategoryListing(bob, "news")
+	require.NoError(t, err)
+	require.Empty(t, listing.Rules)
+	require.Len(t, listing.Posts, 1)
+
+	// category moderators read the log of their categories only
+	entries, err = modLogService.GetModLog(carol, model.ModLogFilter{})
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+	for _, entry := range entries {
+		require.Equal(t, "music", entry.Category)
+	}
+	entries, err = modLogService.GetModLog(carol, model.ModLogFilter{Category: "news"})
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	// and ban from their categories only
+	_, err = banService.Ban(carol, "alice", "news", false, "", 0)
+	require.Equal(t, model.ErrUnAuthorized, err)
+	_, err = banService.Ban(carol, "alice", "", false, "", 0)
+	require.Equal(t, model.ErrUnAuthorized, err)
+	_, err = banService.Ban(carol, "alice", "music", true, "", 0)
+	require.Equal(t, model.ErrUnAuthorized, err)
+	_, err = banService.Ban(carol, "alice", "music", false, "off topic", 0)
+	require.NoError(t, err)
+	_, err = banService.Ban(mod, "carol", "music", false, "", 0)
+	require.Equal(t, model.ErrInvalidBanTarget, err)
+	_, err = banService.Ban(mod, "alice", "news", false, "", 0)
+	require.NoError(t, err)
+	bans, err := banService.GetBans(carol)
+	require.NoError(t, err)
+	require.Len(t, bans, 1)
+	require.Equal(t, "music", bans[0].Category)
+	_, err = banService.GetBans(bob)
+	require.Equal(t, model.ErrUnAuthorized, err)
+	require.NoError(t, banService.Unban(carol, "alice", "music", false))
+	require.Equal(t, model.ErrUnAuthorized, banService.Unban(carol, "alice", "news", false))
+
+	_, err = categoryService.RemoveModerator(mod, "music", "bob")
+	require.Equal(t, model.ErrModeratorNotFound, err)
+	category, err = categoryService.RemoveModerator(mod, "music", "carol")
+	require.NoError(t, err)
+	require.Empty(t, category.Moderators)
+	_, err = reportService.GetQueue(carol, 0, 0)
+	require.Equal(t, model.ErrUnAuthorized, err)
+}
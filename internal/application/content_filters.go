@@ -0,0 +1,164 @@
+package application
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/application/helpers"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+)
+
+// repeatMinLength keeps short replies like "thanks!" out of the repeated content detection.
+const repeatMinLength = 20
+
+// ParseWordList reads one entry per line, skipping blank lines and # comments.
+func ParseWordList(data string) []string {
+	entries := []string{}
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, line)
+	}
+	return entries
+}
+
+// WordFilter matches banned words in the title and text, case insensitively.
+// An entry written as /expression/ is a regular expression, any other is matched as a whole word.
+type WordFilter struct {
+	action   string
+	entries  []string
+	patterns []*regexp.Regexp
+}
+
+func NewWordFilter(entries []string, action string) (*WordFilter, error) {
+	filter := &WordFilter{
+		action:   action,
+		entries:  entries,
+		patterns: make([]*regexp.Regexp, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		expr := ""
+		if len(entry) > 2 && strings.HasPrefix(entry, "/") && strings.HasSuffix(entry, "/") {
+			expr = entry[1 : len(entry)-1]
+		} else {
+			expr = regexp.QuoteMeta(entry)
+			// \b only knows ASCII letters
+			if first, _ := utf8.DecodeRuneInString(entry); isWordRune(first) {
+				expr = `(?:^|[^\p{L}\p{N}_])` + expr
+			}
+			if last, _ := utf8.DecodeLastRuneInString(entry); isWordRune(last) {
+				expr += `(?:$|[^\p{L}\p{N}_])`
+			}
+		}
+		pattern, err := regexp.Compile("(?i)" + expr)
+		if err != nil {
+			return nil, err
+		}
+		filter.patterns = append(filter.patterns, pattern)
+	}
+	return filter, nil
+}
+
+func (f *WordFilter) Check(ctx context.Context, content *model.Content) (*model.FilterVerdict, error) {
+	for idx, pattern := range f.patterns {
+		if pattern.MatchString(content.Title) || pattern.MatchString(content.Text) {
+			return &model.FilterVerdict{Action: f.action, Filter: "words", Reason: "contains " + f.entries[idx]}, nil
+		}
+	}
+	return nil, nil
+}
+
+// DomainFilter matches links to the listed domains and their subdomains, in the link of a post or in the text.
+type DomainFilter struct {
+	action  string
+	domains []string
+}
+
+func NewDomainFilter(domains []string, action string) *DomainFilter {
+	return &DomainFilter{
+		action:  action,
+		domains: domains,
+	}
+}
+
+func (f *DomainFilter) Check(ctx context.Context, content *model.Content) (*model.FilterVerdict, error) {
+	for _, host := range helpers.LinkHosts(content.Url + " " + content.Text) {
+		if helpers.MatchHost(host, f.domains) {
+			return &model.FilterVerdict{Action: f.action, Filter: "domains", Reason: "links to " + host}, nil
+		}
+	}
+	return nil, nil
+}
+
+// RepeatFilter matches content that was submitted more than limit times within the window, by anyone.
+// It remembers what it saw in memory only.
+type RepeatFilter struct {
+	action         string
+	limit          int
+	window         time.Duration
+	timeController model.ITimeController
+
+	mu        sync.Mutex
+	seen      map[string][]time.Time
+	lastPrune time.Time
+}
+
+func NewRepeatFilter(limit int, window time.Duration, action string, timeController model.ITimeController) *RepeatFilter {
+	return &RepeatFilter{
+		action:         action,
+		limit:          limit,
+		window:         window,
+		timeController: timeController,
+		seen:           make(map[string][]time.Time),
+	}
+}
+
+func (f *RepeatFilter) Check(ctx context.Context, content *model.Content) (*model.FilterVerdict, error) {
+	text := strings.ToLower(strings.Join(strings.Fields(content.Title+" "+content.Text), " "))
+	if utf8.RuneCountInString(text) < repeatMinLength {
+		return nil, nil
+	}
+	sum := sha1.Sum([]byte(text))
+	key := hex.EncodeToString(sum[:])
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.timeController.Now()
+	if now.Sub(f.lastPrune) > f.window {
+		for seenKey, times := range f.seen {
+			if now.Sub(times[len(times)-1]) > f.window {
+				delete(f.seen, seenKey)
+			}
+		}
+		f.lastPrune = now
+	}
+
+	recent := make([]time.Time, 0, len(f.seen[key])+1)
+	for _, seenAt := range f.seen[key] {
+		if now.Sub(seenAt) <= f.window {
+			recent = append(recent, seenAt)
+		}
+	}
+	recent = append(recent, now)
+	f.seen[key] = recent
+
+	if len(recent) > f.limit {
+		return &model.FilterVerdict{Action: f.action, Filter: "repeat", Reason: "posted " + strconv.Itoa(len(recent)) + " times within " + f.window.String()}, nil
+	}
+	return nil, nil
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,136 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/repository/inmemory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWordFilter(t *testing.T) {
+	entries := ParseWordList("# banned\ncasino\n\n/fre+ money/\nc++\nспам\n")
+	require.Equal(t, []string{"casino", "/fre+ money/", "c++", "спам"}, entries)
+
+	filter, err := NewWordFilter(entries, model.FilterHold)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		Name    string
+		Content *model.Content
+		Reason  string
+	}{
+		{Name: "Word In Title", Content: &model.Content{Title: "Best CASINO online"}, Reason: "contains casino"},
+		{Name: "Part Of A Word", Content: &model.Content{Text: "casinos are not banned"}},
+		{Name: "Regexp", Content: &model.Content{Text: "get freeee money now"}, Reason: "contains /fre+ money/"},
+		{Name: "Symbols", Content: &model.Content{Text: "I write c++ daily"}, Reason: "contains c++"},
+		{Name: "Unicode", Content: &model.Content{Text: "это Спам!"}, Reason: "contains спам"},
+		{Name: "Unicode Part Of A Word", Content: &model.Content{Text: "спаммер"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			verdict, err := filter.Check(context.Background(), tc.Content)
+			require.NoError(t, err)
+			if tc.Reason == "" {
+				require.Nil(t, verdict)
+				return
+			}
+			require.Equal(t, &model.FilterVerdict{Action: model.FilterHold, Filter: "words", Reason: tc.Reason}, verdict)
+		})
+	}
+
+	_, err = NewWordFilter([]string{"/(/"}, model.FilterReject)
+	require.Error(t, err)
+}
+
+func TestDomainFilter(t *testing.T) {
+	filter := NewDomainFilter([]string{"spam.example"}, model.FilterReject)
+
+	verdict, err := filter.Check(context.Background(), &model.Content{Url: "https://www.Spam.example/offer"})
+	require.NoError(t, err)
+	require.Equal(t, &model.FilterVerdict{Action: model.FilterReject, Filter: "domains", Reason: "links to www.spam.example"}, verdict)
+
+	verdict, err = filter.Check(context.Background(), &model.Content{Text: "see [this](http://spam.example/x)"})
+	require.NoError(t, err)
+	require.NotNil(t, verdict)
+
+	verdict, err = filter.Check(context.Background(), &model.Content{Text: "notspam.example and https://example.com"})
+	require.NoError(t, err)
+	require.Nil(t, verdict)
+}
+
+func TestRepeatFilter(t *testing.T) {
+	clock := &FakeTimeController{fixedTime: time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)}
+	filter := NewRepeatFilter(2, 10*time.Minute, model.FilterHold, clock)
+	content := &model.Content{Text: "Buy followers cheap, visit my profile"}
+
+	for i := 0; i < 2; i++ {
+		verdict, err := filter.Check(context.Background(), content)
+		require.NoError(t, err)
+		require.Nil(t, verdict)
+	}
+
+	verdict, err := filter.Check(context.Background(), &model.Content{Text: "  buy FOLLOWERS cheap,\nvisit my profile"})
+	require.NoError(t, err)
+	require.Equal(t, &model.FilterVerdict{Action: model.FilterHold, Filter: "repeat", Reason: "posted 3 times within 10m0s"}, verdict)
+
+	verdict, err = filter.Check(context.Background(), &model.Content{Text: "thanks!"})
+	require.NoError(t, err)
+	require.Nil(t, verdict)
+
+	clock.fixedTime = clock.fixedTime.Add(11 * time.Minute)
+	verdict, err = filter.Check(context.Background(), content)
+	require.NoError(t, err)
+	require.Nil(t, verdict)
+}
+
+func TestBayesFilter(t *testing.T) {
+	ctx := context.Background()
+	storage := inmemory.NewSpamModelStorage()
+	filter, err := NewBayesFilter(ctx, storage)
+	require.NoError(t, err)
+	filter.minDocs = 3
+
+	spam := []*model.Content{
+		{Text: "cheap pills online, buy now", Url: "https://pills.example"},
+		{Text: "buy cheap watches now"},
+		{Text: "win money now, click here"},
+	}
+	ham := []*model.Content{
+		{Text: "how do goroutines share memory"},
+		{Text: "the new release of the compiler is faster"},
+		{Text: "a question about memory in go"},
+	}
+
+	verdict, err := filter.Check(ctx, spam[0])
+	require.NoError(t, err)
+	require.Nil(t, verdict, "an untrained filter has no opinion")
+
+	for _, content := range spam {
+		require.NoError(t, filter.Train(ctx, content, true))
+	}
+	for _, content := range ham {
+		require.NoError(t, filter.Train(ctx, content, false))
+	}
+
+	saved, err := storage.LoadSpamModel(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), saved.SpamDocs)
+	require.Equal(t, &model.TokenCount{Spam: 3}, saved.Tokens["now"])
+	require.Equal(t, &model.TokenCount{Ham: 2}, saved.Tokens["memory"])
+	require.Equal(t, int64(1), saved.Tokens["host:pills.example"].Spam)
+
+	require.Greater(t, filter.SpamProbability(&model.Content{Text: "buy cheap pills now"}), 0.9)
+	require.Less(t, filter.SpamProbability(&model.Content{Text: "memory of goroutines"}), 0.1)
+
+	verdict, err = filter.Check(ctx, &model.Content{Text: "buy cheap pills now, click here", Url: "https://pills.example/x"})
+	require.NoError(t, err)
+	require.Equal(t, model.FilterReject, verdict.Action)
+
+	verdict, err = filter.Check(ctx, &model.Content{Text: "is the compiler release faster"})
+	require.NoError(t, err)
+	require.Nil(t, verdict)
+}
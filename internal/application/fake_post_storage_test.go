@@ -0,0 +1,429 @@
+package application
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FakePostStorage keeps posts in memory for the service tests, it implements what they call.
+type FakePostStorage struct {
+	model.IPostStorage
+	Posts []*model.Post
+	mu    sync.Mutex
+}
+
+func (s *FakePostStorage) GetAllPosts(ctx context.Context) ([]*model.Post, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	posts := make([]*model.Post, 0, len(s.Posts))
+	for _, post := range s.Posts {
+		copied := *post
+		posts = append(posts, &copied)
+	}
+	return posts, nil
+}
+
+func (s *FakePostStorage) GetPostByID(ctx context.Context, postID primitive.ObjectID) (*model.Post, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, post := range s.Posts {
+		if post.ID == postID {
+			copied := *post
+			return &copied, nil
+		}
+	}
+	return nil, model.ErrPostNotFound
+}
+
+func (s *FakePostStorage) GetPostsByIDs(ctx context.Context, postIDs []primitive.ObjectID) ([]*model.Post, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	posts := make([]*model.Post, 0, len(postIDs))
+	for _, post := range s.Posts {
+		for _, postID := range postIDs {
+			if post.ID == postID {
+				copied := *post
+				posts = append(posts, &copied)
+				break
+			}
+		}
+	}
+	return posts, nil
+}
+
+func (s *FakePostStorage) GetPostsByCategory(ctx context.Context, category string) ([]*model.Post, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	posts := make([]*model.Post, 0)
+	for _, post := range s.Posts {
+		if post.Category == category {
+			copied := *post
+			posts = append(posts, &copied)
+		}
+	}
+	return posts, nil
+}
+
+// GetCommentsByUser takes the comments of every post newest first, the way they were added.
+func (s *FakePostStorage) GetCommentsByUser(ctx context.Context, userName string, filter model.UserCommentsFilter) ([]*model.UserComment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	comments := make([]*model.UserComment, 0)
+	for _, post := range s.Posts {
+		for i := len(post.Comments) - 1; i >= 0; i-- {
+			comment := post.Comments[i]
+			if comment.Author == nil || comment.Author.Username != userName || comment.IsDeleted() {
+				continue
+			}
+			excluded := false
+			for _, userID := range filter.ExcludeAuthors {
+				excluded = excluded || userID == comment.Author.ID
+			}
+			if excluded || !filter.WithHeld && !comment.IsLive() {
+				continue
+			}
+			comments = append(comments, &model.UserComment{
+				Comment: *comment,
+				Post:    &model.PostLink{ID: post.ID, Title: post.Title, Category: post.Category},
+			})
+		}
+	}
+	if filter.Offset >= len(comments) {
+		return []*model.UserComment{}, nil
+	}
+	comments = comments[filter.Offset:]
+	if len(comments) > filter.Limit {
+		comments = comments[:filter.Limit]
+	}
+	return comments, nil
+}
+
+func (s *FakePostStorage) CountPostsByUser(ctx context.Context, userID string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var count int64
+	for _, post := range s.Posts {
+		if post.Author != nil && post.Author.ID == userID && !post.IsDeleted() && post.IsLive() {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *FakePostStorage) CountCommentsByUser(ctx context.Context, userID string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var count int64
+	for _, post := range s.Posts {
+		for _, comment := range post.Comments {
+			if comment.Author != nil && comment.Author.ID == userID && !comment.IsDeleted() && comment.IsLive() && !post.IsDeleted() {
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
+func (s *FakePostStorage) AddPost(ctx context.Context, post *model.Post) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	post.ID = primitive.NewObjectID()
+	copied := *post
+	s.Posts = append(s.Posts, &copied)
+	return nil
+}
+
+func (s *FakePostStorage) GetPostsByURL(ctx context.Context, canonicalURL string, since time.Time) ([]*model.Post, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	posts := make([]*model.Post, 0)
+	for _, post := range s.Posts {
+		if post.CanonicalUrl == canonicalURL && post.ID.Timestamp().After(since) {
+			copied := *post
+			posts = append(posts, &copied)
+		}
+	}
+	return posts, nil
+}
+
+func (s *FakePostStorage) GetPostsByVoter(ctx context.Context, userID string) ([]*model.Post, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	posts := make([]*model.Post, 0)
+	for _, post := range s.Posts {
+		if voteScore(post.Votes, userID) != 0 {
+			copied := *post
+			posts = append(posts, &copied)
+		}
+	}
+	return posts, nil
+}
+
+func (s *FakePostStorage) AddComment(ctx context.Context, post *model.Post, comment *model.Comment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	comment.ID = primitive.NewObjectID()
+	comment.PostID = post.ID
+	for _, stored := range s.Posts {
+		if stored.ID == post.ID {
+			copied := *comment
+			stored.Comments = append(stored.Comments, &copied)
+			return nil
+		}
+	}
+	return model.ErrPostNotFound
+}
+
+func (s *FakePostStorage) SetStatus(ctx context.Context, post *model.Post, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, stored := range s.Posts {
+		if stored.ID == post.ID {
+			stored.Status = status
+			stored.Filter = nil
+			return nil
+		}
+	}
+	return model.ErrPostNotFound
+}
+
+func (s *FakePostStorage) SetCommentStatus(ctx context.Context, post *model.Post, commentID primitive.ObjectID, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, stored := range s.Posts {
+		if stored.ID != post.ID {
+			continue
+		}
+		for _, comment := range stored.Comments {
+			if comment.ID == commentID {
+				comment.Status = status
+				comment.Filter = nil
+				return nil
+			}
+		}
+	}
+	return model.ErrCommentNotFound
+}
+
+func (s *FakePostStorage) SetLinkStatus(ctx context.Context, post *model.Post, status string, preview *model.LinkPreview) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, stored := range s.Posts {
+		if stored.ID == post.ID {
+			stored.Status = status
+			stored.Preview = preview
+			return nil
+		}
+	}
+	return model.ErrPostNotFound
+}
+
+func (s *FakePostStorage) SetLocked(ctx context.Context, post *model.Post, locked bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, stored := range s.Posts {
+		if stored.ID == post.ID {
+			stored.Locked = locked
+			return nil
+		}
+	}
+	return model.ErrPostNotFound
+}
+
+func (s *FakePostStorage) SetArchived(ctx context.Context, post *model.Post, archived bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, stored := range s.Posts {
+		if stored.ID == post.ID {
+			stored.Archived = archived
+			stored.Unarchived = !archived
+			return nil
+		}
+	}
+	return model.ErrPostNotFound
+}
+
+func (s *FakePostStorage) Vote(ctx context.Context, post *model.Post, vote *model.Vote) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, stored := range s.Posts {
+		if stored.ID != post.ID {
+			continue
+		}
+		votes := []*model.Vote{vote}
+		for _, existing := range stored.Votes {
+			if existing.UserID != vote.UserID {
+				votes = append(votes, existing)
+			}
+		}
+		stored.Votes = votes
+		return nil
+	}
+	return model.ErrPostNotFound
+}
+
+func (s *FakePostStorage) PollVote(ctx context.Context, post *model.Post, vote *model.PollVote) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, stored := range s.Posts {
+		if stored.ID == post.ID {
+			poll := *stored.Poll
+			poll.Votes = []*model.PollVote{vote}
+			for _, existing := range stored.Poll.Votes {
+				if existing.UserID != vote.UserID {
+					poll.Votes = append(poll.Votes, existing)
+				}
+			}
+			stored.Poll = &poll
+			return nil
+		}
+	}
+	return model.ErrPostNotFound
+}
+
+func (s *FakePostStorage) UpdateScore(ctx context.Context, post *model.Post) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, stored := range s.Posts {
+		if stored.ID == post.ID {
+			stored.Score = 0
+			for _, vote := range post.Votes {
+				stored.Score += vote.Score
+			}
+			return nil
+		}
+	}
+	return model.ErrPostNotFound
+}
+
+func (s *FakePostStorage) DeletePost(ctx context.Context, postID primitive.ObjectID, deletedBy *model.Author, deletedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, stored := range s.Posts {
+		if stored.ID == postID {
+			stored.DeletedAt, stored.DeletedBy = &deletedAt, deletedBy
+			return nil
+		}
+	}
+	return model.ErrPostNotFound
+}
+
+func (s *FakePostStorage) RestorePost(ctx context.Context, postID primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, stored := range s.Posts {
+		if stored.ID == postID {
+			stored.DeletedAt, stored.DeletedBy = nil, nil
+			return nil
+		}
+	}
+	return model.ErrPostNotFound
+}
+
+func (s *FakePostStorage) DeleteComment(ctx context.Context, post *model.Post, commentID primitive.ObjectID, deletedBy *model.Author, deletedAt time.Time) error {
+	return s.updateComment(post, commentID, func(comment *model.Comment) {
+		comment.DeletedAt, comment.DeletedBy = &deletedAt, deletedBy
+	})
+}
+
+func (s *FakePostStorage) RestoreComment(ctx context.Context, post *model.Post, commentID primitive.ObjectID) error {
+	return s.updateComment(post, commentID, func(comment *model.Comment) {
+		comment.DeletedAt, comment.DeletedBy = nil, nil
+	})
+}
+
+func (s *FakePostStorage) VoteComment(ctx context.Context, post *model.Post, commentID primitive.ObjectID, vote *model.Vote) error {
+	return s.updateComment(post, commentID, func(comment *model.Comment) {
+		votes := []*model.Vote{vote}
+		for _, existing := range comment.Votes {
+			if existing.UserID != vote.UserID {
+				votes = append(votes, existing)
+			}
+		}
+		comment.Votes = votes
+	})
+}
+
+func (s *FakePostStorage) UnVoteComment(ctx context.Context, post *model.Post, commentID primitive.ObjectID, userID string) error {
+	return s.updateComment(post, commentID, func(comment *model.Comment) {
+		votes := []*model.Vote{}
+		for _, existing := range comment.Votes {
+			if existing.UserID != userID {
+				votes = append(votes, existing)
+			}
+		}
+		comment.Votes = votes
+	})
+}
+
+func (s *FakePostStorage) UpdateCommentScore(ctx context.Context, post *model.Post, comment *model.Comment) error {
+	return s.updateComment(post, comment.ID, func(stored *model.Comment) {
+		stored.Score = 0
+		for _, vote := range comment.Votes {
+			stored.Score += vote.Score
+		}
+	})
+}
+
+func (s *FakePostStorage) GetCommentsByVoter(ctx context.Context, userID string) ([]*model.Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	comments := make([]*model.Comment, 0)
+	for _, post := range s.Posts {
+		for _, comment := range post.Comments {
+			if voteScore(comment.Votes, userID) != 0 {
+				copied := *comment
+				comments = append(comments, &copied)
+			}
+		}
+	}
+	return comments, nil
+}
+
+func (s *FakePostStorage) updateComment(post *model.Post, commentID primitive.ObjectID, update func(*model.Comment)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, stored := range s.Posts {
+		if stored.ID != post.ID {
+			continue
+		}
+		for idx, comment := range stored.Comments {
+			if comment.ID == commentID {
+				copied := *comment
+				update(&copied)
+				stored.Comments = append(append(stored.Comments[:idx:idx], &copied), stored.Comments[idx+1:]...)
+				return nil
+			}
+		}
+	}
+	return model.ErrCommentNotFound
+}
+
+func (s *FakePostStorage) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var purged int64
+	posts := make([]*model.Post, 0, len(s.Posts))
+	for _, stored := range s.Posts {
+		if stored.IsDeleted() && stored.DeletedAt.Before(before) {
+			purged += int64(1 + len(stored.Comments))
+			continue
+		}
+		comments := make([]*model.Comment, 0, len(stored.Comments))
+		for _, comment := range stored.Comments {
+			if comment.IsDeleted() && comment.DeletedAt.Before(before) {
+				purged++
+				continue
+			}
+			comments = append(comments, comment)
+		}
+		stored.Comments = comments
+		posts = append(posts, stored)
+	}
+	s.Posts = posts
+	return purged, nil
+}
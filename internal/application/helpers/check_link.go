@@ -1,20 +1,48 @@
 package helpers
 
 import (
+	"context"
+	"errors"
+	"io"
+	"mime"
 	"net/http"
-	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
 )
 
-func CheckLink(link string) bool {
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-	}
+// previewMaxSize caps how much of a page is read looking for its metadata.
+const previewMaxSize = 512 << 10
 
-	resp, err := client.Get(link)
+// CheckLink fetches the link and returns the preview of HTML pages. It fails with ErrLinkUnreachable
+// when trying again later may help, and with ErrInvalidUrl when it will not.
+func CheckLink(ctx context.Context, fetcher *Fetcher, link string) (*model.LinkPreview, error) {
+	resp, err := fetcher.Get(ctx, link)
 	if err != nil {
-		return false
+		for _, policyErr := range []error{model.ErrInvalidUrl, model.ErrSchemeNotAllowed, model.ErrHostNotAllowed, model.ErrAddressNotAllowed, model.ErrTooManyRedirects} {
+			if errors.Is(err, policyErr) {
+				return nil, model.ErrInvalidUrl
+			}
+		}
+		return nil, model.ErrLinkUnreachable
 	}
 	defer resp.Body.Close()
 
-	return resp.StatusCode == http.StatusOK
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooEarly,
+		resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		return nil, model.ErrLinkUnreachable
+	default:
+		return nil, model.ErrInvalidUrl
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, nil
+	}
+	page, err := io.ReadAll(io.LimitReader(resp.Body, previewMaxSize))
+	if err != nil && len(page) == 0 {
+		return nil, nil
+	}
+	return ParseLinkPreview(string(page), resp.Request.URL), nil
 }
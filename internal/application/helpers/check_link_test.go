@@ -0,0 +1,115 @@
+package helpers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckLink(t *testing.T) {
+	pages := map[string]string{
+		"/og": `<!doctype html><html><HEAD>
+			<title>Plain title</title>
+			<!-- <meta property="og:title" content="Commented out"> -->
+			<script>if (a <b) { document.write("<meta property='og:title' content='Scripted'>") }</script>
+			<meta property="og:title" content="Rock &amp; Roll">
+			<meta name="twitter:title" content="Twitter title">
+			<meta property=og:site_name content=Example>
+			<meta name="description" content="  A page
+				about music  ">
+			<meta property="og:image" content="/img/cover.png">
+			</head><body><meta property="og:description" content="Too late"></body></html>`,
+		"/plain":  `<html><head><title>Only a title</title><link rel="shortcut image_src" href="https://cdn.example.com/a.jpg"></head></html>`,
+		"/unsafe": `<html><head><meta property="og:image" content="javascript:alert(1)"><meta name="twitter:description" content="Hi"></head></html>`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/file":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write([]byte("%PDF-1.4"))
+		case "/unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/redirect":
+			http.Redirect(w, r, "/og", http.StatusFound)
+		default:
+			page, found := pages[r.URL.Path]
+			if !found {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(page))
+		}
+	}))
+	defer server.Close()
+
+	fetcher := NewFetcher(FetchPolicy{AllowedNetworks: parseNetworks("127.0.0.1/32")})
+
+	testCases := []struct {
+		Name    string
+		Path    string
+		Err     error
+		Preview *model.LinkPreview
+	}{
+		{
+			Name: "OpenGraph",
+			Path: "/og",
+			Preview: &model.LinkPreview{
+				Title:       "Rock & Roll",
+				Description: "A page about music",
+				SiteName:    "Example",
+				Image:       server.URL + "/img/cover.png",
+			},
+		},
+		{
+			Name: "Redirect",
+			Path: "/redirect",
+			Preview: &model.LinkPreview{
+				Title:       "Rock & Roll",
+				Description: "A page about music",
+				SiteName:    "Example",
+				Image:       server.URL + "/img/cover.png",
+			},
+		},
+		{
+			Name: "HTML Fallbacks",
+			Path: "/plain",
+			Preview: &model.LinkPreview{
+				Title:    "Only a title",
+				SiteName: "127.0.0.1",
+				Image:    "https://cdn.example.com/a.jpg",
+			},
+		},
+		{
+			Name:    "Unsafe Image",
+			Path:    "/unsafe",
+			Preview: &model.LinkPreview{Description: "Hi", SiteName: "127.0.0.1"},
+		},
+		{
+			Name: "Not HTML",
+			Path: "/file",
+		},
+		{
+			Name: "Not Found",
+			Path: "/missing",
+			Err:  model.ErrInvalidUrl,
+		},
+		{
+			Name: "Unavailable",
+			Path: "/unavailable",
+			Err:  model.ErrLinkUnreachable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			preview, err := CheckLink(context.Background(), fetcher, server.URL+tc.Path)
+			require.Equal(t, tc.Err, err)
+			require.Equal(t, tc.Preview, preview)
+		})
+	}
+}
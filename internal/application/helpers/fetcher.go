@@ -0,0 +1,221 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+)
+
+const (
+	defaultFetchTimeout      = 5 * time.Second
+	defaultFetchMaxRedirects = 5
+	defaultFetchMaxSize      = 5 << 20
+)
+
+// blockedNetworks are the special purpose ranges not covered by the net.IP predicates.
+var blockedNetworks = parseNetworks(
+	"0.0.0.0/8",
+	"100.64.0.0/10",
+	"192.0.0.0/24",
+	"192.0.2.0/24",
+	"198.18.0.0/15",
+	"198.51.100.0/24",
+	"203.0.113.0/24",
+	"240.0.0.0/4",
+	"64:ff9b::/96",
+	"64:ff9b:1::/48",
+	"100::/64",
+	"2001:db8::/32",
+)
+
+// FetchPolicy limits what a Fetcher may request, zero values fall back to safe defaults.
+type FetchPolicy struct {
+	// AllowedHosts, when set, are the only hosts that may be fetched, subdomains included.
+	AllowedHosts []string
+	// DeniedHosts may never be fetched, subdomains included.
+	DeniedHosts []string
+	// AllowedNetworks are exempt from the private address blocking.
+	AllowedNetworks []*net.IPNet
+	Timeout         time.Duration
+	MaxRedirects    int
+	MaxSize         int64
+}
+
+// Fetcher makes outbound requests to user supplied URLs without letting them reach internal addresses.
+type Fetcher struct {
+	policy FetchPolicy
+	client *http.Client
+}
+
+func NewFetcher(policy FetchPolicy) *Fetcher {
+	if policy.Timeout <= 0 {
+		policy.Timeout = defaultFetchTimeout
+	}
+	if policy.MaxRedirects <= 0 {
+		policy.MaxRedirects = defaultFetchMaxRedirects
+	}
+	if policy.MaxSize <= 0 {
+		policy.MaxSize = defaultFetchMaxSize
+	}
+
+	fetcher := &Fetcher{policy: policy}
+	dialer := &net.Dialer{
+		Timeout: policy.Timeout,
+		// the address is checked after resolution, on the IP actually dialed, so rebinding the name does not help
+		Control: func(network string, address string, conn syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			return fetcher.checkIP(net.ParseIP(host))
+		},
+	}
+	fetcher.client = &http.Client{
+		Timeout: policy.Timeout,
+		Transport: &http.Transport{
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   policy.Timeout,
+			ResponseHeaderTimeout: policy.Timeout,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       30 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > policy.MaxRedirects {
+				return model.ErrTooManyRedirects
+			}
+			return fetcher.checkURL(req.URL)
+		},
+	}
+	return fetcher
+}
+
+// Get fetches the link, reading the body fails with ErrResponseTooLarge past the size limit.
+func (f *Fetcher) Get(ctx context.Context, link string) (*http.Response, error) {
+	target, err := url.Parse(link)
+	if err != nil {
+		return nil, model.ErrInvalidUrl
+	}
+	if err := f.checkURL(target); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "asperitas-link-checker/1.0")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		for _, policyErr := range []error{model.ErrTooManyRedirects, model.ErrSchemeNotAllowed, model.ErrHostNotAllowed, model.ErrAddressNotAllowed} {
+			if errors.Is(err, policyErr) {
+				return nil, policyErr
+			}
+		}
+		return nil, err
+	}
+	resp.Body = &limitedBody{ReadCloser: resp.Body, remaining: f.policy.MaxSize}
+	return resp, nil
+}
+
+func (f *Fetcher) checkURL(target *url.URL) error {
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return model.ErrSchemeNotAllowed
+	}
+	if target.User != nil {
+		return model.ErrInvalidUrl
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(target.Hostname()), ".")
+	if host == "" {
+		return model.ErrInvalidUrl
+	}
+	if MatchHost(host, f.policy.DeniedHosts) {
+		return model.ErrHostNotAllowed
+	}
+	if len(f.policy.AllowedHosts) > 0 && !MatchHost(host, f.policy.AllowedHosts) {
+		return model.ErrHostNotAllowed
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return f.checkIP(ip)
+	}
+	return nil
+}
+
+func (f *Fetcher) checkIP(ip net.IP) error {
+	if ip == nil {
+		return model.ErrAddressNotAllowed
+	}
+	for _, network := range f.policy.AllowedNetworks {
+		if network.Contains(ip) {
+			return nil
+		}
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return model.ErrAddressNotAllowed
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return model.ErrAddressNotAllowed
+		}
+	}
+	return nil
+}
+
+// MatchHost reports whether host is one of hosts or a subdomain of one.
+func MatchHost(host string, hosts []string) bool {
+	for _, candidate := range hosts {
+		candidate = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(candidate)), ".")
+		if candidate == "" {
+			continue
+		}
+		if host == candidate || strings.HasSuffix(host, "."+candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		// a read past the limit tells a body of exactly the limit apart from a larger one
+		var probe [1]byte
+		if n, _ := b.ReadCloser.Read(probe[:]); n > 0 {
+			return 0, model.ErrResponseTooLarge
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	return n, err
+}
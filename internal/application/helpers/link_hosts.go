@@ -0,0 +1,28 @@
+package helpers
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var textLink = regexp.MustCompile(`(?i)\bhttps?://[^\s<>()\[\]"']+`)
+
+// LinkHosts returns the distinct lowercased hosts of the links written in text.
+func LinkHosts(text string) []string {
+	seen := make(map[string]struct{})
+	hosts := []string{}
+	for _, link := range textLink.FindAllString(text, -1) {
+		target, err := url.Parse(link)
+		if err != nil {
+			continue
+		}
+		host := strings.TrimSuffix(strings.ToLower(target.Hostname()), ".")
+		if _, ok := seen[host]; host == "" || ok {
+			continue
+		}
+		seen[host] = struct{}{}
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
@@ -0,0 +1,141 @@
+package helpers
+
+import (
+	"html"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+)
+
+const (
+	previewTitleLength       = 300
+	previewDescriptionLength = 1000
+)
+
+// ParseLinkPreview extracts the OpenGraph, Twitter card and plain HTML metadata from the head of a page,
+// relative image URLs are resolved against base. It returns nil when the page has none.
+func ParseLinkPreview(page string, base *url.URL) *model.LinkPreview {
+	meta := make(map[string]string)
+	var title, imageSrc string
+
+	lower := asciiLower(page)
+	for i := 0; i < len(page); {
+		start := strings.IndexByte(page[i:], '<')
+		if start < 0 {
+			break
+		}
+		i += start
+
+		if strings.HasPrefix(page[i:], "<!--") {
+			end := strings.Index(page[i:], "-->")
+			if end < 0 {
+				break
+			}
+			i += end + 3
+			continue
+		}
+
+		tag, width, ok := parseTag(page[i:])
+		if !ok {
+			i++
+			continue
+		}
+		i += width
+		if tag.name == "body" || tag.closing && tag.name == "head" {
+			break
+		}
+		if tag.closing {
+			continue
+		}
+
+		switch tag.name {
+		case "script", "style", "title":
+			end := strings.Index(lower[i:], "</"+tag.name)
+			if end < 0 {
+				i = len(page)
+				break
+			}
+			if tag.name == "title" && title == "" {
+				title = html.UnescapeString(page[i : i+end])
+			}
+			i += end
+		case "meta":
+			key := tag.attrs["property"]
+			if key == "" {
+				key = tag.attrs["name"]
+			}
+			key = strings.ToLower(strings.TrimSpace(key))
+			if _, found := meta[key]; key != "" && !found {
+				meta[key] = tag.attrs["content"]
+			}
+		case "link":
+			if imageSrc == "" && strings.Contains(" "+strings.ToLower(tag.attrs["rel"])+" ", " image_src ") {
+				imageSrc = tag.attrs["href"]
+			}
+		}
+	}
+
+	preview := &model.LinkPreview{
+		Title:       Excerpt(first(meta["og:title"], meta["twitter:title"], title), previewTitleLength),
+		Description: Excerpt(first(meta["og:description"], meta["twitter:description"], meta["description"]), previewDescriptionLength),
+		SiteName:    Excerpt(meta["og:site_name"], previewTitleLength),
+		Image:       resolveImage(base, first(meta["og:image"], meta["og:image:url"], meta["og:image:secure_url"], meta["twitter:image"], meta["twitter:image:src"], imageSrc)),
+	}
+	if *preview == (model.LinkPreview{}) {
+		return nil
+	}
+	if preview.SiteName == "" && base != nil {
+		preview.SiteName = strings.TrimPrefix(base.Hostname(), "www.")
+	}
+	return preview
+}
+
+func resolveImage(base *url.URL, image string) string {
+	image = strings.TrimSpace(image)
+	if image == "" {
+		return ""
+	}
+	imageURL, err := url.Parse(image)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		imageURL = base.ResolveReference(imageURL)
+	}
+	if imageURL.Scheme != "http" && imageURL.Scheme != "https" {
+		return ""
+	}
+	return imageURL.String()
+}
+
+// Excerpt collapses the whitespace of text and cuts it to at most length runes.
+func Excerpt(text string, length int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= length {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:length-1])) + "…"
+}
+
+func first(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// asciiLower lowercases only ASCII letters so byte offsets stay the same as in s.
+func asciiLower(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if 'A' <= c && c <= 'Z' {
+			b[i] = c + 'a' - 'A'
+		}
+	}
+	return string(b)
+}
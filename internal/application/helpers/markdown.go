@@ -0,0 +1,651 @@
+package helpers
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	unorderedItem = regexp.MustCompile(`^ {0,3}([-*+])( +|$)`)
+	orderedItem   = regexp.MustCompile(`^ {0,3}(\d{1,9})([.)])( +|$)`)
+	fenceOpen     = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})\\s*([\\w+-]*)")
+	quoteLine     = regexp.MustCompile(`^ {0,3}> ?`)
+	autolink      = regexp.MustCompile(`^<((?:https?://|mailto:)[^\s<>]+)>`)
+)
+
+// RenderMarkdown renders a CommonMark subset (emphasis, links, code, quotes
+// and lists) to sanitized HTML. Raw HTML in the source is always escaped.
+func RenderMarkdown(source string) string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	lines := strings.Split(source, "\n")
+	return SanitizeHTML(renderBlocks(lines, false))
+}
+
+// renderBlocks renders block level elements, tight list items get their paragraphs unwrapped.
+func renderBlocks(lines []string, tight bool) string {
+	var out strings.Builder
+	var paragraph []string
+
+	flush := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+		text := renderInline(strings.TrimSpace(strings.Join(paragraph, "\n")))
+		if tight {
+			out.WriteString(text + "\n")
+		} else {
+			out.WriteString("<p>" + text + "</p>\n")
+		}
+		paragraph = nil
+	}
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		if strings.TrimSpace(line) == "" {
+			flush()
+			i++
+			continue
+		}
+
+		if match := fenceOpen.FindStringSubmatch(line); match != nil {
+			flush()
+			fence := match[1]
+			var code []string
+			i++
+			for i < len(lines) && !strings.HasPrefix(strings.TrimLeft(lines[i], " "), fence) {
+				code = append(code, lines[i])
+				i++
+			}
+			i++
+			class := ""
+			if match[2] != "" {
+				class = ` class="language-` + html.EscapeString(match[2]) + `"`
+			}
+			out.WriteString("<pre><code" + class + ">" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>\n")
+			continue
+		}
+
+		if quoteLine.MatchString(line) {
+			flush()
+			var quote []string
+			for i < len(lines) && quoteLine.MatchString(lines[i]) {
+				quote = append(quote, quoteLine.ReplaceAllString(lines[i], ""))
+				i++
+			}
+			out.WriteString("<blockquote>\n" + renderBlocks(quote, false) + "</blockquote>\n")
+			continue
+		}
+
+		if unorderedItem.MatchString(line) || orderedItem.MatchString(line) {
+			flush()
+			var list string
+			list, i = renderList(lines, i)
+			out.WriteString(list)
+			continue
+		}
+
+		paragraph = append(paragraph, line)
+		i++
+	}
+	flush()
+
+	return out.String()
+}
+
+// renderList consumes a list starting at lines[start] and returns its HTML and the next line index.
+func renderList(lines []string, start int) (string, int) {
+	ordered := !unorderedItem.MatchString(lines[start])
+	marker := ""
+	if ordered {
+		marker = orderedItem.FindStringSubmatch(lines[start])[2]
+	} else {
+		marker = unorderedItem.FindStringSubmatch(lines[start])[1]
+	}
+
+	sameList := func(line string) (int, bool) {
+		if ordered {
+			if match := orderedItem.FindStringSubmatch(line); match != nil && match[2] == marker {
+				return len(match[0]), true
+			}
+			return 0, false
+		}
+		if match := unorderedItem.FindStringSubmatch(line); match != nil && match[1] == marker {
+			return len(match[0]), true
+		}
+		return 0, false
+	}
+
+	var out strings.Builder
+	if ordered {
+		first, _ := strconv.Atoi(orderedItem.FindStringSubmatch(lines[start])[1])
+		if first != 1 {
+			out.WriteString(`<ol start="` + strconv.Itoa(first) + `">` + "\n")
+		} else {
+			out.WriteString("<ol>\n")
+		}
+	} else {
+		out.WriteString("<ul>\n")
+	}
+
+	i := start
+	for i < len(lines) {
+		width, ok := sameList(lines[i])
+		if !ok {
+			break
+		}
+
+		item := []string{lines[i][width:]}
+		i++
+		for i < len(lines) {
+			line := lines[i]
+			if strings.TrimSpace(line) == "" {
+				// a blank line continues the item only if indented content follows
+				if i+1 < len(lines) && indentOf(lines[i+1]) >= width {
+					item = append(item, "")
+					i++
+					continue
+				}
+				break
+			}
+			if indentOf(line) >= width {
+				item = append(item, line[width:])
+				i++
+				continue
+			}
+			if _, next := sameList(line); next || unorderedItem.MatchString(line) || orderedItem.MatchString(line) ||
+				quoteLine.MatchString(line) || fenceOpen.MatchString(line) {
+				break
+			}
+			// lazy paragraph continuation
+			item = append(item, line)
+			i++
+		}
+
+		loose := false
+		for _, line := range item {
+			if line == "" {
+				loose = true
+			}
+		}
+		body := strings.TrimSuffix(renderBlocks(item, !loose), "\n")
+		out.WriteString("<li>" + body + "</li>\n")
+
+		if i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+			if i+1 < len(lines) {
+				if _, next := sameList(lines[i+1]); next {
+					i++
+				}
+			}
+		}
+	}
+
+	if ordered {
+		out.WriteString("</ol>\n")
+	} else {
+		out.WriteString("</ul>\n")
+	}
+	return out.String(), i
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// inlineParser renders inline markup in a single pass. As in the CommonMark reference
+// implementation, emphasis runs and link openers are kept on delimiter stacks and matched when a
+// closer is seen, so unmatched or deeply nested markup never makes the text be rescanned.
+type inlineParser struct {
+	text   string
+	tokens []inlineToken
+	plain  strings.Builder
+
+	last     *delimiter
+	brackets []bracket
+	// inactiveBelow marks brackets that can no longer open a link, links do not nest.
+	inactiveBelow int
+
+	backticks  map[int][]int
+	parenClose []int
+	parenDepth []int
+	spaceAt    []int
+	titleEnds  map[int]int
+	delimiters int
+}
+
+type inlineToken struct {
+	html  string
+	delim *delimiter
+}
+
+// delimiter is a run of * or _ that may open or close emphasis.
+type delimiter struct {
+	char     byte
+	pos      int
+	count    int
+	length   int
+	canOpen  bool
+	canClose bool
+
+	prev *delimiter
+	next *delimiter
+
+	openTags  []string
+	closeTags []string
+}
+
+type bracket struct {
+	token  int
+	bottom *delimiter
+}
+
+func renderInline(text string) string {
+	p := &inlineParser{text: text}
+	return p.render()
+}
+
+func (p *inlineParser) render() string {
+	text := p.text
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '\\' && i+1 < len(text) && isASCIIPunct(text[i+1]):
+			p.plain.WriteString(html.EscapeString(text[i+1 : i+2]))
+			i += 2
+			continue
+
+		case c == '`':
+			run := runLength(text, i, '`')
+			if end := p.codeSpanEnd(i+run, run); end != -1 {
+				code := strings.ReplaceAll(text[i+run:end], "\n", " ")
+				if len(code) > 1 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+					code = code[1 : len(code)-1]
+				}
+				p.plain.WriteString("<code>" + html.EscapeString(code) + "</code>")
+				i = end + run
+				continue
+			}
+			p.plain.WriteString(text[i : i+run])
+			i += run
+			continue
+
+		case c == '<':
+			if match := autolink.FindStringSubmatch(text[i:]); match != nil {
+				p.plain.WriteString(`<a href="` + html.EscapeString(match[1]) + `">` + html.EscapeString(match[1]) + "</a>")
+				i += len(match[0])
+				continue
+			}
+
+		case c == '[':
+			p.flush()
+			p.brackets = append(p.brackets, bracket{token: len(p.tokens), bottom: p.last})
+			p.tokens = append(p.tokens, inlineToken{html: "["})
+			i++
+			continue
+
+		case c == ']':
+			if width := p.closeBracket(i); width > 0 {
+				i += width
+				continue
+			}
+
+		case c == '*' || c == '_':
+			i = p.pushDelimiter(i)
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(text[i:])
+		p.plain.WriteString(html.EscapeString(string(r)))
+		i += size
+	}
+
+	p.flush()
+	p.processEmphasis(nil)
+
+	var out strings.Builder
+	for _, token := range p.tokens {
+		if token.delim == nil {
+			out.WriteString(token.html)
+			continue
+		}
+		d := token.delim
+		for _, tag := range d.closeTags {
+			out.WriteString(tag)
+		}
+		out.WriteString(strings.Repeat(string(d.char), d.count))
+		for i := len(d.openTags) - 1; i >= 0; i-- {
+			out.WriteString(d.openTags[i])
+		}
+	}
+	return out.String()
+}
+
+// flush turns the pending plain text into a token.
+func (p *inlineParser) flush() {
+	if p.plain.Len() > 0 {
+		p.tokens = append(p.tokens, inlineToken{html: p.plain.String()})
+		p.plain.Reset()
+	}
+}
+
+// pushDelimiter adds the * or _ run at text[start] to the delimiter stack and returns the index after it.
+func (p *inlineParser) pushDelimiter(start int) int {
+	text := p.text
+	c := text[start]
+	run := runLength(text, start, c)
+
+	before, after := ' ', ' '
+	if start > 0 {
+		before, _ = utf8.DecodeLastRuneInString(text[:start])
+	}
+	if start+run < len(text) {
+		after, _ = utf8.DecodeRuneInString(text[start+run:])
+	}
+	leftFlanking := !unicode.IsSpace(after) && (!isPunctRune(after) || unicode.IsSpace(before) || isPunctRune(before))
+	rightFlanking := !unicode.IsSpace(before) && (!isPunctRune(before) || unicode.IsSpace(after) || isPunctRune(after))
+
+	canOpen, canClose := leftFlanking, rightFlanking
+	if c == '_' {
+		canOpen = leftFlanking && (!rightFlanking || isPunctRune(before))
+		canClose = rightFlanking && (!leftFlanking || isPunctRune(after))
+	}
+	if !canOpen && !canClose {
+		p.plain.WriteString(text[start : start+run])
+		return start + run
+	}
+
+	p.flush()
+	p.delimiters++
+	d := &delimiter{char: c, pos: p.delimiters, count: run, length: run, canOpen: canOpen, canClose: canClose, prev: p.last}
+	if p.last != nil {
+		p.last.next = d
+	}
+	p.last = d
+	p.tokens = append(p.tokens, inlineToken{delim: d})
+	return start + run
+}
+
+// processEmphasis matches the emphasis delimiters above bottom and removes them from the stack.
+func (p *inlineParser) processEmphasis(bottom *delimiter) {
+	bottomPos := 0
+	if bottom != nil {
+		bottomPos = bottom.pos
+	}
+	// openersBottom remembers, per closer kind, below which position no opener can be found.
+	var openersBottom [2][2][3]int
+	for i := range openersBottom {
+		for j := range openersBottom[i] {
+			for k := range openersBottom[i][j] {
+				openersBottom[i][j][k] = bottomPos
+			}
+		}
+	}
+
+	var closer *delimiter
+	for d := p.last; d != nil && d.pos > bottomPos; d = d.prev {
+		closer = d
+	}
+
+	for closer != nil {
+		if !closer.canClose {
+			closer = closer.next
+			continue
+		}
+
+		kind := &openersBottom[boolIndex(closer.char == '_')][boolIndex(closer.canOpen)][closer.length%3]
+		opener := closer.prev
+		for opener != nil && opener.pos > *kind && !(opener.char == closer.char && opener.canOpen && !oddMatch(opener, closer)) {
+			opener = opener.prev
+		}
+		if opener == nil || opener.pos <= *kind {
+			*kind = bottomPos
+			if closer.prev != nil && closer.prev.pos > bottomPos {
+				*kind = closer.prev.pos
+			}
+			next := closer.next
+			if !closer.canOpen {
+				p.remove(closer)
+			}
+			closer = next
+			continue
+		}
+
+		used, tag := 1, "em"
+		if opener.count >= 2 && closer.count >= 2 {
+			used, tag = 2, "strong"
+		}
+		opener.count -= used
+		closer.count -= used
+		opener.openTags = append(opener.openTags, "<"+tag+">")
+		closer.closeTags = append(closer.closeTags, "</"+tag+">")
+
+		opener.next, closer.prev = closer, opener
+		if opener.count == 0 {
+			p.remove(opener)
+		}
+		if closer.count == 0 {
+			next := closer.next
+			p.remove(closer)
+			closer = next
+		}
+	}
+
+	for p.last != nil && p.last.pos > bottomPos {
+		p.remove(p.last)
+	}
+}
+
+func (p *inlineParser) remove(d *delimiter) {
+	if d.prev != nil {
+		d.prev.next = d.next
+	}
+	if d.next != nil {
+		d.next.prev = d.prev
+	}
+	if p.last == d {
+		p.last = d.prev
+	}
+}
+
+// oddMatch implements the "rule of 3": a run that can both open and close only matches runs whose
+// combined length is not a multiple of 3, unless both lengths are.
+func oddMatch(opener, closer *delimiter) bool {
+	return (opener.canClose || closer.canOpen) &&
+		(opener.length+closer.length)%3 == 0 &&
+		!(opener.length%3 == 0 && closer.length%3 == 0)
+}
+
+func boolIndex(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// closeBracket turns the innermost open bracket into a link when text[i] closes an inline link
+// [label](destination "title") and returns the number of bytes consumed, or 0 when it does not.
+func (p *inlineParser) closeBracket(i int) int {
+	if len(p.brackets) == 0 {
+		return 0
+	}
+	opener := p.brackets[len(p.brackets)-1]
+	active := len(p.brackets)-1 >= p.inactiveBelow
+	p.brackets = p.brackets[:len(p.brackets)-1]
+	if p.inactiveBelow > len(p.brackets) {
+		p.inactiveBelow = len(p.brackets)
+	}
+	if !active || i+1 >= len(p.text) || p.text[i+1] != '(' {
+		return 0
+	}
+
+	href, end, ok := p.parseLinkTail(i + 2)
+	if !ok {
+		return 0
+	}
+
+	p.flush()
+	p.processEmphasis(opener.bottom)
+	p.tokens[opener.token].html = `<a href="` + html.EscapeString(href) + `">`
+	p.tokens = append(p.tokens, inlineToken{html: "</a>"})
+	p.inactiveBelow = len(p.brackets)
+	return end - i
+}
+
+// parseLinkTail parses `destination "title")` following the opening parenthesis of an inline link
+// at text[start-1] and returns the destination and the index after the closing parenthesis. Raw
+// destinations may contain balanced parentheses, as in CommonMark.
+func (p *inlineParser) parseLinkTail(start int) (href string, end int, ok bool) {
+	if p.parenClose == nil {
+		p.indexLinks()
+	}
+	text := p.text
+
+	i := skipLinkSpace(text, start)
+	if i < len(text) && text[i] == '<' {
+		closing := strings.IndexAny(text[i+1:], "<>\n")
+		if closing == -1 || text[i+1+closing] != '>' {
+			return "", 0, false
+		}
+		href = text[i+1 : i+1+closing]
+		i += closing + 2
+	} else {
+		stop := p.spaceAt[i]
+		if closing := p.parenClose[start-1]; closing != -1 && closing < stop {
+			return text[i:closing], closing + 1, true
+		}
+		if p.parenDepth[stop] != p.parenDepth[i] {
+			return "", 0, false
+		}
+		href = text[i:stop]
+		i = stop
+	}
+
+	end, ok = p.linkTitleEnd(i)
+	return href, end, ok
+}
+
+// linkTitleEnd parses the optional link title and the closing parenthesis starting at text[i].
+// Results are memoized, unclosed links sharing a title would otherwise scan it again and again.
+func (p *inlineParser) linkTitleEnd(i int) (int, bool) {
+	if end, ok := p.titleEnds[i]; ok {
+		return end, end != -1
+	}
+	if p.titleEnds == nil {
+		p.titleEnds = map[int]int{}
+	}
+
+	text, end := p.text, -1
+	next := skipLinkSpace(text, i)
+	if next > i && next < len(text) && strings.IndexByte(`"'(`, text[next]) != -1 {
+		closing := text[next]
+		if closing == '(' {
+			closing = ')'
+		}
+		j := next + 1
+		for ; j < len(text) && text[j] != closing; j++ {
+			if text[j] == '\\' {
+				j++
+			} else if closing == ')' && text[j] == '(' {
+				j = len(text)
+			}
+		}
+		if j < len(text) {
+			next = skipLinkSpace(text, j+1)
+		}
+	}
+	if next < len(text) && text[next] == ')' {
+		end = next + 1
+	}
+
+	p.titleEnds[i] = end
+	return end, end != -1
+}
+
+// indexLinks precomputes matching parentheses, parenthesis depth and the next whitespace for
+// every byte, so each link destination is resolved without scanning the rest of the text.
+func (p *inlineParser) indexLinks() {
+	text := p.text
+	p.parenClose = make([]int, len(text))
+	p.parenDepth = make([]int, len(text)+1)
+	p.spaceAt = make([]int, len(text)+1)
+
+	var open []int
+	for i := 0; i < len(text); i++ {
+		p.parenClose[i] = -1
+		p.parenDepth[i] = len(open)
+		switch c := text[i]; {
+		case c == '\\' && i+1 < len(text) && isASCIIPunct(text[i+1]):
+			p.parenClose[i+1] = -1
+			p.parenDepth[i+1] = len(open)
+			i++
+		case c == '(':
+			open = append(open, i)
+		case c == ')' && len(open) > 0:
+			p.parenClose[open[len(open)-1]] = i
+			open = open[:len(open)-1]
+		}
+	}
+	p.parenDepth[len(text)] = len(open)
+
+	p.spaceAt[len(text)] = len(text)
+	for i := len(text) - 1; i >= 0; i-- {
+		p.spaceAt[i] = p.spaceAt[i+1]
+		if c := text[i]; c <= ' ' || c == 0x7f {
+			p.spaceAt[i] = i
+		}
+	}
+}
+
+// codeSpanEnd returns the start of the first backtick run of exactly run backticks at or after
+// from, or -1. Calls come with increasing from, so every run is skipped at most once.
+func (p *inlineParser) codeSpanEnd(from, run int) int {
+	if p.backticks == nil {
+		p.backticks = map[int][]int{}
+		for i := 0; i < len(p.text); {
+			if p.text[i] != '`' {
+				i++
+				continue
+			}
+			n := runLength(p.text, i, '`')
+			p.backticks[n] = append(p.backticks[n], i)
+			i += n
+		}
+	}
+
+	starts := p.backticks[run]
+	for len(starts) > 0 && starts[0] < from {
+		starts = starts[1:]
+	}
+	p.backticks[run] = starts
+	if len(starts) == 0 {
+		return -1
+	}
+	return starts[0]
+}
+
+func skipLinkSpace(text string, i int) int {
+	for i < len(text) && (text[i] == ' ' || text[i] == '\t' || text[i] == '\n') {
+		i++
+	}
+	return i
+}
+
+func runLength(text string, start int, c byte) int {
+	n := 0
+	for start+n < len(text) && text[start+n] == c {
+		n++
+	}
+	return n
+}
+
+func isASCIIPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) != -1
+}
+
+func isPunctRune(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
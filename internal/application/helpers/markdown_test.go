@@ -0,0 +1,152 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	testCases := []struct {
+		Name   string
+		Input  string
+		Result string
+	}{
+		{
+			Name:   "Paragraphs",
+			Input:  "first line\nsecond line\n\nnext paragraph",
+			Result: "<p>first line\nsecond line</p>\n<p>next paragraph</p>\n",
+		},
+		{
+			Name:   "Emphasis",
+			Input:  "*em* _em_ **strong** __strong__ ***both*** snake_case_name 2 * 3",
+			Result: "<p><em>em</em> <em>em</em> <strong>strong</strong> <strong>strong</strong> <em><strong>both</strong></em> snake_case_name 2 * 3</p>\n",
+		},
+		{
+			Name:   "Nested Emphasis",
+			Input:  "**a *b* c** *foo**bar**baz* **unclosed *em*",
+			Result: "<p><strong>a <em>b</em> c</strong> <em>foo<strong>bar</strong>baz</em> **unclosed <em>em</em></p>\n",
+		},
+		{
+			Name:   "Inline Code",
+			Input:  "run `go test ./...` or `` a`b ``",
+			Result: "<p>run <code>go test ./...</code> or <code>a`b</code></p>\n",
+		},
+		{
+			Name:   "Fenced Code",
+			Input:  "```go\nif a < b {\n}\n```",
+			Result: "<pre><code class=\"language-go\">if a &lt; b {\n}</code></pre>\n",
+		},
+		{
+			Name:   "Links",
+			Input:  "[the *docs*](https://go.dev/doc \"Go\") and <https://go.dev>",
+			Result: "<p><a href=\"https://go.dev/doc\" rel=\"nofollow ugc\">the <em>docs</em></a> and <a href=\"https://go.dev\" rel=\"nofollow ugc\">https://go.dev</a></p>\n",
+		},
+		{
+			Name:   "Nested Brackets",
+			Input:  "[a [b] c](https://go.dev) [x [y](https://a.dev)](https://b.dev)",
+			Result: "<p><a href=\"https://go.dev\" rel=\"nofollow ugc\">a [b] c</a> [x <a href=\"https://a.dev\" rel=\"nofollow ugc\">y</a>](https://b.dev)</p>\n",
+		},
+		{
+			Name:   "Unsafe Link",
+			Input:  "[click](javascript:alert(1)) [tab](java\tscript:alert(1))",
+			Result: "<p><a rel=\"nofollow ugc\">click</a> [tab](java\tscript:alert(1))</p>\n",
+		},
+		{
+			Name:   "Link With Parentheses",
+			Input:  "[Go](https://en.wikipedia.org/wiki/Go_(programming_language)) (see [spec](<https://go.dev/ref/spec> 'Spec'))",
+			Result: "<p><a href=\"https://en.wikipedia.org/wiki/Go_(programming_language)\" rel=\"nofollow ugc\">Go</a> (see <a href=\"https://go.dev/ref/spec\" rel=\"nofollow ugc\">spec</a>)</p>\n",
+		},
+		{
+			Name:   "Quote",
+			Input:  "> quoted\n> **text**\n\nafter",
+			Result: "<blockquote>\n<p>quoted\n<strong>text</strong></p>\n</blockquote>\n<p>after</p>\n",
+		},
+		{
+			Name:   "Unordered List",
+			Input:  "- one\n- two\n  - nested\n- three",
+			Result: "<ul>\n<li>one</li>\n<li>two\n<ul>\n<li>nested</li>\n</ul></li>\n<li>three</li>\n</ul>\n",
+		},
+		{
+			Name:   "Ordered List",
+			Input:  "3. three\n4. four",
+			Result: "<ol start=\"3\">\n<li>three</li>\n<li>four</li>\n</ol>\n",
+		},
+		{
+			Name:   "Raw HTML Is Escaped",
+			Input:  "<script>alert(1)</script><img src=x onerror=alert(1)>",
+			Result: "<p>&lt;script&gt;alert(1)&lt;/script&gt;&lt;img src=x onerror=alert(1)&gt;</p>\n",
+		},
+		{
+			Name:   "Escapes",
+			Input:  `\*not em\* & co`,
+			Result: "<p>*not em* &amp; co</p>\n",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.Name, func(t *testing.T) {
+			require.Equal(t, test.Result, RenderMarkdown(test.Input))
+		})
+	}
+}
+
+func TestRenderMarkdown_Pathological(t *testing.T) {
+	inputs := []string{
+		strings.Repeat("[", 50000),
+		strings.Repeat("[a](b", 50000),
+		strings.Repeat("[", 50000) + strings.Repeat("](x", 50000),
+		strings.Repeat("*a ", 50000),
+		strings.Repeat("_a_ *", 50000),
+		strings.Repeat("``a`", 50000),
+		strings.Repeat("[a](b \"", 50000),
+	}
+
+	start := time.Now()
+	for _, input := range inputs {
+		RenderMarkdown(input)
+	}
+	require.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestSanitizeHTML(t *testing.T) {
+	testCases := []struct {
+		Name   string
+		Input  string
+		Result string
+	}{
+		{
+			Name:   "Allowed Tags",
+			Input:  `<p class="x" onclick="evil()">hi <em>there</em></p>`,
+			Result: `<p>hi <em>there</em></p>`,
+		},
+		{
+			Name:   "Script Dropped With Content",
+			Input:  `a<script>alert("x")</script>b<style>p{}</style>c`,
+			Result: `abc`,
+		},
+		{
+			Name:   "Unknown Tags Dropped",
+			Input:  `<div><img src=x onerror=alert(1)>text</div>`,
+			Result: `text`,
+		},
+		{
+			Name:   "Links",
+			Input:  `<a href="HTTPS://example.com/?a=1&amp;b=2" rel="opener" target="_blank">x</a><a href="jav&#x09;ascript:alert(1)">y</a>`,
+			Result: `<a href="HTTPS://example.com/?a=1&amp;b=2" rel="nofollow ugc">x</a><a rel="nofollow ugc">y</a>`,
+		},
+		{
+			Name:   "Comments And Broken Tags",
+			Input:  `1 < 2 <!-- <script>x</script> --> & 3 > 2`,
+			Result: `1 &lt; 2  &amp; 3 &gt; 2`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.Name, func(t *testing.T) {
+			require.Equal(t, test.Result, SanitizeHTML(test.Input))
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package helpers
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+)
+
+// trackingParams are query parameters that only tell the linked site where the visitor came from.
+var trackingParams = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"dclid":   true,
+	"gbraid":  true,
+	"wbraid":  true,
+	"msclkid": true,
+	"yclid":   true,
+	"igshid":  true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"_hsenc":  true,
+	"_hsmi":   true,
+	"ref_src": true,
+}
+
+// NormalizeURL returns the canonical form of a link, so that the variants people paste for the same page compare equal.
+// The scheme becomes https, the host is lowercased without www and the default port, trailing slashes,
+// the fragment and tracking parameters are dropped and the remaining parameters are sorted.
+func NormalizeURL(link string) (string, error) {
+	target, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return "", model.ErrInvalidUrl
+	}
+	scheme := strings.ToLower(target.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return "", model.ErrInvalidUrl
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(target.Hostname()), ".")
+	if host == "" {
+		return "", model.ErrInvalidUrl
+	}
+	host = strings.TrimPrefix(host, "www.")
+	if port := target.Port(); port != "" && port != "80" && port != "443" {
+		host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+
+	path := strings.TrimRight(target.EscapedPath(), "/")
+
+	query, err := url.ParseQuery(target.RawQuery)
+	if err != nil {
+		return "", model.ErrInvalidUrl
+	}
+	for key := range query {
+		lower := strings.ToLower(key)
+		if strings.HasPrefix(lower, "utm_") || trackingParams[lower] {
+			query.Del(key)
+		}
+	}
+
+	canonical := "https://" + host + path
+	if encoded := query.Encode(); encoded != "" {
+		canonical += "?" + encoded
+	}
+	return canonical, nil
+}
@@ -0,0 +1,66 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	testCases := []struct {
+		Name      string
+		Link      string
+		Canonical string
+		Err       error
+	}{
+		{
+			Name:      "Scheme And Host",
+			Link:      "HTTP://WWW.Example.COM/Some/Path/",
+			Canonical: "https://example.com/Some/Path",
+		},
+		{
+			Name:      "Default Port",
+			Link:      "https://example.com:443",
+			Canonical: "https://example.com",
+		},
+		{
+			Name:      "Other Port",
+			Link:      "http://example.com:8080/",
+			Canonical: "https://example.com:8080",
+		},
+		{
+			Name:      "Tracking Parameters",
+			Link:      "https://example.com/a?utm_source=feed&b=2&UTM_Medium=x&fbclid=abc&a=1#comments",
+			Canonical: "https://example.com/a?a=1&b=2",
+		},
+		{
+			Name:      "Escaped Path",
+			Link:      "https://example.com/a%2Fb//",
+			Canonical: "https://example.com/a%2Fb",
+		},
+		{
+			Name:      "IPv6",
+			Link:      "http://[::1]:80/x",
+			Canonical: "https://[::1]/x",
+		},
+		{
+			Name: "Not HTTP",
+			Link: "ftp://example.com/file",
+			Err:  model.ErrInvalidUrl,
+		},
+		{
+			Name: "No Host",
+			Link: "https:///path",
+			Err:  model.ErrInvalidUrl,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			canonical, err := NormalizeURL(tc.Link)
+			require.Equal(t, tc.Err, err)
+			require.Equal(t, tc.Canonical, canonical)
+		})
+	}
+}
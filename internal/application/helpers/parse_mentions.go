@@ -0,0 +1,19 @@
+package helpers
+
+import "regexp"
+
+var mention = regexp.MustCompile(`(?:^|[^\w@/])@([A-Za-z0-9_-]{1,32})`)
+
+// ParseMentions returns the distinct usernames mentioned as @username in text.
+func ParseMentions(text string) []string {
+	seen := make(map[string]struct{})
+	usernames := []string{}
+	for _, match := range mention.FindAllStringSubmatch(text, -1) {
+		if _, ok := seen[match[1]]; ok {
+			continue
+		}
+		seen[match[1]] = struct{}{}
+		usernames = append(usernames, match[1])
+	}
+	return usernames
+}
@@ -0,0 +1,253 @@
+package helpers
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	entity        = regexp.MustCompile(`^&(#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[a-zA-Z][a-zA-Z0-9]{1,31});`)
+	languageClass = regexp.MustCompile(`^language-[\w+-]+$`)
+	digits        = regexp.MustCompile(`^[0-9]{1,9}$`)
+
+	allowedTags = map[string]bool{
+		"p": true, "br": true, "em": true, "strong": true, "code": true, "pre": true,
+		"blockquote": true, "ul": true, "ol": true, "li": true, "a": true,
+	}
+	voidTags = map[string]bool{
+		"br": true,
+	}
+	// rawTextTags have their content dropped together with the tag
+	rawTextTags = map[string]bool{
+		"script": true, "style": true, "iframe": true, "noscript": true, "textarea": true, "title": true,
+	}
+	allowedSchemes = map[string]bool{
+		"http": true, "https": true, "mailto": true,
+	}
+)
+
+type htmlTag struct {
+	name    string
+	closing bool
+	attrs   map[string]string
+}
+
+// SanitizeHTML keeps only allowlisted tags and attributes, drops unsafe link
+// targets and marks every link with rel="nofollow ugc".
+func SanitizeHTML(input string) string {
+	var out strings.Builder
+	skip := ""
+
+	for i := 0; i < len(input); {
+		if input[i] != '<' {
+			next := strings.IndexByte(input[i:], '<')
+			if next == -1 {
+				next = len(input) - i
+			}
+			if skip == "" {
+				out.WriteString(escapeText(input[i : i+next]))
+			}
+			i += next
+			continue
+		}
+
+		if strings.HasPrefix(input[i:], "<!--") {
+			end := strings.Index(input[i+4:], "-->")
+			if end == -1 {
+				break
+			}
+			i += 4 + end + 3
+			continue
+		}
+
+		tag, width, ok := parseTag(input[i:])
+		if !ok {
+			if skip == "" {
+				out.WriteString("&lt;")
+			}
+			i++
+			continue
+		}
+		i += width
+
+		if skip != "" {
+			if tag.closing && tag.name == skip {
+				skip = ""
+			}
+			continue
+		}
+		if rawTextTags[tag.name] {
+			if !tag.closing {
+				skip = tag.name
+			}
+			continue
+		}
+		if !allowedTags[tag.name] {
+			continue
+		}
+
+		if tag.closing {
+			if !voidTags[tag.name] {
+				out.WriteString("</" + tag.name + ">")
+			}
+			continue
+		}
+		out.WriteString(renderTag(tag))
+	}
+
+	return out.String()
+}
+
+func renderTag(tag htmlTag) string {
+	var out strings.Builder
+	out.WriteString("<" + tag.name)
+
+	switch tag.name {
+	case "a":
+		if href, ok := safeURL(tag.attrs["href"]); ok {
+			out.WriteString(` href="` + html.EscapeString(href) + `"`)
+		}
+		out.WriteString(` rel="nofollow ugc"`)
+	case "ol":
+		if start := tag.attrs["start"]; digits.MatchString(start) {
+			out.WriteString(` start="` + start + `"`)
+		}
+	case "code":
+		if class := tag.attrs["class"]; languageClass.MatchString(class) {
+			out.WriteString(` class="` + html.EscapeString(class) + `"`)
+		}
+	}
+
+	out.WriteString(">")
+	return out.String()
+}
+
+func safeURL(raw string) (string, bool) {
+	// browsers ignore whitespace and control characters inside schemes
+	cleaned := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, raw)
+	if cleaned == "" {
+		return "", false
+	}
+
+	parsed, err := url.Parse(cleaned)
+	if err != nil {
+		return "", false
+	}
+	if parsed.Scheme != "" && !allowedSchemes[strings.ToLower(parsed.Scheme)] {
+		return "", false
+	}
+	return cleaned, true
+}
+
+func parseTag(input string) (htmlTag, int, bool) {
+	tag := htmlTag{attrs: map[string]string{}}
+	i := 1
+	if i < len(input) && input[i] == '/' {
+		tag.closing = true
+		i++
+	}
+
+	start := i
+	for i < len(input) && isTagNameByte(input[i], i == start) {
+		i++
+	}
+	if i == start {
+		return tag, 0, false
+	}
+	tag.name = strings.ToLower(input[start:i])
+
+	for i < len(input) {
+		for i < len(input) && (isSpace(input[i]) || input[i] == '/') {
+			i++
+		}
+		if i >= len(input) {
+			return tag, 0, false
+		}
+		if input[i] == '>' {
+			return tag, i + 1, true
+		}
+
+		nameStart := i
+		for i < len(input) && !isSpace(input[i]) && !strings.ContainsRune(`"'>/=`, rune(input[i])) {
+			i++
+		}
+		if i == nameStart {
+			// a stray quote or equals sign, skip it
+			i++
+			continue
+		}
+		name := strings.ToLower(input[nameStart:i])
+
+		for i < len(input) && isSpace(input[i]) {
+			i++
+		}
+		value := ""
+		if i < len(input) && input[i] == '=' {
+			i++
+			for i < len(input) && isSpace(input[i]) {
+				i++
+			}
+			if i < len(input) && (input[i] == '"' || input[i] == '\'') {
+				quote := input[i]
+				end := strings.IndexByte(input[i+1:], quote)
+				if end == -1 {
+					return tag, 0, false
+				}
+				value = input[i+1 : i+1+end]
+				i += end + 2
+			} else {
+				valueStart := i
+				for i < len(input) && !isSpace(input[i]) && input[i] != '>' {
+					i++
+				}
+				value = input[valueStart:i]
+			}
+		}
+		if _, exists := tag.attrs[name]; !exists {
+			tag.attrs[name] = html.UnescapeString(value)
+		}
+	}
+
+	return tag, 0, false
+}
+
+// escapeText escapes markup characters while keeping well formed entities intact.
+func escapeText(text string) string {
+	var out strings.Builder
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '&':
+			if match := entity.FindString(text[i:]); match != "" {
+				out.WriteString(match)
+				i += len(match) - 1
+				continue
+			}
+			out.WriteString("&amp;")
+		case '>':
+			out.WriteString("&gt;")
+		case '"':
+			out.WriteString("&#34;")
+		default:
+			out.WriteByte(text[i])
+		}
+	}
+	return out.String()
+}
+
+func isTagNameByte(c byte, first bool) bool {
+	if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' {
+		return true
+	}
+	return !first && c >= '0' && c <= '9'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
@@ -0,0 +1,24 @@
+package helpers
+
+import (
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+)
+
+func TallyPoll(poll *model.Poll, now time.Time) {
+	counts := make(map[int]int64, len(poll.Options))
+	for _, vote := range poll.Votes {
+		counts[vote.Option]++
+	}
+
+	poll.Total = int64(len(poll.Votes))
+	for _, option := range poll.Options {
+		option.Votes = counts[option.ID]
+		option.Percentage = 0
+		if poll.Total != 0 {
+			option.Percentage = int64((float64(option.Votes) / float64(poll.Total)) * 100)
+		}
+	}
+	poll.Closed = poll.IsClosed(now)
+}
@@ -0,0 +1,156 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/application/helpers"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/google/uuid"
+)
+
+const (
+	UploadsPath = "/uploads/"
+
+	thumbnailSize   = 320
+	thumbnailSuffix = "_thumb.jpg"
+	maxImagePixels  = 40_000_000
+	maxGIFFrames    = 500
+)
+
+var imageIDPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\.(jpg|png|gif)$`)
+
+type ImageService struct {
+	blobStorage model.IBlobStorage
+	maxSize     int64
+}
+
+func NewImageService(blobStorage model.IBlobStorage, maxSize int64) *ImageService {
+	return &ImageService{
+		blobStorage: blobStorage,
+		maxSize:     maxSize,
+	}
+}
+
+func (s *ImageService) MaxSize() int64 {
+	return s.maxSize
+}
+
+// Upload validates an uploaded image, re-encodes it without metadata and stores it with a thumbnail.
+func (s *ImageService) Upload(ctx context.Context, data []byte) (*model.Image, error) {
+	if int64(len(data)) > s.maxSize {
+		return nil, model.ErrImageTooLarge
+	}
+
+	var ext string
+	switch http.DetectContentType(data) {
+	case "image/jpeg":
+		ext = ".jpg"
+	case "image/png":
+		ext = ".png"
+	case "image/gif":
+		ext = ".gif"
+	default:
+		return nil, model.ErrImageUnsupported
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || config.Width <= 0 || config.Height <= 0 {
+		return nil, model.ErrInvalidImage
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return nil, model.ErrImageTooLarge
+	}
+
+	var img image.Image
+	clean := new(bytes.Buffer)
+	switch ext {
+	case ".jpg":
+		img, err = jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, model.ErrInvalidImage
+		}
+		img = helpers.Orient(img, helpers.JPEGOrientation(data))
+		err = jpeg.Encode(clean, img, &jpeg.Options{Quality: 90})
+	case ".png":
+		img, err = png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, model.ErrInvalidImage
+		}
+		err = png.Encode(clean, img)
+	case ".gif":
+		// every frame is decoded at once, the sizes in the header only bound the first
+		if frames, pixels := helpers.GIFFrames(data); frames > maxGIFFrames || pixels > maxImagePixels {
+			return nil, model.ErrImageTooLarge
+		}
+		var animation *gif.GIF
+		animation, err = gif.DecodeAll(bytes.NewReader(data))
+		if err != nil || len(animation.Image) == 0 {
+			return nil, model.ErrInvalidImage
+		}
+		img = animation.Image[0]
+		err = gif.EncodeAll(clean, animation)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	thumb := new(bytes.Buffer)
+	if err := jpeg.Encode(thumb, helpers.Thumbnail(img, thumbnailSize), &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+
+	id := uuid.New().String()
+	result := newImage(id + ext)
+	if err := s.blobStorage.Put(ctx, result.ID, clean); err != nil {
+		return nil, err
+	}
+	if err := s.blobStorage.Put(ctx, id+thumbnailSuffix, thumb); err != nil {
+		return nil, err
+	}
+
+	result.Width = img.Bounds().Dx()
+	result.Height = img.Bounds().Dy()
+	return result, nil
+}
+
+func newImage(id string) *model.Image {
+	return &model.Image{
+		ID:           id,
+		URL:          UploadsPath + id,
+		ThumbnailURL: UploadsPath + strings.TrimSuffix(id, path.Ext(id)) + thumbnailSuffix,
+	}
+}
+
+// Stored rebuilds the image of a post from its id so clients can neither point it elsewhere nor make up
+// its size, it must have been uploaded and its size is read from the stored file.
+func (s *ImageService) Stored(ctx context.Context, img *model.Image) (*model.Image, error) {
+	if img == nil || !imageIDPattern.MatchString(img.ID) {
+		return nil, model.ErrInvalidImage
+	}
+	blob, err := s.blobStorage.Get(ctx, img.ID)
+	if err == model.ErrBlobNotFound {
+		return nil, model.ErrInvalidImage
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer blob.Close()
+
+	config, _, err := image.DecodeConfig(blob)
+	if err != nil {
+		return nil, model.ErrInvalidImage
+	}
+	result := newImage(img.ID)
+	result.Width = config.Width
+	result.Height = config.Height
+	return result, nil
+}
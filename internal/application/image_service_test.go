@@ -0,0 +1,148 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"testing"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+type FakeBlobStorage struct {
+	blobs map[string][]byte
+}
+
+func (s *FakeBlobStorage) Put(ctx context.Context, key string, blob io.Reader) error {
+	data, err := io.ReadAll(blob)
+	if err != nil {
+		return err
+	}
+	s.blobs[key] = data
+	return nil
+}
+
+func (s *FakeBlobStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	data, ok := s.blobs[key]
+	if !ok {
+		return nil, model.ErrBlobNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *FakeBlobStorage) Delete(ctx context.Context, key string) error {
+	delete(s.blobs, key)
+	return nil
+}
+
+func testPNG(t *testing.T, width, height int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	buf := new(bytes.Buffer)
+	require.NoError(t, png.Encode(buf, img))
+	return buf.Bytes()
+}
+
+func testGIF(t *testing.T, width, height, frames int) []byte {
+	animation := &gif.GIF{}
+	for i := 0; i < frames; i++ {
+		frame := image.NewPaletted(image.Rect(0, 0, width, height), palette.Plan9)
+		frame.SetColorIndex(0, 0, uint8(i))
+		animation.Image = append(animation.Image, frame)
+		animation.Delay = append(animation.Delay, 10)
+	}
+	buf := new(bytes.Buffer)
+	require.NoError(t, gif.EncodeAll(buf, animation))
+	return buf.Bytes()
+}
+
+func TestImageServiceUpload(t *testing.T) {
+	ctx := context.Background()
+	storage := &FakeBlobStorage{blobs: map[string][]byte{}}
+	imageService := NewImageService(storage, 1<<20)
+
+	t.Run("Success", func(t *testing.T) {
+		result, err := imageService.Upload(ctx, testPNG(t, 640, 480))
+		require.NoError(t, err)
+		require.Regexp(t, imageIDPattern, result.ID)
+		require.Equal(t, 640, result.Width)
+		require.Equal(t, 480, result.Height)
+		require.Equal(t, UploadsPath+result.ID, result.URL)
+
+		thumbKey := result.ThumbnailURL[len(UploadsPath):]
+		require.Contains(t, storage.blobs, result.ID)
+		require.Contains(t, storage.blobs, thumbKey)
+
+		thumb, err := jpeg.DecodeConfig(bytes.NewReader(storage.blobs[thumbKey]))
+		require.NoError(t, err)
+		require.Equal(t, 320, thumb.Width)
+		require.Equal(t, 240, thumb.Height)
+	})
+
+	t.Run("Success GIF", func(t *testing.T) {
+		result, err := imageService.Upload(ctx, testGIF(t, 40, 30, 3))
+		require.NoError(t, err)
+		require.Equal(t, 40, result.Width)
+		require.Equal(t, 30, result.Height)
+
+		stored, err := gif.DecodeAll(bytes.NewReader(storage.blobs[result.ID]))
+		require.NoError(t, err)
+		require.Len(t, stored.Image, 3)
+	})
+
+	t.Run("Error Too Many Frames", func(t *testing.T) {
+		_, err := imageService.Upload(ctx, testGIF(t, 1, 1, maxGIFFrames+1))
+		require.ErrorIs(t, err, model.ErrImageTooLarge)
+	})
+
+	t.Run("Error Unsupported", func(t *testing.T) {
+		_, err := imageService.Upload(ctx, []byte("<html><script>alert(1)</script></html>"))
+		require.ErrorIs(t, err, model.ErrImageUnsupported)
+	})
+
+	t.Run("Error Invalid", func(t *testing.T) {
+		data := testPNG(t, 10, 10)
+		_, err := imageService.Upload(ctx, data[:len(data)/2])
+		require.ErrorIs(t, err, model.ErrInvalidImage)
+	})
+
+	t.Run("Error Too Large", func(t *testing.T) {
+		small := NewImageService(storage, 16)
+		_, err := small.Upload(ctx, testPNG(t, 10, 10))
+		require.ErrorIs(t, err, model.ErrImageTooLarge)
+	})
+}
+
+func TestImageServiceStored(t *testing.T) {
+	ctx := context.Background()
+	storage := &FakeBlobStorage{blobs: map[string][]byte{}}
+	imageService := NewImageService(storage, 1<<20)
+
+	uploaded, err := imageService.Upload(ctx, testPNG(t, 64, 48))
+	require.NoError(t, err)
+
+	result, err := imageService.Stored(ctx, &model.Image{
+		ID:     uploaded.ID,
+		URL:    "https://evil.example/x.png",
+		Width:  10000,
+		Height: 1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, uploaded, result)
+
+	_, err = imageService.Stored(ctx, &model.Image{ID: "0b7c4a4e-6f3f-4a53-9d3a-0c43f8a1f2d1.png"})
+	require.ErrorIs(t, err, model.ErrInvalidImage)
+	_, err = imageService.Stored(ctx, &model.Image{ID: "../../etc/passwd"})
+	require.ErrorIs(t, err, model.ErrInvalidImage)
+}
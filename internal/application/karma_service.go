@@ -0,0 +1,89 @@
+package application
+
+import (
+	"context"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+)
+
+type KarmaService struct {
+	postStorage  model.IPostStorage
+	karmaStorage model.IKarmaStorage
+	banChecker   model.IBanChecker
+}
+
+func NewKarmaService(postStorage model.IPostStorage, karmaStorage model.IKarmaStorage) *KarmaService {
+	return &KarmaService{
+		postStorage:  postStorage,
+		karmaStorage: karmaStorage,
+	}
+}
+
+// SetBanChecker keeps the votes of shadow-banned users out of the rebuilt karma.
+func (s *KarmaService) SetBanChecker(banChecker model.IBanChecker) {
+	s.banChecker = banChecker
+}
+
+// Rebuild recomputes the karma of every user from the stored votes and returns the number of users with karma.
+func (s *KarmaService) Rebuild(ctx context.Context) (int, error) {
+	posts, err := s.postStorage.GetAllPosts(ctx)
+	if err != nil {
+		return 0, err
+	}
+	shadowBanned := map[string]bool{}
+	if s.banChecker != nil {
+		shadowBanned, err = s.banChecker.ShadowBanned(ctx)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	karma := make(map[string]*model.Karma)
+	for _, post := range posts {
+		for _, vote := range countedVotes(post.Author, post.Votes, shadowBanned) {
+			authorKarma(karma, post.Author).Post += vote.Score
+		}
+		for _, comment := range post.Comments {
+			for _, vote := range countedVotes(comment.Author, comment.Votes, shadowBanned) {
+				authorKarma(karma, comment.Author).Comment += vote.Score
+			}
+		}
+	}
+
+	if err := s.karmaStorage.ReplaceKarma(ctx, karma); err != nil {
+		return 0, err
+	}
+	return len(karma), nil
+}
+
+// countedVotes drops the votes that earn the author no karma, their own and those of shadow-banned users.
+func countedVotes(author *model.Author, votes []*model.Vote, shadowBanned map[string]bool) []*model.Vote {
+	if author == nil {
+		return nil
+	}
+	counted := make([]*model.Vote, 0, len(votes))
+	for _, vote := range votes {
+		if vote.UserID != author.ID && !shadowBanned[vote.UserID] {
+			counted = append(counted, vote)
+		}
+	}
+	return counted
+}
+
+// authorKarma returns the karma of the author, adding it on first use.
+func authorKarma(karma map[string]*model.Karma, author *model.Author) *model.Karma {
+	if _, ok := karma[author.ID]; !ok {
+		karma[author.ID] = &model.Karma{}
+	}
+	return karma[author.ID]
+}
+
+// voteScore returns the current vote of a user among the votes on a post or comment, zero when they have not voted.
+func voteScore(votes []*model.Vote, userID string) int64 {
+	for _, vote := range votes {
+		if vote.UserID == userID {
+			return vote.Score
+		}
+	}
+	return 0
+}
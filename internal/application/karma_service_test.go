@@ -0,0 +1,125 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/middleware"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/repository/inmemory"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestKarma(t *testing.T) {
+	ctx := context.Background()
+
+	userStorage := inmemory.NewUserStorage()
+	alice := &model.User{Username: "alice"}
+	bob := &model.User{Username: "bob"}
+	require.NoError(t, userStorage.AddUser(ctx, alice))
+	require.NoError(t, userStorage.AddUser(ctx, bob))
+
+	aliceAuthor := &model.Author{ID: alice.ID, Username: alice.Username}
+	bobAuthor := &model.Author{ID: bob.ID, Username: bob.Username}
+
+	t.Run("Incremental", func(t *testing.T) {
+		postService := NewPostService(nil, nil, nil)
+		postService.SetKarmaStorage(userStorage)
+
+		before := &model.Post{Author: aliceAuthor, Votes: []*model.Vote{{UserID: bob.ID, Score: -1}}}
+		after := &model.Post{Author: aliceAuthor, Votes: []*model.Vote{{UserID: bob.ID, Score: 1}}}
+		postService.updateKarma(ctx, after.Author, &model.Karma{Post: voteScore(after.Votes, bob.ID) - voteScore(before.Votes, bob.ID)}, bobAuthor)
+		require.Equal(t, int64(2), alice.PostKarma)
+
+		unvoted := &model.Post{Author: aliceAuthor}
+		postService.updateKarma(ctx, unvoted.Author, &model.Karma{Post: voteScore(unvoted.Votes, bob.ID) - voteScore(after.Votes, bob.ID)}, bobAuthor)
+		require.Equal(t, int64(1), alice.PostKarma)
+
+		postService.updateKarma(ctx, after.Author, &model.Karma{Post: 1}, aliceAuthor)
+		require.Equal(t, int64(1), alice.PostKarma)
+	})
+
+	t.Run("Comment Votes", func(t *testing.T) {
+		alice.CommentKarma = 0
+		postObjectID := primitive.NewObjectID()
+		comment := &model.Comment{ID: primitive.NewObjectID(), PostID: postObjectID, Author: aliceAuthor, Body: "hi"}
+		post := &model.Post{ID: postObjectID, Category: "news", Author: bobAuthor, Comments: []*model.Comment{comment}}
+		postStorage := &FakePostStorage{Posts: []*model.Post{post}}
+		banStorage := inmemory.NewBanStorage()
+		postService := NewPostService(postStorage, nil, model.TimeControllerFunc(time.Now))
+		postService.SetKarmaStorage(userStorage)
+		postService.SetBanChecker(NewBanService(banStorage, nil, nil, nil, model.TimeControllerFunc(time.Now)))
+		asAlice := context.WithValue(ctx, middleware.AuthorContextKey, aliceAuthor)
+		asBob := context.WithValue(ctx, middleware.AuthorContextKey, bobAuthor)
+		postID, commentID := post.ID.Hex(), comment.ID.Hex()
+
+		voted, err := postService.VoteComment(asBob, postID, commentID, "upvote")
+		require.NoError(t, err)
+		require.Equal(t, int64(1), voted.Comments[0].Score)
+		require.Equal(t, int64(1), alice.CommentKarma)
+
+		voted, err = postService.VoteComment(asBob, postID, commentID, "downvote")
+		require.NoError(t, err)
+		require.Equal(t, int64(-1), voted.Comments[0].Score)
+		require.Equal(t, int64(-1), alice.CommentKarma)
+
+		// a vote on your own comment counts toward its score but not toward your karma
+		voted, err = postService.VoteComment(asAlice, postID, commentID, "upvote")
+		require.NoError(t, err)
+		require.Equal(t, int64(0), voted.Comments[0].Score)
+		require.Equal(t, int64(-1), alice.CommentKarma)
+
+		_, err = postService.VoteComment(asBob, postID, commentID, "sidevote")
+		require.Equal(t, model.ErrVotesActionNotImplement, err)
+		_, err = postService.VoteComment(asBob, postID, primitive.NewObjectID().Hex(), "upvote")
+		require.Equal(t, model.ErrCommentNotFound, err)
+
+		// the vote of a shadow-banned bob stops counting, and counts again once the ban is lifted
+		require.NoError(t, banStorage.AddBan(ctx, model.NewBan(bobAuthor, "", true, "", nil, nil)))
+		require.NoError(t, postService.RecountVotes(ctx, bob.ID, false))
+		require.Equal(t, int64(1), postStorage.Posts[0].Comments[0].Score)
+		require.Equal(t, int64(0), alice.CommentKarma)
+		require.NoError(t, banStorage.RemoveBan(ctx, bob.ID, "", true))
+		require.NoError(t, postService.RecountVotes(ctx, bob.ID, true))
+		require.Equal(t, int64(0), postStorage.Posts[0].Comments[0].Score)
+		require.Equal(t, int64(-1), alice.CommentKarma)
+
+		voted, err = postService.VoteComment(asBob, postID, commentID, "unvote")
+		require.NoError(t, err)
+		require.Equal(t, int64(1), voted.Comments[0].Score)
+		require.Equal(t, int64(0), alice.CommentKarma)
+	})
+
+	t.Run("Rebuild", func(t *testing.T) {
+		alice.PostKarma, alice.CommentKarma = 42, 42
+		bob.PostKarma = 7
+
+		postStorage := &FakePostStorage{Posts: []*model.Post{
+			{Author: aliceAuthor, Votes: []*model.Vote{{UserID: alice.ID, Score: 1}, {UserID: bob.ID, Score: 1}}},
+			{Author: aliceAuthor, Votes: []*model.Vote{{UserID: bob.ID, Score: 1}, {UserID: "id3", Score: 1}}},
+			{Author: bobAuthor, Votes: []*model.Vote{{UserID: alice.ID, Score: -1}}, Comments: []*model.Comment{
+				{Author: aliceAuthor, Votes: []*model.Vote{{UserID: alice.ID, Score: 1}, {UserID: bob.ID, Score: 1}, {UserID: "id3", Score: 1}}},
+			}},
+		}}
+
+		users, err := NewKarmaService(postStorage, userStorage).Rebuild(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 2, users)
+		require.Equal(t, int64(3), alice.PostKarma)
+		require.Equal(t, int64(2), alice.CommentKarma)
+		require.Equal(t, int64(-1), bob.PostKarma)
+		require.Equal(t, int64(0), bob.CommentKarma)
+
+		// the votes of shadow-banned users do not count
+		banStorage := inmemory.NewBanStorage()
+		require.NoError(t, banStorage.AddBan(ctx, model.NewBan(&model.Author{ID: "id3"}, "", true, "", nil, nil)))
+		karmaService := NewKarmaService(postStorage, userStorage)
+		karmaService.SetBanChecker(NewBanService(banStorage, nil, nil, nil, model.TimeControllerFunc(time.Now)))
+		_, err = karmaService.Rebuild(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), alice.PostKarma)
+		require.Equal(t, int64(1), alice.CommentKarma)
+	})
+}
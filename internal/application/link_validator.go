@@ -0,0 +1,123 @@
+package application
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/application/helpers"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultLinkCheckAttempts = 5
+	defaultLinkCheckBackoff  = 30 * time.Second
+	maxLinkCheckBackoff      = 30 * time.Minute
+)
+
+// LinkValidator works the link queue of a PostService, making pending link posts live or rejected.
+type LinkValidator struct {
+	postService *PostService
+	queue       model.ILinkQueue
+	logger      *zap.SugaredLogger
+	attempts    int
+	backoff     time.Duration
+}
+
+func NewLinkValidator(postService *PostService, queue model.ILinkQueue, logger *zap.SugaredLogger) *LinkValidator {
+	return &LinkValidator{
+		postService: postService,
+		queue:       queue,
+		logger:      logger,
+		attempts:    defaultLinkCheckAttempts,
+		backoff:     defaultLinkCheckBackoff,
+	}
+}
+
+// Run checks queued links with the given number of workers until ctx is done.
+func (v *LinkValidator) Run(ctx context.Context, workers int) {
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				check, err := v.queue.Dequeue(ctx)
+				if ctx.Err() != nil {
+					return
+				}
+				if err != nil {
+					v.logger.Errorw("link queue unavailable", "error", err)
+					select {
+					case <-ctx.Done():
+						return
+					case <-time.After(time.Second):
+					}
+					continue
+				}
+				v.process(ctx, check)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (v *LinkValidator) process(ctx context.Context, check *model.LinkCheck) {
+	post, err := v.postService.postStorage.GetPostByID(ctx, check.PostID)
+	if err == model.ErrPostNotFound {
+		v.ack(ctx, check)
+		return
+	}
+	if err != nil {
+		v.retry(ctx, check, err)
+		return
+	}
+	if post.Status != model.PostStatusPending {
+		v.ack(ctx, check)
+		return
+	}
+
+	preview, err := helpers.CheckLink(ctx, v.postService.fetcher, check.URL)
+	if err == model.ErrLinkUnreachable && check.Attempt+1 < v.attempts {
+		v.retry(ctx, check, err)
+		return
+	}
+
+	status := model.PostStatusLive
+	if err != nil {
+		status = model.PostStatusRejected
+		preview = nil
+	}
+	if err := v.postService.finishLinkCheck(ctx, post, status, preview); err != nil {
+		v.retry(ctx, check, err)
+		return
+	}
+	v.ack(ctx, check)
+	v.logger.Infow("link checked", "post", post.ID.Hex(), "status", status, "attempts", check.Attempt+1)
+}
+
+// ack drops the finished check from the queue, should it fail the check is handed out again and finds the post done.
+func (v *LinkValidator) ack(ctx context.Context, check *model.LinkCheck) {
+	if err := v.queue.Ack(ctx, check); err != nil {
+		v.logger.Errorw("failed to acknowledge link check", "post", check.PostID.Hex(), "error", err)
+	}
+}
+
+// retry queues the check again after an exponential backoff, the leased one is kept should that fail.
+func (v *LinkValidator) retry(ctx context.Context, check *model.LinkCheck, cause error) {
+	delay := v.backoff << check.Attempt
+	if delay > maxLinkCheckBackoff || delay <= 0 {
+		delay = maxLinkCheckBackoff
+	}
+
+	next := *check
+	next.Attempt++
+	next.Due = v.postService.timeController.Now().Add(delay)
+	if err := v.queue.Enqueue(ctx, &next); err != nil {
+		v.logger.Errorw("failed to requeue link check", "post", check.PostID.Hex(), "error", err)
+		return
+	}
+	v.ack(ctx, check)
+	v.logger.Infow("link check postponed", "post", check.PostID.Hex(), "attempt", next.Attempt, "delay", delay, "cause", cause)
+}
@@ -0,0 +1,177 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/application/helpers"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/middleware"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/repository/inmemory"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type FakeNotifier struct {
+	model.INotifier
+	mu      sync.Mutex
+	added   []*model.Post
+	checked []*model.Post
+	warned  []string
+}
+
+func (n *FakeNotifier) PostAdded(ctx context.Context, post *model.Post) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.added = append(n.added, post)
+}
+
+func (n *FakeNotifier) LinkChecked(ctx context.Context, post *model.Post) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.checked = append(n.checked, post)
+}
+
+func (n *FakeNotifier) CommentAdded(ctx context.Context, post *model.Post, comment *model.Comment) {}
+
+func (n *FakeNotifier) Warned(ctx context.Context, post *model.Post, comment *model.Comment, message string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.warned = append(n.warned, message)
+}
+
+func (n *FakeNotifier) counts() (int, int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.added), len(n.checked)
+}
+
+func TestLinkValidator(t *testing.T) {
+	var mu sync.Mutex
+	unavailable := 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/flaky":
+			mu.Lock()
+			fail := unavailable > 0
+			unavailable--
+			mu.Unlock()
+			if fail {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><head><title>Back again</title></head></html>`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	_, loopback, err := net.ParseCIDR("127.0.0.1/32")
+	require.NoError(t, err)
+
+	postStorage := new(FakePostStorage)
+	queue := inmemory.NewLinkQueue()
+	notifier := new(FakeNotifier)
+
+	postService := NewPostService(postStorage, inmemory.NewModeratorStorage(), model.TimeControllerFunc(time.Now))
+	postService.SetFetcher(helpers.NewFetcher(helpers.FetchPolicy{AllowedNetworks: []*net.IPNet{loopback}}))
+	postService.SetNotifier(notifier)
+	postService.SetLinkQueue(queue)
+
+	validator := NewLinkValidator(postService, queue, zap.NewNop().Sugar())
+	validator.backoff = 10 * time.Millisecond
+
+	author := &model.Author{ID: "id1", Username: "alice"}
+	ctx := context.WithValue(context.Background(), middleware.AuthorContextKey, author)
+
+	live, err := postService.AddPost(ctx, &model.Post{Type: "link", Title: "Flaky", Category: "music", Url: server.URL + "/flaky"})
+	require.NoError(t, err)
+	require.Equal(t, model.PostStatusPending, live.Status)
+
+	rejected, err := postService.AddPost(ctx, &model.Post{Type: "link", Title: "Gone", Category: "music", Url: server.URL + "/gone"})
+	require.NoError(t, err)
+	require.Equal(t, model.PostStatusPending, rejected.Status)
+
+	added, checked := notifier.counts()
+	require.Equal(t, 0, added)
+	require.Equal(t, 0, checked)
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		validator.Run(runCtx, 2)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		_, checked := notifier.counts()
+		return checked == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+
+	post, err := postStorage.GetPostByID(ctx, live.ID)
+	require.NoError(t, err)
+	require.Equal(t, model.PostStatusLive, post.Status)
+	require.Equal(t, &model.LinkPreview{Title: "Back again", SiteName: "127.0.0.1"}, post.Preview)
+
+	post, err = postStorage.GetPostByID(ctx, rejected.ID)
+	require.NoError(t, err)
+	require.Equal(t, model.PostStatusRejected, post.Status)
+	require.Nil(t, post.Preview)
+
+	added, _ = notifier.counts()
+	require.Equal(t, 1, added)
+}
+
+type FailingLinkQueue struct {
+	model.ILinkQueue
+}
+
+func (q *FailingLinkQueue) Enqueue(ctx context.Context, check *model.LinkCheck) error {
+	return errors.New("queue unavailable")
+}
+
+func TestPendingLinksSurviveRestart(t *testing.T) {
+	postStorage := new(FakePostStorage)
+	postService := NewPostService(postStorage, inmemory.NewModeratorStorage(), model.TimeControllerFunc(time.Now))
+	postService.SetLinkQueue(inmemory.NewLinkQueue())
+
+	ctx := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id1", Username: "alice"})
+
+	pending, err := postService.AddPost(ctx, &model.Post{Type: "link", Title: "Pending", Category: "music", Url: "https://example.com/a"})
+	require.NoError(t, err)
+	require.Equal(t, model.PostStatusPending, pending.Status)
+
+	// the in-memory queue is lost with the process, the pending post is found in storage again
+	queue := inmemory.NewLinkQueue()
+	postService.SetLinkQueue(queue)
+	queued, err := postService.RequeuePendingLinks(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, queued)
+
+	dequeueCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	check, err := queue.Dequeue(dequeueCtx)
+	require.NoError(t, err)
+	require.Equal(t, pending.ID, check.PostID)
+	require.Equal(t, "https://example.com/a", check.URL)
+
+	// a post whose check cannot be queued is rejected rather than left pending forever
+	postService.SetLinkQueue(new(FailingLinkQueue))
+	_, err = postService.AddPost(ctx, &model.Post{Type: "link", Title: "Lost", Category: "music", Url: "https://example.com/b"})
+	require.EqualError(t, err, "queue unavailable")
+
+	posts, err := postStorage.GetAllPosts(ctx)
+	require.NoError(t, err)
+	require.Len(t, posts, 2)
+	require.Equal(t, model.PostStatusRejected, posts[1].Status)
+}
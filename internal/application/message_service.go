@@ -0,0 +1,222 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/middleware"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultMessagesLimit = 50
+	maxMessagesLimit     = 200
+)
+
+type MessageService struct {
+	messageStorage model.IMessageStorage
+	userStorage    model.IUserStorage
+	blockStorage   model.IBlockStorage
+}
+
+func NewMessageService(messageStorage model.IMessageStorage, userStorage model.IUserStorage) *MessageService {
+	return &MessageService{
+		messageStorage: messageStorage,
+		userStorage:    userStorage,
+	}
+}
+
+// SetBlockStorage stops users from messaging people who have blocked them.
+func (s *MessageService) SetBlockStorage(blockStorage model.IBlockStorage) {
+	s.blockStorage = blockStorage
+}
+
+// SendMessage sends a message to the named user, starting a conversation with them if needed.
+func (s *MessageService) SendMessage(ctx context.Context, username string, body string) (*model.Message, error) {
+	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+
+	body, err := validateMessage(body)
+	if err != nil {
+		return nil, err
+	}
+
+	recipient, err := s.userStorage.GetUser(ctx, username)
+	if err == model.ErrUserNotFound {
+		return nil, model.ErrInvalidRecipient
+	}
+	if err != nil {
+		return nil, err
+	}
+	if recipient.ID == author.ID {
+		return nil, model.ErrInvalidRecipient
+	}
+	if err := s.checkBlocked(ctx, []string{recipient.ID}, author); err != nil {
+		return nil, err
+	}
+
+	key := model.ConversationKey(author.ID, recipient.ID)
+	conversation, err := s.messageStorage.GetConversationByKey(ctx, key)
+	if err == model.ErrConversationNotFound {
+		conversation = model.NewConversation(author, &model.Author{ID: recipient.ID, Username: recipient.Username})
+		err = s.messageStorage.AddConversation(ctx, conversation)
+		if err == model.ErrConversationExist {
+			// the recipient messaged first in the meantime
+			conversation, err = s.messageStorage.GetConversationByKey(ctx, key)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	message := model.NewMessage(conversation.ID, author, body)
+	if err := s.messageStorage.AddMessage(ctx, conversation, message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// Reply sends a message to an existing conversation of the current user.
+func (s *MessageService) Reply(ctx context.Context, conversationID string, body string) (*model.Message, error) {
+	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+
+	body, err := validateMessage(body)
+	if err != nil {
+		return nil, err
+	}
+
+	conversation, err := s.getConversation(ctx, conversationID, author)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkBlocked(ctx, conversation.Members, author); err != nil {
+		return nil, err
+	}
+
+	message := model.NewMessage(conversation.ID, author, body)
+	if err := s.messageStorage.AddMessage(ctx, conversation, message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+func (s *MessageService) GetConversations(ctx context.Context, offset int, limit int) ([]*model.Conversation, error) {
+	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+
+	offset, limit = messagesPage(offset, limit)
+	conversations, err := s.messageStorage.GetConversations(ctx, author.ID, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, conversation := range conversations {
+		conversation.Unread = conversation.UnreadBy[author.ID]
+		if conversation.LastMessage != nil {
+			conversation.LastMessage.Read = conversation.ReadByOthers(conversation.LastMessage)
+		}
+	}
+	return conversations, nil
+}
+
+// GetMessages returns a page of the conversation history, newest first.
+func (s *MessageService) GetMessages(ctx context.Context, conversationID string, offset int, limit int) ([]*model.Message, error) {
+	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+
+	conversation, err := s.getConversation(ctx, conversationID, author)
+	if err != nil {
+		return nil, err
+	}
+
+	offset, limit = messagesPage(offset, limit)
+	messages, err := s.messageStorage.GetMessages(ctx, conversation.ID, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, message := range messages {
+		message.Read = conversation.ReadByOthers(message)
+	}
+	return messages, nil
+}
+
+// MarkRead marks every message of the conversation up to the latest one as read by the current user.
+func (s *MessageService) MarkRead(ctx context.Context, conversationID string) error {
+	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+
+	conversation, err := s.getConversation(ctx, conversationID, author)
+	if err != nil {
+		return err
+	}
+	if conversation.LastMessage == nil {
+		return nil
+	}
+
+	return s.messageStorage.MarkConversationRead(ctx, conversation, author.ID, conversation.LastMessage.ID)
+}
+
+func (s *MessageService) CountUnread(ctx context.Context) (int64, error) {
+	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+	return s.messageStorage.CountUnreadMessages(ctx, author.ID)
+}
+
+// getConversation hides conversations the user is not part of behind ErrConversationNotFound.
+func (s *MessageService) getConversation(ctx context.Context, conversationID string, author *model.Author) (*model.Conversation, error) {
+	conversationObjectID, err := primitive.ObjectIDFromHex(conversationID)
+	if err != nil {
+		return nil, model.ErrInvalidConversationID
+	}
+
+	conversation, err := s.messageStorage.GetConversation(ctx, conversationObjectID)
+	if err != nil {
+		return nil, err
+	}
+	if !conversation.HasMember(author.ID) {
+		return nil, model.ErrConversationNotFound
+	}
+	return conversation, nil
+}
+
+// checkBlocked fails when any of the recipients has blocked the author.
+func (s *MessageService) checkBlocked(ctx context.Context, recipients []string, author *model.Author) error {
+	if s.blockStorage == nil {
+		return nil
+	}
+	for _, recipient := range recipients {
+		if recipient == author.ID {
+			continue
+		}
+		blocked, err := s.blockStorage.IsBlocked(ctx, recipient, author.ID)
+		if err != nil {
+			return err
+		}
+		if blocked {
+			return model.ErrBlocked
+		}
+	}
+	return nil
+}
+
+func validateMessage(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", model.ErrMessageEmpty
+	}
+	if utf8.RuneCountInString(body) > model.MessageMaxLength {
+		return "", model.ErrMessageTooLong
+	}
+	return body, nil
+}
+
+func messagesPage(offset int, limit int) (int, int) {
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 {
+		limit = defaultMessagesLimit
+	}
+	if limit > maxMessagesLimit {
+		limit = maxMessagesLimit
+	}
+	return offset, limit
+}
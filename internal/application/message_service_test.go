@@ -0,0 +1,153 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/middleware"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model/mocks"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/repository/inmemory"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMessageService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userStorage := mocks.NewMockIUserStorage(ctrl)
+	messageService := NewMessageService(inmemory.NewMessageStorage(), userStorage)
+
+	alice := &model.Author{ID: "id1", Username: "alice"}
+	bob := &model.Author{ID: "id2", Username: "bob"}
+	eve := &model.Author{ID: "id3", Username: "eve"}
+	aliceCtx := context.WithValue(context.Background(), middleware.AuthorContextKey, alice)
+	bobCtx := context.WithValue(context.Background(), middleware.AuthorContextKey, bob)
+	eveCtx := context.WithValue(context.Background(), middleware.AuthorContextKey, eve)
+
+	userStorage.EXPECT().GetUser(gomock.Any(), "bob").Return(&model.User{ID: "id2", Username: "bob"}, nil).Times(2)
+	userStorage.EXPECT().GetUser(gomock.Any(), "alice").Return(&model.User{ID: "id1", Username: "alice"}, nil).Times(2)
+	userStorage.EXPECT().GetUser(gomock.Any(), "ghost").Return(nil, model.ErrUserNotFound)
+
+	first, err := messageService.SendMessage(aliceCtx, "bob", "  hi bob  ")
+	require.NoError(t, err)
+	require.Equal(t, "hi bob", first.Body)
+
+	second, err := messageService.SendMessage(aliceCtx, "bob", "are you there?")
+	require.NoError(t, err)
+	require.Equal(t, first.ConversationID, second.ConversationID)
+	conversationID := first.ConversationID.Hex()
+
+	t.Run("Invalid Messages", func(t *testing.T) {
+		_, err := messageService.SendMessage(aliceCtx, "alice", "hi me")
+		require.Equal(t, model.ErrInvalidRecipient, err)
+
+		_, err = messageService.SendMessage(aliceCtx, "ghost", "hi")
+		require.Equal(t, model.ErrInvalidRecipient, err)
+
+		_, err = messageService.Reply(aliceCtx, conversationID, " ")
+		require.Equal(t, model.ErrMessageEmpty, err)
+
+		_, err = messageService.Reply(aliceCtx, conversationID, strings.Repeat("a", model.MessageMaxLength+1))
+		require.Equal(t, model.ErrMessageTooLong, err)
+
+		_, err = messageService.Reply(aliceCtx, "bad", "hi")
+		require.Equal(t, model.ErrInvalidConversationID, err)
+
+		_, err = messageService.Reply(aliceCtx, primitive.NewObjectID().Hex(), "hi")
+		require.Equal(t, model.ErrConversationNotFound, err)
+	})
+
+	t.Run("Outsiders", func(t *testing.T) {
+		_, err := messageService.GetMessages(eveCtx, conversationID, 0, 0)
+		require.Equal(t, model.ErrConversationNotFound, err)
+
+		_, err = messageService.Reply(eveCtx, conversationID, "hi")
+		require.Equal(t, model.ErrConversationNotFound, err)
+	})
+
+	t.Run("Unread And Receipts", func(t *testing.T) {
+		count, err := messageService.CountUnread(bobCtx)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), count)
+
+		count, err = messageService.CountUnread(aliceCtx)
+		require.NoError(t, err)
+		require.Equal(t, int64(0), count)
+
+		conversations, err := messageService.GetConversations(bobCtx, 0, 0)
+		require.NoError(t, err)
+		require.Len(t, conversations, 1)
+		require.Equal(t, int64(2), conversations[0].Unread)
+		require.Equal(t, "are you there?", conversations[0].LastMessage.Body)
+		require.False(t, conversations[0].LastMessage.Read)
+
+		require.NoError(t, messageService.MarkRead(bobCtx, conversationID))
+
+		count, err = messageService.CountUnread(bobCtx)
+		require.NoError(t, err)
+		require.Equal(t, int64(0), count)
+
+		messages, err := messageService.GetMessages(aliceCtx, conversationID, 0, 0)
+		require.NoError(t, err)
+		require.Len(t, messages, 2)
+		require.Equal(t, second.ID, messages[0].ID)
+		require.True(t, messages[0].Read)
+		require.True(t, messages[1].Read)
+	})
+
+	t.Run("Reply And Pagination", func(t *testing.T) {
+		reply, err := messageService.Reply(bobCtx, conversationID, "yes")
+		require.NoError(t, err)
+
+		_, err = messageService.SendMessage(bobCtx, "alice", "still here")
+		require.NoError(t, err)
+
+		count, err := messageService.CountUnread(aliceCtx)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), count)
+
+		messages, err := messageService.GetMessages(aliceCtx, conversationID, 1, 2)
+		require.NoError(t, err)
+		require.Len(t, messages, 2)
+		require.Equal(t, reply.ID, messages[0].ID)
+		require.False(t, messages[0].Read)
+		require.Equal(t, second.ID, messages[1].ID)
+	})
+}
+
+// RacingMessageStorage misses the conversation once, as if the other member started it concurrently.
+type RacingMessageStorage struct {
+	*inmemory.MessageStorage
+	missed bool
+}
+
+func (s *RacingMessageStorage) GetConversationByKey(ctx context.Context, key string) (*model.Conversation, error) {
+	if !s.missed {
+		s.missed = true
+		return nil, model.ErrConversationNotFound
+	}
+	return s.MessageStorage.GetConversationByKey(ctx, key)
+}
+
+func TestSendMessage_ConcurrentConversation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userStorage := mocks.NewMockIUserStorage(ctrl)
+	userStorage.EXPECT().GetUser(gomock.Any(), "bob").Return(&model.User{ID: "id2", Username: "bob"}, nil)
+
+	alice := &model.Author{ID: "id1", Username: "alice"}
+	bob := &model.Author{ID: "id2", Username: "bob"}
+	messageStorage := &RacingMessageStorage{MessageStorage: inmemory.NewMessageStorage()}
+	existing := model.NewConversation(bob, alice)
+	require.NoError(t, messageStorage.AddConversation(context.Background(), existing))
+
+	messageService := NewMessageService(messageStorage, userStorage)
+	message, err := messageService.SendMessage(context.WithValue(context.Background(), middleware.AuthorContextKey, alice), "bob", "hi bob")
+	require.NoError(t, err)
+	require.Equal(t, existing.ID, message.ConversationID)
+}
@@ -0,0 +1,75 @@
+package application
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/middleware"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+)
+
+const (
+	defaultModLogLimit = 50
+	maxModLogLimit     = 200
+)
+
+type ModLogService struct {
+	modLogStorage    model.IModLogStorage
+	moderatorStorage model.IModeratorStorage
+	categoryStorage  model.ICategoryStorage
+}
+
+func NewModLogService(modLogStorage model.IModLogStorage, moderatorStorage model.IModeratorStorage) *ModLogService {
+	return &ModLogService{
+		modLogStorage:    modLogStorage,
+		moderatorStorage: moderatorStorage,
+	}
+}
+
+// SetCategoryStorage lets the moderators of a category read the log of their categories.
+func (s *ModLogService) SetCategoryStorage(categoryStorage model.ICategoryStorage) {
+	s.categoryStorage = categoryStorage
+}
+
+// GetModLog returns the moderation log, newest first. Site moderators see every entry, the moderators
+// of a category the entries in their categories, other users only the actions taken on their own content,
+// reasons included, but never their shadow bans.
+func (s *ModLogService) GetModLog(ctx context.Context, filter model.ModLogFilter) ([]*model.ModAction, error) {
+	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+	all, moderated, err := moderatedCategories(ctx, s.moderatorStorage, s.categoryStorage, author)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case all, filter.Category != "" && moderated[filter.Category]:
+	case filter.Category == "" && len(moderated) > 0:
+		for category := range moderated {
+			filter.Categories = append(filter.Categories, category)
+		}
+		sort.Strings(filter.Categories)
+	default:
+		filter.TargetID = author.ID
+		filter.ExcludeActions = []string{model.ModActionShadowBan, model.ModActionShadowUnban}
+	}
+
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultModLogLimit
+	}
+	if filter.Limit > maxModLogLimit {
+		filter.Limit = maxModLogLimit
+	}
+
+	actions, err := s.modLogStorage.GetModActions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, action := range actions {
+		if action.Post != nil {
+			action.Post.URL = postPath(action.Post.Category, action.Post.ID)
+		}
+	}
+	return actions, nil
+}
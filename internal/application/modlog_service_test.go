@@ -0,0 +1,62 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/middleware"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/repository/inmemory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModLog(t *testing.T) {
+	moderatorStorage := inmemory.NewModeratorStorage("mod")
+	modLogStorage := inmemory.NewModLogStorage()
+	postService := NewPostService(new(FakePostStorage), moderatorStorage, model.TimeControllerFunc(time.Now))
+	postService.SetModLogStorage(modLogStorage)
+	reportService := NewReportService(inmemory.NewReportStorage(), postService)
+	modLogService := NewModLogService(modLogStorage, moderatorStorage)
+
+	alice := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id1", Username: "alice"})
+	bob := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id2", Username: "bob"})
+	mod := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id3", Username: "mod"})
+
+	news, err := postService.AddPost(alice, &model.Post{Type: "text", Title: "News", Category: "news", Text: "hi"})
+	require.NoError(t, err)
+	music, err := postService.AddPost(bob, &model.Post{Type: "text", Title: "Music", Category: "music", Text: "off topic"})
+	require.NoError(t, err)
+
+	_, err = postService.Moderate(mod, news.ID.Hex(), "lock", "heated thread", 0)
+	require.NoError(t, err)
+	_, err = reportService.Report(alice, music.ID.Hex(), "", "off topic")
+	require.NoError(t, err)
+	_, err = reportService.Resolve(mod, music.ID.Hex(), "", model.ReportRemove, "not about music", 0)
+	require.NoError(t, err)
+
+	actions, err := modLogService.GetModLog(mod, model.ModLogFilter{})
+	require.NoError(t, err)
+	require.Len(t, actions, 2)
+	require.Equal(t, model.ModActionRemovePost, actions[0].Action)
+	require.Equal(t, "not about music", actions[0].Reason)
+	require.Equal(t, "mod", actions[0].Moderator.Username)
+	require.Equal(t, "bob", actions[0].Target.Username)
+	require.Equal(t, "/a/music/"+music.ID.Hex(), actions[0].Post.URL)
+	require.Equal(t, model.ModActionLock, actions[1].Action)
+
+	actions, err = modLogService.GetModLog(mod, model.ModLogFilter{Category: "news"})
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+	require.Equal(t, "heated thread", actions[0].Reason)
+
+	actions, err = modLogService.GetModLog(mod, model.ModLogFilter{Action: model.ModActionLock, Moderator: "someone"})
+	require.NoError(t, err)
+	require.Empty(t, actions)
+
+	// authors only see what was done to their own content
+	actions, err = modLogService.GetModLog(bob, model.ModLogFilter{})
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+	require.Equal(t, "not about music", actions[0].Reason)
+}
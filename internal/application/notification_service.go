@@ -0,0 +1,176 @@
+package application
+
+import (
+	"context"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/application/helpers"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/middleware"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultNotificationsLimit = 50
+	maxNotificationsLimit     = 200
+)
+
+type NotificationService struct {
+	notificationStorage model.INotificationStorage
+	userStorage         model.IUserStorage
+	blockStorage        model.IBlockStorage
+	logger              *zap.SugaredLogger
+}
+
+func NewNotificationService(notificationStorage model.INotificationStorage, userStorage model.IUserStorage, logger *zap.SugaredLogger) *NotificationService {
+	return &NotificationService{
+		notificationStorage: notificationStorage,
+		userStorage:         userStorage,
+		logger:              logger,
+	}
+}
+
+// SetBlockStorage keeps users from being notified about what the users they blocked or muted do.
+func (s *NotificationService) SetBlockStorage(blockStorage model.IBlockStorage) {
+	s.blockStorage = blockStorage
+}
+
+// PostAdded notifies users mentioned in a new post.
+func (s *NotificationService) PostAdded(ctx context.Context, post *model.Post) {
+	recipients := make(map[string]string)
+	s.addMentions(ctx, recipients, post.Author, post.Title+"\n"+post.Text)
+	s.send(ctx, recipients, post.Author, post, nil)
+}
+
+// CommentAdded notifies the parent comment author, the post author and mentioned
+// users, each of them receives a single notification of the most specific type.
+func (s *NotificationService) CommentAdded(ctx context.Context, post *model.Post, comment *model.Comment) {
+	recipients := make(map[string]string)
+	s.addMentions(ctx, recipients, comment.Author, comment.Body)
+
+	if post.Author != nil && post.Author.ID != comment.Author.ID {
+		recipients[post.Author.ID] = model.NotificationPostReply
+	}
+
+	if comment.ParentID != nil {
+		for _, parent := range post.Comments {
+			if parent.ID == *comment.ParentID && parent.Author != nil && parent.Author.ID != comment.Author.ID {
+				recipients[parent.Author.ID] = model.NotificationCommentReply
+			}
+		}
+	}
+
+	s.send(ctx, recipients, comment.Author, post, comment)
+}
+
+// LinkChecked tells the author whether their link post went live or its link was rejected.
+func (s *NotificationService) LinkChecked(ctx context.Context, post *model.Post) {
+	if post.Author == nil {
+		return
+	}
+	kind := model.NotificationPostLive
+	if post.Status == model.PostStatusRejected {
+		kind = model.NotificationPostRejected
+	}
+	s.send(ctx, map[string]string{post.Author.ID: kind}, nil, post, nil)
+}
+
+func (s *NotificationService) Warned(ctx context.Context, post *model.Post, comment *model.Comment, message string) {
+	author := post.Author
+	if comment != nil {
+		author = comment.Author
+	}
+	if author == nil {
+		return
+	}
+	notification := model.NewNotification(model.NotificationWarning, author.ID, nil, post, comment)
+	notification.Message = message
+	if err := s.notificationStorage.AddNotification(ctx, notification); err != nil {
+		s.logger.Errorw("failed to add notification", "user", author.ID, "type", model.NotificationWarning, "error", err)
+	}
+}
+
+func (s *NotificationService) GetNotifications(ctx context.Context, unreadOnly bool, offset int, limit int) ([]*model.Notification, error) {
+	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 {
+		limit = defaultNotificationsLimit
+	}
+	if limit > maxNotificationsLimit {
+		limit = maxNotificationsLimit
+	}
+
+	return s.notificationStorage.GetNotifications(ctx, author.ID, unreadOnly, offset, limit)
+}
+
+// MarkRead marks the given notifications as read, or all of them when ids is empty.
+func (s *NotificationService) MarkRead(ctx context.Context, ids []string) error {
+	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+
+	if len(ids) == 0 {
+		return s.notificationStorage.MarkAllRead(ctx, author.ID)
+	}
+
+	objectIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return model.ErrInvalidNotificationID
+		}
+		objectIDs = append(objectIDs, objectID)
+	}
+
+	return s.notificationStorage.MarkRead(ctx, author.ID, objectIDs)
+}
+
+func (s *NotificationService) CountUnread(ctx context.Context) (int64, error) {
+	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+	return s.notificationStorage.CountUnread(ctx, author.ID)
+}
+
+func (s *NotificationService) addMentions(ctx context.Context, recipients map[string]string, actor *model.Author, text string) {
+	for _, username := range helpers.ParseMentions(text) {
+		if username == actor.Username {
+			continue
+		}
+		user, err := s.userStorage.GetUser(ctx, username)
+		if err != nil {
+			continue
+		}
+		recipients[user.ID] = model.NotificationMention
+	}
+}
+
+func (s *NotificationService) send(ctx context.Context, recipients map[string]string, actor *model.Author, post *model.Post, comment *model.Comment) {
+	for userID, kind := range recipients {
+		if s.silenced(ctx, userID, actor) {
+			continue
+		}
+		notification := model.NewNotification(kind, userID, actor, post, comment)
+		if err := s.notificationStorage.AddNotification(ctx, notification); err != nil {
+			s.logger.Errorw("failed to add notification", "user", userID, "type", kind, "error", err)
+		}
+	}
+}
+
+// silenced reports whether the user blocked or muted the actor. The notification is dropped when
+// the blocks can't be read, rather than risk showing the user someone they blocked.
+func (s *NotificationService) silenced(ctx context.Context, userID string, actor *model.Author) bool {
+	if s.blockStorage == nil || actor == nil {
+		return false
+	}
+	blocks, err := s.blockStorage.GetBlocks(ctx, userID)
+	if err != nil {
+		s.logger.Errorw("failed to get blocks", "user", userID, "error", err)
+		return true
+	}
+	for _, block := range blocks {
+		if block.Target != nil && block.Target.ID == actor.ID {
+			return true
+		}
+	}
+	return false
+}
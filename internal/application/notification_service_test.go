@@ -0,0 +1,109 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/middleware"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model/mocks"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/repository/inmemory"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+func TestNotificationServiceCommentAdded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userStorage := mocks.NewMockIUserStorage(ctrl)
+	notificationStorage := inmemory.NewNotificationStorage()
+	notificationService := NewNotificationService(notificationStorage, userStorage, zap.NewNop().Sugar())
+
+	postAuthor := &model.Author{ID: "id0", Username: "poster"}
+	parentAuthor := &model.Author{ID: "id1", Username: "parent"}
+	commenter := &model.Author{ID: "id2", Username: "commenter"}
+
+	parent := &model.Comment{ID: primitive.NewObjectID(), Author: parentAuthor}
+	post := &model.Post{
+		ID:       primitive.NewObjectID(),
+		Title:    "title",
+		Author:   postAuthor,
+		Comments: []*model.Comment{parent},
+	}
+	comment := model.NewComment("@parent @friend @commenter @ghost me@mail.com", commenter)
+	comment.ID = primitive.NewObjectID()
+	comment.ParentID = &parent.ID
+
+	userStorage.EXPECT().GetUser(gomock.Any(), "parent").Return(&model.User{ID: "id1", Username: "parent"}, nil)
+	userStorage.EXPECT().GetUser(gomock.Any(), "friend").Return(&model.User{ID: "id3", Username: "friend"}, nil)
+	userStorage.EXPECT().GetUser(gomock.Any(), "ghost").Return(nil, model.ErrUserNotFound)
+
+	notificationService.CommentAdded(context.Background(), post, comment)
+
+	expected := map[string]string{
+		"id0": model.NotificationPostReply,
+		"id1": model.NotificationCommentReply,
+		"id3": model.NotificationMention,
+	}
+	for userID, kind := range expected {
+		ctx := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: userID})
+
+		notifications, err := notificationService.GetNotifications(ctx, true, 0, 0)
+		require.NoError(t, err)
+		require.Len(t, notifications, 1)
+		require.Equal(t, kind, notifications[0].Type)
+		require.Equal(t, commenter, notifications[0].Actor)
+		require.Equal(t, post.ID, notifications[0].PostID)
+		require.Equal(t, &comment.ID, notifications[0].CommentID)
+
+		count, err := notificationService.CountUnread(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), count)
+
+		require.NoError(t, notificationService.MarkRead(ctx, []string{notifications[0].ID.Hex()}))
+		count, err = notificationService.CountUnread(ctx)
+		require.NoError(t, err)
+		require.Zero(t, count)
+	}
+
+	ctx := context.WithValue(context.Background(), middleware.AuthorContextKey, commenter)
+	count, err := notificationService.CountUnread(ctx)
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	require.ErrorIs(t, notificationService.MarkRead(ctx, []string{"bad"}), model.ErrInvalidNotificationID)
+}
+
+func TestNotificationServiceBlocks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userStorage := mocks.NewMockIUserStorage(ctrl)
+	notificationStorage := inmemory.NewNotificationStorage()
+	blockStorage := inmemory.NewBlockStorage()
+	notificationService := NewNotificationService(notificationStorage, userStorage, zap.NewNop().Sugar())
+	notificationService.SetBlockStorage(blockStorage)
+
+	postAuthor := &model.Author{ID: "id0", Username: "poster"}
+	commenter := &model.Author{ID: "id2", Username: "commenter"}
+	post := &model.Post{ID: primitive.NewObjectID(), Title: "title", Author: postAuthor}
+	comment := model.NewComment("@muter @friend", commenter)
+	comment.ID = primitive.NewObjectID()
+
+	require.NoError(t, blockStorage.AddBlock(context.Background(), model.NewBlock("id0", commenter, model.BlockBlock)))
+	require.NoError(t, blockStorage.AddBlock(context.Background(), model.NewBlock("id1", commenter, model.BlockMute)))
+	userStorage.EXPECT().GetUser(gomock.Any(), "muter").Return(&model.User{ID: "id1", Username: "muter"}, nil)
+	userStorage.EXPECT().GetUser(gomock.Any(), "friend").Return(&model.User{ID: "id3", Username: "friend"}, nil)
+
+	notificationService.CommentAdded(context.Background(), post, comment)
+
+	for userID, count := range map[string]int64{"id0": 0, "id1": 0, "id3": 1} {
+		ctx := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: userID})
+		unread, err := notificationService.CountUnread(ctx)
+		require.NoError(t, err)
+		require.Equal(t, count, unread, userID)
+	}
+}
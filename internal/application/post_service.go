@@ -4,6 +4,7 @@ import (
 	"context"
 	"sort"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/Totus-Floreo/asperitas-on-go/internal/application/helpers"
@@ -12,30 +13,151 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const (
+	defaultCommentsLimit   = 25
+	maxCommentsLimit       = 100
+	defaultDuplicateWindow = 7 * 24 * time.Hour
+	defaultRestoreWindow   = 7 * 24 * time.Hour
+	modLogExcerptLength    = 200
+)
+
 type PostService struct {
-	postStorage model.IPostStorage
+	postStorage      model.IPostStorage
+	moderatorStorage model.IModeratorStorage
+	timeController   model.ITimeController
+	archiveAfter     time.Duration
+	duplicateWindow  time.Duration
+	restoreWindow    time.Duration
+	notifier         model.INotifier
+	broker           model.IEventBroker
+	blockStorage     model.IBlockStorage
+	karmaStorage     model.IKarmaStorage
+	fetcher          *helpers.Fetcher
+	linkQueue        model.ILinkQueue
+	contentFilters   []model.IContentFilter
+	modLogStorage    model.IModLogStorage
+	banChecker       model.IBanChecker
+	categoryStorage  model.ICategoryStorage
+	reportStorage    model.IReportStorage
+	imageService     *ImageService
 }
 
-func NewPostService(postStorage model.IPostStorage) *PostService {
+func NewPostService(postStorage model.IPostStorage, moderatorStorage model.IModeratorStorage, timeController model.ITimeController) *PostService {
 	return &PostService{
-		postStorage: postStorage,
+		postStorage:      postStorage,
+		moderatorStorage: moderatorStorage,
+		timeController:   timeController,
+		fetcher:          helpers.NewFetcher(helpers.FetchPolicy{}),
+		duplicateWindow:  defaultDuplicateWindow,
+		restoreWindow:    defaultRestoreWindow,
 	}
 }
 
+// SetFetcher replaces the fetcher used to check submitted links.
+func (s *PostService) SetFetcher(fetcher *helpers.Fetcher) {
+	s.fetcher = fetcher
+}
+
+// SetNotifier registers a notifier that is told about new posts and comments.
+func (s *PostService) SetNotifier(notifier model.INotifier) {
+	s.notifier = notifier
+}
+
+// SetBroker registers a broker that receives live update events.
+func (s *PostService) SetBroker(broker model.IEventBroker) {
+	s.broker = broker
+}
+
+// SetBlockStorage enables hiding blocked and muted users' content from listings.
+func (s *PostService) SetBlockStorage(blockStorage model.IBlockStorage) {
+	s.blockStorage = blockStorage
+}
+
+// SetKarmaStorage enables karma accounting for post authors when their posts are voted on.
+func (s *PostService) SetKarmaStorage(karmaStorage model.IKarmaStorage) {
+	s.karmaStorage = karmaStorage
+}
+
+// SetLinkQueue makes link posts pending until a LinkValidator working the queue has checked their link.
+func (s *PostService) SetLinkQueue(linkQueue model.ILinkQueue) {
+	s.linkQueue = linkQueue
+}
+
+// SetContentFilters sets the filters new posts and comments go through, the strictest verdict wins.
+func (s *PostService) SetContentFilters(filters ...model.IContentFilter) {
+	s.contentFilters = filters
+}
+
+// SetModLogStorage enables recording the moderators' actions in the moderation log.
+func (s *PostService) SetModLogStorage(modLogStorage model.IModLogStorage) {
+	s.modLogStorage = modLogStorage
+}
+
+// SetBanChecker enables keeping banned users from posting, commenting and voting.
+func (s *PostService) SetBanChecker(banChecker model.IBanChecker) {
+	s.banChecker = banChecker
+}
+
+// SetArchiveAfter enables automatic archiving of posts older than age, zero disables it.
+func (s *PostService) SetArchiveAfter(age time.Duration) {
+	s.archiveAfter = age
+}
+
+// SetDuplicateWindow sets how long a link may not be posted again to the same category, zero disables the check.
+func (s *PostService) SetDuplicateWindow(window time.Duration) {
+	s.duplicateWindow = window
+}
+
+// SetCategoryStorage lets the moderators of a category moderate its posts and cite its rules.
+func (s *PostService) SetCategoryStorage(categoryStorage model.ICategoryStorage) {
+	s.categoryStorage = categoryStorage
+}
+
+// SetReportStorage enables closing the open reports on posts and comments when they are deleted.
+func (s *PostService) SetReportStorage(reportStorage model.IReportStorage) {
+	s.reportStorage = reportStorage
+}
+
+// SetImageService enables image posts, their images are looked up in the uploads.
+func (s *PostService) SetImageService(imageService *ImageService) {
+	s.imageService = imageService
+}
+
+// SetRestoreWindow sets how long deleted posts and comments can be restored before they are purged.
+func (s *PostService) SetRestoreWindow(window time.Duration) {
+	s.restoreWindow = window
+}
+
 func (s *PostService) GetAllPosts(ctx context.Context) ([]*model.Post, error) {
 	posts, err := s.postStorage.GetAllPosts(ctx)
 	if err != nil {
 		return nil, err
 	}
+	posts, err = s.filterVisible(ctx, posts)
+	if err != nil {
+		return nil, err
+	}
 
 	sort.Slice(posts, func(i, j int) bool {
 		return helpers.LessByVotesThenViews(posts[i], posts[j])
 	})
+	for _, post := range posts {
+		s.prepare(post)
+	}
 
 	return posts, nil
 }
 
 func (s *PostService) GetPostByID(ctx context.Context, postID string) (*model.Post, error) {
+	return s.getPost(ctx, postID, true)
+}
+
+// GetPostComments returns the post with its comments like GetPostByID, without counting a view.
+func (s *PostService) GetPostComments(ctx context.Context, postID string) (*model.Post, error) {
+	return s.getPost(ctx, postID, false)
+}
+
+func (s *PostService) getPost(ctx context.Context, postID string, view bool) (*model.Post, error) {
 	postObjectID, err := primitive.ObjectIDFromHex(postID)
 	if err != nil {
 		return nil, model.ErrInvalidPostID
@@ -45,9 +167,34 @@ func (s *PostService) GetPostByID(ctx context.Context, postID string) (*model.Po
 		return nil, err
 	}
 
-	if err := s.postStorage.AddView(ctx, post); err != nil {
+	if post.IsDeleted() {
+		return nil, model.ErrPostNotFound
+	}
+
+	viewer, _ := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+	if !post.IsLive() && !isAuthor(viewer, post.Author) {
+		return nil, model.ErrPostNotFound
+	}
+	shadowBanned, err := s.shadowBanned(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if post.Author != nil && shadowBanned[post.Author.ID] && !isAuthor(viewer, post.Author) {
+		return nil, model.ErrPostNotFound
+	}
+
+	if view {
+		if err := s.postStorage.AddView(ctx, post); err != nil {
+			return nil, err
+		}
+	}
+	hidden, err := s.hiddenAuthors(ctx)
+	if err != nil {
 		return nil, err
 	}
+	filterComments(post, hidden, viewer)
+	filterPollVotes(post, shadowBanned, viewer)
+	s.prepare(post)
 	return post, nil
 }
 
@@ -56,35 +203,189 @@ func (s *PostService) GetPostsByCategory(ctx context.Context, category string) (
 	if err != nil {
 		return nil, err
 	}
+	posts, err = s.filterVisible(ctx, posts)
+	if err != nil {
+		return nil, err
+	}
 
 	sort.Slice(posts, func(i, j int) bool {
 		return helpers.LessByVotesThenViews(posts[i], posts[j])
 	})
+	for _, post := range posts {
+		s.prepare(post)
+	}
 
 	return posts, nil
 }
 
+// GetCategoryListing lists the category's posts like GetPostsByCategory, together with the category's rules.
+func (s *PostService) GetCategoryListing(ctx context.Context, category string) (*model.CategoryListing, error) {
+	posts, err := s.GetPostsByCategory(ctx, category)
+	if err != nil {
+		return nil, err
+	}
+	listing := &model.CategoryListing{Category: category, Rules: []string{}, Posts: posts}
+	if s.categoryStorage == nil {
+		return listing, nil
+	}
+	stored, err := s.categoryStorage.GetCategory(ctx, category)
+	if err == model.ErrCategoryNotFound {
+		return listing, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	listing.Rules = stored.Rules
+	return listing, nil
+}
+
 func (s *PostService) GetPostsByUser(ctx context.Context, userName string) ([]*model.Post, error) {
 	posts, err := s.postStorage.GetPostsByUser(ctx, userName)
 	if err != nil {
 		return nil, err
 	}
+	posts, err = s.filterVisible(ctx, posts)
+	if err != nil {
+		return nil, err
+	}
 
 	sort.Slice(posts, func(i, j int) bool {
 		return helpers.LessByVotesThenViews(posts[i], posts[j])
 	})
+	for _, post := range posts {
+		s.prepare(post)
+	}
 
 	return posts, err
 }
 
+// GetCommentsByUser returns a page of the user's comments, newest first, each linking back to its post.
+func (s *PostService) GetCommentsByUser(ctx context.Context, userName string, offset int, limit int) ([]*model.UserComment, error) {
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 {
+		limit = defaultCommentsLimit
+	}
+	if limit > maxCommentsLimit {
+		limit = maxCommentsLimit
+	}
+
+	hidden, err := s.hiddenAuthors(ctx)
+	if err != nil {
+		return nil, err
+	}
+	viewer, _ := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+
+	// the storage drops what the viewer may not see before paging, so a page is never cut short
+	filter := model.UserCommentsFilter{
+		WithHeld: viewer != nil && viewer.Username == userName,
+		Offset:   offset,
+		Limit:    limit,
+	}
+	for userID := range hidden {
+		filter.ExcludeAuthors = append(filter.ExcludeAuthors, userID)
+	}
+	sort.Strings(filter.ExcludeAuthors)
+
+	comments, err := s.postStorage.GetCommentsByUser(ctx, userName, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, comment := range comments {
+		comment.HTML = helpers.RenderMarkdown(comment.Body)
+		if comment.Post != nil {
+			comment.Post.URL = postPath(comment.Post.Category, comment.Post.ID)
+		}
+	}
+	return comments, nil
+}
+
 func (s *PostService) AddPost(ctx context.Context, post *model.Post) (*model.Post, error) {
 	post.Author = ctx.Value(middleware.AuthorContextKey).(*model.Author)
 
+	if utf8.RuneCountInString(post.Text) > model.PostTextMaxLength {
+		return nil, model.ErrPostTooLong
+	}
+
+	if err := s.checkBanned(ctx, post.Author, post.Category); err != nil {
+		return nil, err
+	}
+
+	if post.Type == "poll" {
+		if err := s.preparePoll(post.Poll); err != nil {
+			return nil, err
+		}
+	} else {
+		post.Poll = nil
+	}
+
+	if post.Type == "image" {
+		if s.imageService == nil {
+			return nil, model.ErrInvalidImage
+		}
+		image, err := s.imageService.Stored(ctx, post.Image)
+		if err != nil {
+			return nil, err
+		}
+		post.Image = image
+	} else {
+		post.Image = nil
+	}
+
+	post.CanonicalUrl = ""
 	if post.Url != "" {
 		post.Url = strings.TrimSpace(post.Url)
-		if work := helpers.CheckLink(post.Url); !work {
+		canonical, err := helpers.NormalizeURL(post.Url)
+		if err != nil {
 			return nil, model.ErrInvalidUrl
 		}
+		post.CanonicalUrl = canonical
+
+		if s.duplicateWindow > 0 {
+			duplicates, err := s.findDuplicates(ctx, canonical, post.Category)
+			if err != nil {
+				return nil, err
+			}
+			if len(duplicates) > 0 {
+				return nil, &model.ErrorStack{MsgErrors: []model.ErrorMessage{{
+					Location: "body",
+					Param:    "url",
+					Value:    post.Url,
+					Msg:      "has already been posted",
+					Post:     duplicates[0],
+				}}}
+			}
+		}
+	}
+
+	verdict, err := s.filterContent(ctx, &model.Content{Author: post.Author, Title: post.Title, Text: post.Text, Url: post.Url})
+	if err != nil {
+		return nil, err
+	}
+	if verdict.Action == model.FilterReject {
+		return nil, model.ErrContentRejected
+	}
+
+	post.Status = model.PostStatusLive
+	post.Preview = nil
+	post.Filter = nil
+	if post.Url != "" {
+		if s.linkQueue != nil {
+			post.Status = model.PostStatusPending
+		} else {
+			preview, err := helpers.CheckLink(ctx, s.fetcher, post.Url)
+			if err != nil {
+				return nil, model.ErrInvalidUrl
+			}
+			post.Preview = preview
+		}
+	}
+	if verdict.Action == model.FilterHold {
+		// a queued link is checked once a moderator approves the post
+		post.Status = model.PostStatusHeld
+		post.Filter = verdict
 	}
 
 	if err := s.postStorage.AddPost(ctx, post); err != nil {
@@ -94,10 +395,203 @@ func (s *PostService) AddPost(ctx context.Context, post *model.Post) (*model.Pos
 		if err != nil {
 			return nil, err
 		}
+		switch postCreated.Status {
+		case model.PostStatusPending:
+			if err := s.enqueueLinkCheck(ctx, postCreated); err != nil {
+				// nothing would ever check the link, the post must not linger as pending
+				if err := s.postStorage.SetStatus(ctx, postCreated, model.PostStatusRejected); err != nil {
+					return nil, err
+				}
+				return nil, err
+			}
+			s.prepare(postCreated)
+		case model.PostStatusHeld:
+			s.prepare(postCreated)
+		default:
+			s.postLive(ctx, postCreated)
+		}
 		return postCreated, nil
 	}
 }
 
+// GetDuplicates returns the visible posts linking to the same page as link within the duplicate window,
+// newest first. An empty category matches every category.
+func (s *PostService) GetDuplicates(ctx context.Context, link string, category string) ([]*model.PostLink, error) {
+	canonical, err := helpers.NormalizeURL(link)
+	if err != nil {
+		return nil, err
+	}
+	return s.findDuplicates(ctx, canonical, category)
+}
+
+func (s *PostService) findDuplicates(ctx context.Context, canonical string, category string) ([]*model.PostLink, error) {
+	var since time.Time
+	if s.duplicateWindow > 0 {
+		since = s.timeController.Now().Add(-s.duplicateWindow)
+	}
+	posts, err := s.postStorage.GetPostsByURL(ctx, canonical, since)
+	if err != nil {
+		return nil, err
+	}
+	posts, err = s.filterVisible(ctx, posts)
+	if err != nil {
+		return nil, err
+	}
+
+	duplicates := make([]*model.PostLink, 0, len(posts))
+	for _, post := range posts {
+		if post.Status == model.PostStatusRejected || category != "" && post.Category != category {
+			continue
+		}
+		duplicates = append(duplicates, &model.PostLink{
+			ID:       post.ID,
+			Title:    post.Title,
+			Category: post.Category,
+			URL:      postPath(post.Category, post.ID),
+		})
+	}
+	return duplicates, nil
+}
+
+// RequeuePendingLinks queues a link check for every pending post, so that checks lost with a
+// restart, or never queued, still run. A check queued twice finds the post done the second time.
+func (s *PostService) RequeuePendingLinks(ctx context.Context) (int, error) {
+	posts, err := s.postStorage.GetAllPosts(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	for _, post := range posts {
+		if post.Status != model.PostStatusPending || post.IsDeleted() {
+			continue
+		}
+		if err := s.enqueueLinkCheck(ctx, post); err != nil {
+			return queued, err
+		}
+		queued++
+	}
+	return queued, nil
+}
+
+func (s *PostService) enqueueLinkCheck(ctx context.Context, post *model.Post) error {
+	check := &model.LinkCheck{PostID: post.ID, URL: post.Url, Due: s.timeController.Now()}
+	return s.linkQueue.Enqueue(ctx, check)
+}
+
+// filterContent runs the content filters, the strictest verdict wins and a rejection stops the chain.
+func (s *PostService) filterContent(ctx context.Context, content *model.Content) (*model.FilterVerdict, error) {
+	verdict := &model.FilterVerdict{Action: model.FilterAllow}
+	for _, filter := range s.contentFilters {
+		filterVerdict, err := filter.Check(ctx, content)
+		if err != nil {
+			return nil, err
+		}
+		if filterVerdict == nil {
+			continue
+		}
+		if filterVerdict.Action == model.FilterReject {
+			return filterVerdict, nil
+		}
+		if filterVerdict.Action == model.FilterHold && verdict.Action == model.FilterAllow {
+			verdict = filterVerdict
+		}
+	}
+	return verdict, nil
+}
+
+func (s *PostService) trainFilters(ctx context.Context, content *model.Content, spam bool) error {
+	for _, filter := range s.contentFilters {
+		if trainable, ok := filter.(model.ITrainableFilter); ok {
+			if err := trainable.Train(ctx, content, spam); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// releaseHeldPost makes a held post live, or pending when its link still has to be checked, or rejects it.
+func (s *PostService) releaseHeldPost(ctx context.Context, post *model.Post, approve bool) error {
+	if post.Status != model.PostStatusHeld {
+		return model.ErrNotHeld
+	}
+	if err := s.trainFilters(ctx, &model.Content{Author: post.Author, Title: post.Title, Text: post.Text, Url: post.Url}, !approve); err != nil {
+		return err
+	}
+
+	status := model.PostStatusRejected
+	if approve {
+		status = model.PostStatusLive
+		if post.Url != "" && s.linkQueue != nil {
+			status = model.PostStatusPending
+		}
+	}
+	if err := s.postStorage.SetStatus(ctx, post, status); err != nil {
+		return err
+	}
+	post.Status = status
+	post.Filter = nil
+
+	switch status {
+	case model.PostStatusPending:
+		return s.enqueueLinkCheck(ctx, post)
+	case model.PostStatusLive:
+		s.postLive(ctx, post)
+	}
+	return nil
+}
+
+// recordModAction appends the moderator's action on the post, or on its comment when set, to the moderation log.
+func (s *PostService) recordModAction(ctx context.Context, action string, post *model.Post, comment *model.Comment, reason string, rule *model.CitedRule) error {
+	if s.modLogStorage == nil {
+		return nil
+	}
+	moderator := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+
+	entry := model.NewModAction(action, moderator, reason)
+	entry.Category = post.Category
+	entry.Post = &model.PostLink{ID: post.ID, Title: post.Title, Category: post.Category}
+	entry.Target = post.Author
+	entry.Excerpt = helpers.Excerpt(post.Text, modLogExcerptLength)
+	entry.Rule = rule
+	if comment != nil {
+		entry.CommentID = &comment.ID
+		entry.Target = comment.Author
+		entry.Excerpt = helpers.Excerpt(comment.Body, modLogExcerptLength)
+	}
+	return s.modLogStorage.AddModAction(ctx, entry)
+}
+
+// postLive tells the notifier and the live update subscribers about a post everyone can see.
+func (s *PostService) postLive(ctx context.Context, post *model.Post) {
+	s.prepare(post)
+	if s.isShadowBanned(ctx, post.Author) {
+		return
+	}
+	if s.notifier != nil {
+		s.notifier.PostAdded(ctx, post)
+	}
+	s.publish(ctx, model.PostsTopic, model.EventPostAdded, post.ID, post.Author, post)
+}
+
+// finishLinkCheck stores the outcome of an asynchronous link check and lets the author know.
+func (s *PostService) finishLinkCheck(ctx context.Context, post *model.Post, status string, preview *model.LinkPreview) error {
+	if err := s.postStorage.SetLinkStatus(ctx, post, status, preview); err != nil {
+		return err
+	}
+	post.Status = status
+	post.Preview = preview
+
+	if s.notifier != nil {
+		s.notifier.LinkChecked(ctx, post)
+	}
+	if status == model.PostStatusLive {
+		s.postLive(ctx, post)
+	}
+	return nil
+}
+
 func (s *PostService) DeletePost(ctx context.Context, postID string) error {
 	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
 
@@ -108,19 +602,28 @@ func (s *PostService) DeletePost(ctx context.Context, postID string) error {
 
 	if post, err := s.postStorage.GetPostByID(ctx, postObjectID); err != nil {
 		return err
+	} else if post.IsDeleted() {
+		return model.ErrPostNotFound
 	} else if post.Author.ID != author.ID {
 		return model.ErrUnAuthorized
 	}
 
-	err = s.postStorage.DeletePost(ctx, postObjectID)
-	if err != nil {
+	return s.removePost(ctx, postObjectID, nil)
+}
+
+// removePost soft-deletes the post on behalf of the user in ctx, it can be restored until it is purged.
+// The open reports on the post and its comments are closed with the resolution.
+func (s *PostService) removePost(ctx context.Context, postID primitive.ObjectID, resolution *model.ReportResolution) error {
+	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+	if err := s.postStorage.DeletePost(ctx, postID, author, s.timeController.Now()); err != nil {
 		return err
 	}
-
-	return nil
+	return s.closeReports(ctx, postID, nil, resolution)
 }
 
-func (s *PostService) AddComment(ctx context.Context, postID string, body string) (*model.Post, error) {
+// RestorePost brings back a deleted post within the restore window. Authors may restore the posts
+// they deleted themselves, moderators any post.
+func (s *PostService) RestorePost(ctx context.Context, postID string) (*model.Post, error) {
 	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
 
 	postObjectID, err := primitive.ObjectIDFromHex(postID)
@@ -128,74 +631,113 @@ func (s *PostService) AddComment(ctx context.Context, postID string, body string
 		return nil, model.ErrInvalidPostID
 	}
 
-	if utf8.RuneCountInString(body) > 2000 {
-		return nil, model.ErrCommentTooLong
-	}
-
 	post, err := s.postStorage.GetPostByID(ctx, postObjectID)
 	if err != nil {
 		return nil, err
 	}
-
-	comment := model.NewComment(body, author)
-	if err := s.postStorage.AddComment(ctx, post, comment); err != nil {
+	if err := s.checkBanned(ctx, author, post.Category); err != nil {
 		return nil, err
 	}
-
-	postChanged, err := s.postStorage.GetPostByID(ctx, postObjectID)
+	moderator, err := s.checkRestore(ctx, post.Category, post.Author, post.DeletedBy, post.DeletedAt)
 	if err != nil {
 		return nil, err
 	}
 
-	return postChanged, nil
+	if err := s.postStorage.RestorePost(ctx, postObjectID); err != nil {
+		return nil, err
+	}
+	if moderator {
+		if err := s.recordModAction(ctx, model.ModActionRestorePost, post, nil, "", nil); err != nil {
+			return nil, err
+		}
+	}
 
+	return s.reloadPost(ctx, postObjectID)
 }
 
-func (s *PostService) DeleteComment(ctx context.Context, postID string, commentID string) (*model.Post, error) {
+// RestoreComment brings back a deleted comment of a post within the restore window, like RestorePost.
+func (s *PostService) RestoreComment(ctx context.Context, postID string, commentID string) (*model.Post, error) {
 	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
 
 	postObjectID, err := primitive.ObjectIDFromHex(postID)
 	if err != nil {
 		return nil, model.ErrInvalidPostID
 	}
+	commentObjectID, err := primitive.ObjectIDFromHex(commentID)
+	if err != nil {
+		return nil, model.ErrInvalidCommentID
+	}
 
 	post, err := s.postStorage.GetPostByID(ctx, postObjectID)
 	if err != nil {
 		return nil, err
 	}
-
-	commendIdx := -1
-	for idx, comment := range post.Comments {
-		if comment.ID.Hex() == commentID {
-			commendIdx = idx
-		}
+	if post.IsDeleted() {
+		return nil, model.ErrPostNotFound
 	}
-	if commendIdx == -1 {
-		return nil, model.ErrCommentNotFound
+	if err := s.checkBanned(ctx, author, post.Category); err != nil {
+		return nil, err
 	}
-
-	if post.Comments[commendIdx].Author.ID != author.ID {
-		return nil, model.ErrUnAuthorized
+	commentIdx, err := helpers.FindCommentIdx(post, commentID)
+	if err != nil {
+		return nil, err
 	}
-
-	commentObjectID, err := primitive.ObjectIDFromHex(commentID)
+	comment := post.Comments[commentIdx]
+	moderator, err := s.checkRestore(ctx, post.Category, comment.Author, comment.DeletedBy, comment.DeletedAt)
 	if err != nil {
-		return nil, model.ErrInvalidCommentID
+		return nil, err
 	}
 
-	if err = s.postStorage.DeleteComment(ctx, post, commentObjectID); err != nil {
+	if err := s.postStorage.RestoreComment(ctx, post, commentObjectID); err != nil {
 		return nil, err
 	}
+	comment.DeletedAt = nil
+	comment.DeletedBy = nil
+	if comment.IsLive() && !s.isShadowBanned(ctx, comment.Author) {
+		comment.HTML = helpers.RenderMarkdown(comment.Body)
+		s.publish(ctx, model.PostTopic(post.ID.Hex()), model.EventCommentAdded, post.ID, comment.Author, comment)
+	}
+	if moderator {
+		if err := s.recordModAction(ctx, model.ModActionRestoreComment, post, comment, "", nil); err != nil {
+			return nil, err
+		}
+	}
 
-	postChanged, err := s.postStorage.GetPostByID(ctx, post.ID)
-	if err != nil {
-		return nil, err
+	return s.reloadPost(ctx, postObjectID)
+}
+
+// checkRestore allows the user in ctx to restore content of the category deleted at deletedAt by deletedBy
+// and reports whether they do it as a moderator rather than as the author who deleted it.
+func (s *PostService) checkRestore(ctx context.Context, category string, author *model.Author, deletedBy *model.Author, deletedAt *time.Time) (bool, error) {
+	viewer := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+	if deletedAt == nil {
+		return false, model.ErrNotDeleted
 	}
 
-	return postChanged, nil
+	moderator := false
+	if !isAuthor(viewer, author) || !isAuthor(viewer, deletedBy) {
+		ok, err := s.canModerate(ctx, category)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			return false, model.ErrUnAuthorized
+		}
+		moderator = true
+	}
+
+	if s.timeController.Now().Sub(*deletedAt) > s.restoreWindow {
+		return false, model.ErrRestoreExpired
+	}
+	return moderator, nil
 }
 
-func (s *PostService) Vote(ctx context.Context, postID string, method string) (*model.Post, error) {
+// PurgeDeleted removes for good the posts and comments deleted longer than the restore window ago.
+func (s *PostService) PurgeDeleted(ctx context.Context) (int64, error) {
+	return s.postStorage.PurgeDeleted(ctx, s.timeController.Now().Add(-s.restoreWindow))
+}
+
+func (s *PostService) AddComment(ctx context.Context, postID string, body string, parentID string) (*model.Post, error) {
 	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
 
 	postObjectID, err := primitive.ObjectIDFromHex(postID)
@@ -203,9 +745,175 @@ func (s *PostService) Vote(ctx context.Context, postID string, method string) (*
 		return nil, model.ErrInvalidPostID
 	}
 
-	post, err := s.postStorage.GetPostByID(ctx, postObjectID)
-	if err != nil {
-		return nil, err
+	if utf8.RuneCountInString(body) > 2000 {
+		return nil, model.ErrCommentTooLong
+	}
+
+	post, err := s.postStorage.GetPostByID(ctx, postObjectID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkWritable(post); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkBanned(ctx, author, post.Category); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkBlocked(ctx, post.Author, author); err != nil {
+		return nil, err
+	}
+
+	verdict, err := s.filterContent(ctx, &model.Content{Author: author, Text: body})
+	if err != nil {
+		return nil, err
+	}
+	if verdict.Action == model.FilterReject {
+		return nil, model.ErrContentRejected
+	}
+
+	comment := model.NewComment(body, author)
+	if verdict.Action == model.FilterHold {
+		comment.Status = model.PostStatusHeld
+		comment.Filter = verdict
+	}
+	if parentID != "" {
+		parentIdx, err := helpers.FindCommentIdx(post, parentID)
+		if err != nil {
+			return nil, err
+		}
+		if post.Comments[parentIdx].IsDeleted() {
+			return nil, model.ErrCommentNotFound
+		}
+		if err := s.checkBlocked(ctx, post.Comments[parentIdx].Author, author); err != nil {
+			return nil, err
+		}
+		parentObjectID, err := primitive.ObjectIDFromHex(parentID)
+		if err != nil {
+			return nil, model.ErrInvalidCommentID
+		}
+		comment.ParentID = &parentObjectID
+	}
+
+	if err := s.postStorage.AddComment(ctx, post, comment); err != nil {
+		return nil, err
+	}
+	if comment.IsLive() {
+		s.commentLive(ctx, post, comment)
+	}
+
+	return s.reloadPost(ctx, postObjectID)
+
+}
+
+// commentLive tells the notifier and the live update subscribers about a comment everyone can see.
+func (s *PostService) commentLive(ctx context.Context, post *model.Post, comment *model.Comment) {
+	if s.isShadowBanned(ctx, comment.Author) {
+		return
+	}
+	if s.notifier != nil {
+		s.notifier.CommentAdded(ctx, post, comment)
+	}
+	comment.HTML = helpers.RenderMarkdown(comment.Body)
+	s.publish(ctx, model.PostTopic(post.ID.Hex()), model.EventCommentAdded, post.ID, comment.Author, comment)
+}
+
+func (s *PostService) DeleteComment(ctx context.Context, postID string, commentID string) (*model.Post, error) {
+	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+
+	postObjectID, err := primitive.ObjectIDFromHex(postID)
+	if err != nil {
+		return nil, model.ErrInvalidPostID
+	}
+
+	post, err := s.postStorage.GetPostByID(ctx, postObjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.IsDeleted() {
+		return nil, model.ErrPostNotFound
+	}
+
+	commendIdx := -1
+	for idx, comment := range post.Comments {
+		if comment.ID.Hex() == commentID && !comment.IsDeleted() {
+			commendIdx = idx
+		}
+	}
+	if commendIdx == -1 {
+		return nil, model.ErrCommentNotFound
+	}
+
+	if post.Comments[commendIdx].Author.ID != author.ID {
+		return nil, model.ErrUnAuthorized
+	}
+
+	commentObjectID, err := primitive.ObjectIDFromHex(commentID)
+	if err != nil {
+		return nil, model.ErrInvalidCommentID
+	}
+
+	if err = s.removeComment(ctx, post, commentObjectID, nil); err != nil {
+		return nil, err
+	}
+
+	return s.reloadPost(ctx, post.ID)
+}
+
+// removeComment soft-deletes the comment on behalf of the user in ctx and closes its open reports with the resolution.
+func (s *PostService) removeComment(ctx context.Context, post *model.Post, commentID primitive.ObjectID, resolution *model.ReportResolution) error {
+	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+	if err := s.postStorage.DeleteComment(ctx, post, commentID, author, s.timeController.Now()); err != nil {
+		return err
+	}
+	s.publish(ctx, model.PostTopic(post.ID.Hex()), model.EventCommentDeleted, post.ID, nil, map[string]string{
+		"id": commentID.Hex(),
+	})
+	return s.closeReports(ctx, post.ID, &commentID, resolution)
+}
+
+// closeReports closes the open reports on the comment, or on the post and all its comments when commentID is nil.
+// Content its author deleted closes them without a resolution.
+func (s *PostService) closeReports(ctx context.Context, postID primitive.ObjectID, commentID *primitive.ObjectID, resolution *model.ReportResolution) error {
+	if s.reportStorage == nil {
+		return nil
+	}
+	reports, err := s.reportStorage.GetOpenReportsByPost(ctx, postID)
+	if err != nil {
+		return err
+	}
+	target := &model.Report{PostID: postID, CommentID: commentID}
+	reportIDs := []primitive.ObjectID{}
+	for _, report := range reports {
+		if commentID == nil || report.SameTarget(target) {
+			reportIDs = append(reportIDs, report.ID)
+		}
+	}
+	if len(reportIDs) == 0 {
+		return nil
+	}
+	return s.reportStorage.CloseReports(ctx, reportIDs, resolution)
+}
+
+func (s *PostService) Vote(ctx context.Context, postID string, method string) (*model.Post, error) {
+	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+
+	postObjectID, err := primitive.ObjectIDFromHex(postID)
+	if err != nil {
+		return nil, model.ErrInvalidPostID
+	}
+
+	post, err := s.postStorage.GetPostByID(ctx, postObjectID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkWritable(post); err != nil {
+		return nil, err
+	}
+	if err := s.checkBanned(ctx, author, post.Category); err != nil {
+		return nil, err
 	}
 
 	switch method {
@@ -238,15 +946,712 @@ func (s *PostService) Vote(ctx context.Context, postID string, method string) (*
 		return nil, err
 	}
 
-	err = s.postStorage.UpdateScore(ctx, postVoted)
+	shadowBanned, err := s.shadowBanned(ctx)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.updateScore(ctx, postVoted, shadowBanned); err != nil {
+		return nil, err
+	}
+	if !shadowBanned[author.ID] {
+		delta := voteScore(postVoted.Votes, author.ID) - voteScore(post.Votes, author.ID)
+		s.updateKarma(ctx, postVoted.Author, &model.Karma{Post: delta}, author)
+	}
+
+	postChanged, err := s.reloadPost(ctx, postObjectID)
+	if err != nil {
+		return nil, err
+	}
+	s.publish(ctx, model.PostTopic(post.ID.Hex()), model.EventScoreChanged, postChanged.ID, nil, map[string]int64{
+		"score":            postChanged.Score,
+		"upvotePercentage": postChanged.UpvotePercentage,
+	})
+
+	return postChanged, nil
+}
+
+// VoteComment upvotes, downvotes or unvotes a comment of the post. Like Vote, a shadow-banned user's vote is kept
+// for them to see but does not count.
+func (s *PostService) VoteComment(ctx context.Context, postID string, commentID string, method string) (*model.Post, error) {
+	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+
+	postObjectID, err := primitive.ObjectIDFromHex(postID)
+	if err != nil {
+		return nil, model.ErrInvalidPostID
+	}
+	commentObjectID, err := primitive.ObjectIDFromHex(commentID)
+	if err != nil {
+		return nil, model.ErrInvalidCommentID
+	}
+
+	post, err := s.postStorage.GetPostByID(ctx, postObjectID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkWritable(post); err != nil {
+		return nil, err
+	}
+	if err := s.checkBanned(ctx, author, post.Category); err != nil {
+		return nil, err
+	}
+	comment := findComment(post, commentObjectID)
+	if comment == nil || comment.IsDeleted() || !comment.IsLive() {
+		return nil, model.ErrCommentNotFound
+	}
+
+	switch method {
+	case "upvote":
+		err = s.postStorage.VoteComment(ctx, post, commentObjectID, &model.Vote{UserID: author.ID, Score: 1})
+	case "downvote":
+		err = s.postStorage.VoteComment(ctx, post, commentObjectID, &model.Vote{UserID: author.ID, Score: -1})
+	case "unvote":
+		err = s.postStorage.UnVoteComment(ctx, post, commentObjectID, author.ID)
+	default:
+		return nil, model.ErrVotesActionNotImplement
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	postVoted, err := s.postStorage.GetPostByID(ctx, postObjectID)
+	if err != nil {
+		return nil, err
+	}
+	commentVoted := findComment(postVoted, commentObjectID)
+	if commentVoted == nil {
+		return nil, model.ErrCommentNotFound
+	}
+
+	shadowBanned, err := s.shadowBanned(ctx)
+	if err != nil {
+		return nil, err
+	}
+	score, err := s.updateCommentScore(ctx, postVoted, commentVoted, shadowBanned)
+	if err != nil {
+		return nil, err
+	}
+	if !shadowBanned[author.ID] {
+		delta := voteScore(commentVoted.Votes, author.ID) - voteScore(comment.Votes, author.ID)
+		s.updateKarma(ctx, commentVoted.Author, &model.Karma{Comment: delta}, author)
+	}
+	s.publish(ctx, model.PostTopic(post.ID.Hex()), model.EventCommentScoreChanged, post.ID, commentVoted.Author, map[string]interface{}{
+		"id":    commentID,
+		"score": score,
+	})
+
+	return s.reloadPost(ctx, postObjectID)
+}
+
+// findComment returns the comment of the post with the ID, nil when there is none.
+func findComment(post *model.Post, commentID primitive.ObjectID) *model.Comment {
+	for _, comment := range post.Comments {
+		if comment.ID == commentID {
+			return comment
+		}
+	}
+	return nil
+}
+
+// Moderate takes the action on the post for a moderator of its category, citing the category's rule
+// by number when it is not zero.
+func (s *PostService) Moderate(ctx context.Context, postID string, action string, reason string, rule int) (*model.Post, error) {
+	postObjectID, err := primitive.ObjectIDFromHex(postID)
+	if err != nil {
+		return nil, model.ErrInvalidPostID
+	}
+
+	post, err := s.postStorage.GetPostByID(ctx, postObjectID)
+	if err != nil {
+		return nil, err
+	}
+	if post.IsDeleted() {
+		return nil, model.ErrPostNotFound
+	}
+	if err := s.checkModerator(ctx, post.Category); err != nil {
+		return nil, err
+	}
+	cited, err := s.citeRule(ctx, post.Category, rule)
+	if err != nil {
+		return nil, err
+	}
+
+	switch action {
+	case "lock":
+		err = s.postStorage.SetLocked(ctx, post, true)
+	case "unlock":
+		err = s.postStorage.SetLocked(ctx, post, false)
+	case "archive":
+		err = s.postStorage.SetArchived(ctx, post, true)
+	case "unarchive":
+		err = s.postStorage.SetArchived(ctx, post, false)
+	case "approve", "reject":
+		err = s.releaseHeldPost(ctx, post, action == "approve")
+	default:
+		return nil, model.ErrModerateActionNotImplement
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.recordModAction(ctx, action, post, nil, reason, cited); err != nil {
+		return nil, err
+	}
 
 	postChanged, err := s.postStorage.GetPostByID(ctx, postObjectID)
 	if err != nil {
 		return nil, err
 	}
+	s.prepare(postChanged)
 
 	return postChanged, nil
 }
+
+// ModerateComment approves or rejects a comment held by the content filters, like Moderate.
+func (s *PostService) ModerateComment(ctx context.Context, postID string, commentID string, action string, reason string, rule int) (*model.Post, error) {
+	postObjectID, err := primitive.ObjectIDFromHex(postID)
+	if err != nil {
+		return nil, model.ErrInvalidPostID
+	}
+	commentObjectID, err := primitive.ObjectIDFromHex(commentID)
+	if err != nil {
+		return nil, model.ErrInvalidCommentID
+	}
+	if action != "approve" && action != "reject" {
+		return nil, model.ErrModerateActionNotImplement
+	}
+
+	post, err := s.postStorage.GetPostByID(ctx, postObjectID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkModerator(ctx, post.Category); err != nil {
+		return nil, err
+	}
+	commentIdx, err := helpers.FindCommentIdx(post, commentID)
+	if err != nil {
+		return nil, err
+	}
+	comment := post.Comments[commentIdx]
+	if post.IsDeleted() || comment.IsDeleted() {
+		return nil, model.ErrCommentNotFound
+	}
+	if comment.Status != model.PostStatusHeld {
+		return nil, model.ErrNotHeld
+	}
+	cited, err := s.citeRule(ctx, post.Category, rule)
+	if err != nil {
+		return nil, err
+	}
+
+	approve := action == "approve"
+	if err := s.trainFilters(ctx, &model.Content{Author: comment.Author, Text: comment.Body}, !approve); err != nil {
+		return nil, err
+	}
+	status := model.PostStatusRejected
+	if approve {
+		status = model.PostStatusLive
+	}
+	if err := s.postStorage.SetCommentStatus(ctx, post, commentObjectID, status); err != nil {
+		return nil, err
+	}
+	comment.Status = status
+	comment.Filter = nil
+	if approve {
+		s.commentLive(ctx, post, comment)
+	}
+
+	logAction := model.ModActionRejectComment
+	if approve {
+		logAction = model.ModActionApproveComment
+	}
+	if err := s.recordModAction(ctx, logAction, post, comment, reason, cited); err != nil {
+		return nil, err
+	}
+
+	postChanged, err := s.postStorage.GetPostByID(ctx, postObjectID)
+	if err != nil {
+		return nil, err
+	}
+	s.prepare(postChanged)
+
+	return postChanged, nil
+}
+
+// GetHeld lists the posts and comments waiting for a moderator in the categories they moderate, oldest first.
+func (s *PostService) GetHeld(ctx context.Context) (*model.HeldContent, error) {
+	all, moderated, err := s.moderatedCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !all && len(moderated) == 0 {
+		return nil, model.ErrUnAuthorized
+	}
+
+	posts, err := s.postStorage.GetAllPosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	held := &model.HeldContent{Posts: []*model.HeldPost{}, Comments: []*model.HeldComment{}}
+	for _, post := range posts {
+		if post.IsDeleted() || !all && !moderated[post.Category] {
+			continue
+		}
+		if post.Status == model.PostStatusHeld {
+			s.prepare(post)
+			held.Posts = append(held.Posts, &model.HeldPost{Post: post, Verdict: post.Filter})
+		}
+		for _, comment := range post.Comments {
+			if comment.Status != model.PostStatusHeld || comment.IsDeleted() {
+				continue
+			}
+			comment.HTML = helpers.RenderMarkdown(comment.Body)
+			held.Comments = append(held.Comments, &model.HeldComment{
+				UserComment: &model.UserComment{
+					Comment: *comment,
+					Post:    &model.PostLink{ID: post.ID, Title: post.Title, Category: post.Category, URL: postPath(post.Category, post.ID)},
+				},
+				Verdict: comment.Filter,
+			})
+		}
+	}
+	sort.Slice(held.Posts, func(i, j int) bool {
+		return held.Posts[i].ID.Timestamp().Before(held.Posts[j].ID.Timestamp())
+	})
+	sort.Slice(held.Comments, func(i, j int) bool {
+		return held.Comments[i].ID.Timestamp().Before(held.Comments[j].ID.Timestamp())
+	})
+	return held, nil
+}
+
+// TrainFilters teaches the trainable content filters that the content is spam or not, for moderators only.
+func (s *PostService) TrainFilters(ctx context.Context, content *model.Content, spam bool) error {
+	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+	if ok, err := s.moderatorStorage.IsModerator(ctx, author.Username); err != nil {
+		return err
+	} else if !ok {
+		return model.ErrUnAuthorized
+	}
+	return s.trainFilters(ctx, content, spam)
+}
+
+func (s *PostService) VotePoll(ctx context.Context, postID string, option int) (*model.Post, error) {
+	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+
+	postObjectID, err := primitive.ObjectIDFromHex(postID)
+	if err != nil {
+		return nil, model.ErrInvalidPostID
+	}
+
+	post, err := s.postStorage.GetPostByID(ctx, postObjectID)
+	if err != nil {
+		return nil, err
+	}
+	if post.Poll == nil {
+		return nil, model.ErrPostNotPoll
+	}
+	if err := s.checkWritable(post); err != nil {
+		return nil, err
+	}
+	if err := s.checkBanned(ctx, author, post.Category); err != nil {
+		return nil, err
+	}
+	if post.Poll.IsClosed(s.timeController.Now()) {
+		return nil, model.ErrPollClosed
+	}
+	if option < 0 || option >= len(post.Poll.Options) {
+		return nil, model.ErrInvalidPollOption
+	}
+
+	vote := &model.PollVote{
+		UserID: author.ID,
+		Option: option,
+	}
+	if err := s.postStorage.PollVote(ctx, post, vote); err != nil {
+		return nil, err
+	}
+
+	return s.reloadPost(ctx, postObjectID)
+}
+
+// reloadPost loads the post again after a change and prepares it for the user in ctx.
+func (s *PostService) reloadPost(ctx context.Context, postID primitive.ObjectID) (*model.Post, error) {
+	post, err := s.postStorage.GetPostByID(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	hidden, err := s.hiddenAuthors(ctx)
+	if err != nil {
+		return nil, err
+	}
+	shadowBanned, err := s.shadowBanned(ctx)
+	if err != nil {
+		return nil, err
+	}
+	viewer, _ := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+	filterComments(post, hidden, viewer)
+	filterPollVotes(post, shadowBanned, viewer)
+	s.prepare(post)
+	return post, nil
+}
+
+// publish sends a live update event, delivery is best effort and never fails the request.
+// The author is whoever wrote the content the event carries, nil when it carries none.
+func (s *PostService) publish(ctx context.Context, topic string, kind string, postID primitive.ObjectID, author *model.Author, data interface{}) {
+	if s.broker == nil {
+		return
+	}
+	event, err := model.NewEvent(kind, postID.Hex(), data)
+	if err != nil {
+		return
+	}
+	if author != nil {
+		event.AuthorID = author.ID
+	}
+	s.broker.Publish(ctx, topic, event)
+}
+
+// CanSeeEvent reports whether the user in ctx may see a live update event, the ones carrying content
+// of authors hidden from them are skipped.
+func (s *PostService) CanSeeEvent(ctx context.Context, event *model.Event) (bool, error) {
+	if event.AuthorID == "" {
+		return true, nil
+	}
+	hidden, err := s.hiddenAuthors(ctx)
+	if err != nil {
+		return false, err
+	}
+	return !hidden[event.AuthorID], nil
+}
+
+// updateScore stores the score of the post. The votes of shadow-banned users are kept, so that they still
+// see them, but do not count.
+func (s *PostService) updateScore(ctx context.Context, post *model.Post, shadowBanned map[string]bool) error {
+	counted := *post
+	counted.Votes = make([]*model.Vote, 0, len(post.Votes))
+	for _, vote := range post.Votes {
+		if !shadowBanned[vote.UserID] {
+			counted.Votes = append(counted.Votes, vote)
+		}
+	}
+	return s.postStorage.UpdateScore(ctx, &counted)
+}
+
+// updateCommentScore stores and returns the score of the comment, leaving out the votes of shadow-banned users
+// like updateScore.
+func (s *PostService) updateCommentScore(ctx context.Context, post *model.Post, comment *model.Comment, shadowBanned map[string]bool) (int64, error) {
+	counted := *comment
+	counted.Votes = make([]*model.Vote, 0, len(comment.Votes))
+	var score int64
+	for _, vote := range comment.Votes {
+		if !shadowBanned[vote.UserID] {
+			counted.Votes = append(counted.Votes, vote)
+			score += vote.Score
+		}
+	}
+	return score, s.postStorage.UpdateCommentScore(ctx, post, &counted)
+}
+
+// RecountVotes updates the scores of the posts and comments the user voted on, and the karma of their authors,
+// once the user's votes stop counting or, with counted, count again.
+func (s *PostService) RecountVotes(ctx context.Context, userID string, counted bool) error {
+	posts, err := s.postStorage.GetPostsByVoter(ctx, userID)
+	if err != nil {
+		return err
+	}
+	shadowBanned, err := s.shadowBanned(ctx)
+	if err != nil {
+		return err
+	}
+
+	sign := int64(1)
+	if !counted {
+		sign = -1
+	}
+	voter := &model.Author{ID: userID}
+	for _, post := range posts {
+		if err := s.updateScore(ctx, post, shadowBanned); err != nil {
+			return err
+		}
+		s.updateKarma(ctx, post.Author, &model.Karma{Post: sign * voteScore(post.Votes, userID)}, voter)
+	}
+
+	comments, err := s.postStorage.GetCommentsByVoter(ctx, userID)
+	if err != nil {
+		return err
+	}
+	postIDs := make([]primitive.ObjectID, 0, len(comments))
+	for _, comment := range comments {
+		postIDs = append(postIDs, comment.PostID)
+	}
+	commented, err := s.postStorage.GetPostsByIDs(ctx, postIDs)
+	if err != nil {
+		return err
+	}
+	postsByID := make(map[primitive.ObjectID]*model.Post, len(commented))
+	for _, post := range commented {
+		postsByID[post.ID] = post
+	}
+	for _, comment := range comments {
+		post, ok := postsByID[comment.PostID]
+		if !ok {
+			continue
+		}
+		if _, err := s.updateCommentScore(ctx, post, comment, shadowBanned); err != nil {
+			return err
+		}
+		s.updateKarma(ctx, comment.Author, &model.Karma{Comment: sign * voteScore(comment.Votes, userID)}, voter)
+	}
+	return nil
+}
+
+// updateKarma credits the author of a post or comment with a vote change, it is best effort and
+// drift is repaired by KarmaService.Rebuild. Votes on your own content do not count.
+func (s *PostService) updateKarma(ctx context.Context, author *model.Author, karma *model.Karma, voter *model.Author) {
+	if s.karmaStorage == nil || karma.Post == 0 && karma.Comment == 0 || author == nil || author.ID == voter.ID {
+		return
+	}
+	s.karmaStorage.AddKarma(ctx, author.ID, karma)
+}
+
+// hiddenAuthors returns the IDs of users whose content the viewer in ctx does not see:
+// the ones they blocked or muted and the shadow-banned ones other than themselves.
+func (s *PostService) hiddenAuthors(ctx context.Context) (map[string]bool, error) {
+	hidden, err := hiddenAuthors(ctx, s.blockStorage)
+	if err != nil {
+		return nil, err
+	}
+	shadowBanned, err := s.shadowBanned(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(shadowBanned) == 0 {
+		return hidden, nil
+	}
+	if hidden == nil {
+		hidden = make(map[string]bool, len(shadowBanned))
+	}
+	viewer, _ := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+	for userID := range shadowBanned {
+		if viewer == nil || viewer.ID != userID {
+			hidden[userID] = true
+		}
+	}
+	return hidden, nil
+}
+
+// shadowBanned returns the IDs of the shadow-banned users.
+func (s *PostService) shadowBanned(ctx context.Context) (map[string]bool, error) {
+	if s.banChecker == nil {
+		return nil, nil
+	}
+	return s.banChecker.ShadowBanned(ctx)
+}
+
+// isShadowBanned reports whether the author is shadow-banned, it is best effort and says no when the check fails.
+func (s *PostService) isShadowBanned(ctx context.Context, author *model.Author) bool {
+	if author == nil {
+		return false
+	}
+	shadowBanned, err := s.shadowBanned(ctx)
+	return err == nil && shadowBanned[author.ID]
+}
+
+// filterVisible drops deleted posts and posts whose link is pending or rejected unless they are the viewer's own,
+// as well as posts and comments by users the viewer has blocked or muted.
+func (s *PostService) filterVisible(ctx context.Context, posts []*model.Post) ([]*model.Post, error) {
+	hidden, err := s.hiddenAuthors(ctx)
+	if err != nil {
+		return nil, err
+	}
+	shadowBanned, err := s.shadowBanned(ctx)
+	if err != nil {
+		return nil, err
+	}
+	viewer, _ := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+
+	visible := make([]*model.Post, 0, len(posts))
+	for _, post := range posts {
+		if post.IsDeleted() || !post.IsLive() && !isAuthor(viewer, post.Author) {
+			continue
+		}
+		if post.Author != nil && hidden[post.Author.ID] {
+			continue
+		}
+		filterComments(post, hidden, viewer)
+		filterPollVotes(post, shadowBanned, viewer)
+		visible = append(visible, post)
+	}
+	return visible, nil
+}
+
+// filterComments drops deleted comments, the comments of hidden authors and the held or rejected comments
+// of anyone but the viewer.
+func filterComments(post *model.Post, hidden map[string]bool, viewer *model.Author) {
+	comments := make([]*model.Comment, 0, len(post.Comments))
+	for _, comment := range post.Comments {
+		if comment.Author != nil && hidden[comment.Author.ID] || comment.IsDeleted() {
+			continue
+		}
+		if !comment.IsLive() && !isAuthor(viewer, comment.Author) {
+			continue
+		}
+		comments = append(comments, comment)
+	}
+	post.Comments = comments
+}
+
+// filterPollVotes leaves the poll votes of shadow-banned users out of the poll and its tally, except for
+// the viewer's own vote.
+func filterPollVotes(post *model.Post, shadowBanned map[string]bool, viewer *model.Author) {
+	if post.Poll == nil || len(shadowBanned) == 0 {
+		return
+	}
+	poll := *post.Poll
+	poll.Votes = make([]*model.PollVote, 0, len(post.Poll.Votes))
+	for _, vote := range post.Poll.Votes {
+		if shadowBanned[vote.UserID] && (viewer == nil || viewer.ID != vote.UserID) {
+			continue
+		}
+		poll.Votes = append(poll.Votes, vote)
+	}
+	post.Poll = &poll
+}
+
+func isAuthor(viewer *model.Author, author *model.Author) bool {
+	return viewer != nil && author != nil && viewer.ID == author.ID
+}
+
+// canModerate reports whether the user in ctx is a site moderator or a moderator of the category.
+func (s *PostService) canModerate(ctx context.Context, category string) (bool, error) {
+	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+	return moderates(ctx, s.moderatorStorage, s.categoryStorage, author, category)
+}
+
+func (s *PostService) checkModerator(ctx context.Context, category string) error {
+	if ok, err := s.canModerate(ctx, category); err != nil {
+		return err
+	} else if !ok {
+		return model.ErrUnAuthorized
+	}
+	return nil
+}
+
+// moderatedCategories returns the categories the user in ctx moderates, or all as true for a site moderator.
+func (s *PostService) moderatedCategories(ctx context.Context) (bool, map[string]bool, error) {
+	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+	return moderatedCategories(ctx, s.moderatorStorage, s.categoryStorage, author)
+}
+
+// citeRule looks up the rule of the category a moderator acts on content for, zero cites none.
+func (s *PostService) citeRule(ctx context.Context, category string, number int) (*model.CitedRule, error) {
+	if number == 0 {
+		return nil, nil
+	}
+	if s.categoryStorage == nil {
+		return nil, model.ErrInvalidRule
+	}
+	stored, err := getCategory(ctx, s.categoryStorage, category)
+	if err != nil {
+		return nil, err
+	}
+	return stored.Rule(number)
+}
+
+// checkBanned rejects writes from users banned site-wide or from the category.
+func (s *PostService) checkBanned(ctx context.Context, author *model.Author, category string) error {
+	if s.banChecker == nil {
+		return nil
+	}
+	return s.banChecker.CheckBanned(ctx, author.ID, category)
+}
+
+// checkBlocked rejects replies from users the owner of the replied content has blocked.
+func (s *PostService) checkBlocked(ctx context.Context, owner *model.Author, author *model.Author) error {
+	if s.blockStorage == nil || owner == nil || owner.ID == author.ID {
+		return nil
+	}
+	blocked, err := s.blockStorage.IsBlocked(ctx, owner.ID, author.ID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return model.ErrBlocked
+	}
+	return nil
+}
+
+// preparePoll validates a poll submitted by a client and resets its server side state.
+func (s *PostService) preparePoll(poll *model.Poll) error {
+	if poll == nil || len(poll.Options) < model.PollMinOptions || len(poll.Options) > model.PollMaxOptions {
+		return model.ErrInvalidPoll
+	}
+	if poll.ClosesAt != nil && !poll.ClosesAt.After(s.timeController.Now()) {
+		return model.ErrInvalidPoll
+	}
+
+	for idx, option := range poll.Options {
+		option.Text = strings.TrimSpace(option.Text)
+		if option.Text == "" {
+			return model.ErrInvalidPoll
+		}
+		option.ID = idx
+	}
+	poll.Votes = []*model.PollVote{}
+
+	return nil
+}
+
+// prepare fills the computed fields of a post before it is returned to a client.
+func (s *PostService) prepare(post *model.Post) {
+	if post.Status == "" {
+		post.Status = model.PostStatusLive
+	}
+	s.markArchived(post)
+	post.HTML = helpers.RenderMarkdown(post.Text)
+	for _, comment := range post.Comments {
+		comment.HTML = helpers.RenderMarkdown(comment.Body)
+	}
+	if post.Poll != nil {
+		helpers.TallyPoll(post.Poll, s.timeController.Now())
+	}
+}
+
+// checkWritable rejects new comments and votes on deleted, pending, locked or archived posts.
+func (s *PostService) checkWritable(post *model.Post) error {
+	if post.IsDeleted() {
+		return model.ErrPostNotFound
+	}
+	if post.Status == model.PostStatusPending || post.Status == model.PostStatusHeld {
+		return model.ErrPostPending
+	}
+	if post.Status == model.PostStatusRejected {
+		return model.ErrPostNotFound
+	}
+	s.markArchived(post)
+	if post.Archived {
+		return model.ErrPostArchived
+	}
+	if post.Locked {
+		return model.ErrPostLocked
+	}
+	return nil
+}
+
+// markArchived flags the post as archived once it is older than archiveAfter, unless a moderator unarchived it.
+func (s *PostService) markArchived(post *model.Post) {
+	if s.archiveAfter <= 0 || post.Archived || post.Unarchived {
+		return
+	}
+	created, err := post.CreatedAt()
+	if err != nil {
+		return
+	}
+	if s.timeController.Now().Sub(created) > s.archiveAfter {
+		post.Archived = true
+	}
+}
+
+// postPath is where the frontend shows the post.
+func postPath(category string, postID primitive.ObjectID) string {
+	return "/a/" + category + "/" + postID.Hex()
+}
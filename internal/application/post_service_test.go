@@ -0,0 +1,384 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/application/helpers"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/middleware"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/repository/inmemory"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDuplicateLinks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+	}))
+	defer server.Close()
+
+	_, loopback, err := net.ParseCIDR("127.0.0.1/32")
+	require.NoError(t, err)
+
+	postService := NewPostService(new(FakePostStorage), inmemory.NewModeratorStorage(), model.TimeControllerFunc(time.Now))
+	postService.SetFetcher(helpers.NewFetcher(helpers.FetchPolicy{AllowedNetworks: []*net.IPNet{loopback}}))
+
+	alice := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id1", Username: "alice"})
+	bob := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id2", Username: "bob"})
+
+	original, err := postService.AddPost(alice, &model.Post{Type: "link", Title: "First", Category: "music", Url: server.URL + "/song/?utm_source=feed"})
+	require.NoError(t, err)
+
+	_, err = postService.AddPost(bob, &model.Post{Type: "link", Title: "Again", Category: "music", Url: server.URL + "/song#top"})
+	require.Equal(t, &model.ErrorStack{MsgErrors: []model.ErrorMessage{{
+		Location: "body",
+		Param:    "url",
+		Value:    server.URL + "/song#top",
+		Msg:      "has already been posted",
+		Post:     &model.PostLink{ID: original.ID, Title: "First", Category: "music", URL: "/a/music/" + original.ID.Hex()},
+	}}}, err)
+
+	_, err = postService.AddPost(bob, &model.Post{Type: "link", Title: "Elsewhere", Category: "videos", Url: server.URL + "/song"})
+	require.NoError(t, err)
+
+	duplicates, err := postService.GetDuplicates(bob, server.URL+"/song/", "")
+	require.NoError(t, err)
+	require.Len(t, duplicates, 2)
+
+	postService.SetDuplicateWindow(0)
+	_, err = postService.AddPost(bob, &model.Post{Type: "link", Title: "Again", Category: "music", Url: server.URL + "/song"})
+	require.NoError(t, err)
+}
+
+func TestPostTextLength(t *testing.T) {
+	postService := NewPostService(new(FakePostStorage), inmemory.NewModeratorStorage(), model.TimeControllerFunc(time.Now))
+	alice := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id1", Username: "alice"})
+
+	_, err := postService.AddPost(alice, &model.Post{Type: "text", Title: "Long", Category: "music", Text: strings.Repeat("ы", model.PostTextMaxLength+1)})
+	require.Equal(t, model.ErrPostTooLong, err)
+
+	post, err := postService.AddPost(alice, &model.Post{Type: "text", Title: "Fits", Category: "music", Text: strings.Repeat("ы", model.PostTextMaxLength)})
+	require.NoError(t, err)
+	require.NotEmpty(t, post.HTML)
+}
+
+func TestVotePoll(t *testing.T) {
+	timeController := &FakeTimeController{fixedTime: time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)}
+	postService := NewPostService(new(FakePostStorage), inmemory.NewModeratorStorage(), timeController)
+
+	alice := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id1", Username: "alice"})
+	bob := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id2", Username: "bob"})
+
+	closesAt := timeController.fixedTime.Add(time.Hour)
+	poll, err := postService.AddPost(alice, &model.Post{Type: "poll", Title: "Tabs or spaces?", Category: "programming", Poll: &model.Poll{
+		Options:  []*model.PollOption{{Text: "tabs"}, {Text: "spaces"}},
+		ClosesAt: &closesAt,
+	}})
+	require.NoError(t, err)
+
+	_, err = postService.VotePoll(alice, poll.ID.Hex(), 2)
+	require.Equal(t, model.ErrInvalidPollOption, err)
+
+	post, err := postService.VotePoll(alice, poll.ID.Hex(), 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), post.Poll.Total)
+
+	post, err = postService.VotePoll(bob, poll.ID.Hex(), 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), post.Poll.Options[0].Votes)
+
+	// changing a vote replaces it
+	post, err = postService.VotePoll(alice, poll.ID.Hex(), 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), post.Poll.Total)
+	require.Equal(t, int64(1), post.Poll.Options[0].Votes)
+	require.Equal(t, int64(1), post.Poll.Options[1].Votes)
+
+	timeController.fixedTime = closesAt
+	_, err = postService.VotePoll(bob, poll.ID.Hex(), 1)
+	require.Equal(t, model.ErrPollClosed, err)
+
+	post, err = postService.GetPostComments(bob, poll.ID.Hex())
+	require.NoError(t, err)
+	require.True(t, post.Poll.Closed)
+	require.Equal(t, int64(1), post.Poll.Options[0].Votes)
+}
+
+func TestVotePollShadowBanned(t *testing.T) {
+	postService := NewPostService(new(FakePostStorage), inmemory.NewModeratorStorage(), model.TimeControllerFunc(time.Now))
+	banStorage := inmemory.NewBanStorage()
+	postService.SetBanChecker(NewBanService(banStorage, nil, nil, nil, model.TimeControllerFunc(time.Now)))
+
+	aliceAuthor := &model.Author{ID: "id1", Username: "alice"}
+	alice := context.WithValue(context.Background(), middleware.AuthorContextKey, aliceAuthor)
+	bob := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id2", Username: "bob"})
+
+	poll, err := postService.AddPost(bob, &model.Post{Type: "poll", Title: "Tabs or spaces?", Category: "programming", Poll: &model.Poll{
+		Options: []*model.PollOption{{Text: "tabs"}, {Text: "spaces"}},
+	}})
+	require.NoError(t, err)
+	require.NoError(t, banStorage.AddBan(context.Background(), model.NewBan(aliceAuthor, "", true, "", nil, nil)))
+
+	// the shadow-banned voter still sees their vote counted
+	post, err := postService.VotePoll(alice, poll.ID.Hex(), 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), post.Poll.Total)
+	require.Len(t, post.Poll.Votes, 1)
+
+	// but nobody else does
+	post, err = postService.GetPostComments(bob, poll.ID.Hex())
+	require.NoError(t, err)
+	require.Equal(t, int64(0), post.Poll.Total)
+	require.Empty(t, post.Poll.Votes)
+
+	post, err = postService.VotePoll(bob, poll.ID.Hex(), 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), post.Poll.Total)
+	require.Equal(t, int64(0), post.Poll.Options[0].Votes)
+	require.Equal(t, int64(1), post.Poll.Options[1].Votes)
+
+	posts, err := postService.GetPostsByCategory(bob, "programming")
+	require.NoError(t, err)
+	require.Len(t, posts, 1)
+	require.Equal(t, int64(1), posts[0].Poll.Total)
+}
+
+func TestLockAndArchive(t *testing.T) {
+	now := time.Date(2023, 5, 10, 12, 0, 0, 0, time.UTC)
+	fresh := &model.Post{ID: primitive.NewObjectID(), Category: "news", Title: "Fresh", Type: "text", Created: now.Add(-time.Hour).Format("2006-01-02T15:04:05.000Z")}
+	old := &model.Post{ID: primitive.NewObjectID(), Category: "news", Title: "Old", Type: "text", Created: now.AddDate(0, 0, -7).Format("2006-01-02T15:04:05.000Z")}
+	postService := NewPostService(&FakePostStorage{Posts: []*model.Post{fresh, old}}, inmemory.NewModeratorStorage("mod"), model.TimeControllerFunc(func() time.Time { return now }))
+	postService.SetArchiveAfter(24 * time.Hour)
+
+	bob := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id2", Username: "bob"})
+	mod := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id3", Username: "mod"})
+
+	_, err := postService.Moderate(bob, fresh.ID.Hex(), "lock", "", 0)
+	require.Equal(t, model.ErrUnAuthorized, err)
+
+	post, err := postService.Moderate(mod, fresh.ID.Hex(), "lock", "", 0)
+	require.NoError(t, err)
+	require.True(t, post.Locked)
+	_, err = postService.Vote(bob, fresh.ID.Hex(), "upvote")
+	require.Equal(t, model.ErrPostLocked, err)
+
+	post, err = postService.Moderate(mod, fresh.ID.Hex(), "unlock", "", 0)
+	require.NoError(t, err)
+	require.False(t, post.Locked)
+	_, err = postService.Vote(bob, fresh.ID.Hex(), "upvote")
+	require.NoError(t, err)
+
+	post, err = postService.Moderate(mod, fresh.ID.Hex(), "archive", "", 0)
+	require.NoError(t, err)
+	require.True(t, post.Archived)
+	_, err = postService.Vote(bob, fresh.ID.Hex(), "downvote")
+	require.Equal(t, model.ErrPostArchived, err)
+
+	post, err = postService.Moderate(mod, fresh.ID.Hex(), "unarchive", "", 0)
+	require.NoError(t, err)
+	require.False(t, post.Archived)
+
+	// the old post is archived by age alone, until a moderator unarchives it
+	_, err = postService.Vote(bob, old.ID.Hex(), "upvote")
+	require.Equal(t, model.ErrPostArchived, err)
+	post, err = postService.Moderate(mod, old.ID.Hex(), "unarchive", "", 0)
+	require.NoError(t, err)
+	require.False(t, post.Archived)
+	_, err = postService.Vote(bob, old.ID.Hex(), "upvote")
+	require.NoError(t, err)
+
+	post, err = postService.Moderate(mod, old.ID.Hex(), "archive", "", 0)
+	require.NoError(t, err)
+	require.True(t, post.Archived)
+	_, err = postService.Vote(bob, old.ID.Hex(), "downvote")
+	require.Equal(t, model.ErrPostArchived, err)
+}
+
+func TestHeldContent(t *testing.T) {
+	holdWords, err := NewWordFilter([]string{"casino"}, model.FilterHold)
+	require.NoError(t, err)
+	rejectWords, err := NewWordFilter([]string{"scam"}, model.FilterReject)
+	require.NoError(t, err)
+	spamModelStorage := inmemory.NewSpamModelStorage()
+	bayes, err := NewBayesFilter(context.Background(), spamModelStorage)
+	require.NoError(t, err)
+
+	notifier := new(FakeNotifier)
+	postService := NewPostService(new(FakePostStorage), inmemory.NewModeratorStorage("mod"), model.TimeControllerFunc(time.Now))
+	postService.SetNotifier(notifier)
+	postService.SetContentFilters(holdWords, rejectWords, bayes)
+
+	alice := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id1", Username: "alice"})
+	bob := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id2", Username: "bob"})
+	mod := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id3", Username: "mod"})
+
+	_, err = postService.AddPost(alice, &model.Post{Type: "text", Title: "Casino scam", Category: "news", Text: "hi"})
+	require.Equal(t, model.ErrContentRejected, err)
+
+	held, err := postService.AddPost(alice, &model.Post{Type: "text", Title: "Casino", Category: "news", Text: "best casino"})
+	require.NoError(t, err)
+	require.Equal(t, model.PostStatusHeld, held.Status)
+	require.Equal(t, &model.FilterVerdict{Action: model.FilterHold, Filter: "words", Reason: "contains casino"}, held.Filter)
+	// the author must not learn which filter caught the post
+	body, err := json.Marshal(held)
+	require.NoError(t, err)
+	require.NotContains(t, string(body), `"filter"`)
+
+	_, err = postService.GetPostComments(bob, held.ID.Hex())
+	require.Equal(t, model.ErrPostNotFound, err)
+	_, err = postService.GetPostComments(alice, held.ID.Hex())
+	require.NoError(t, err)
+
+	_, err = postService.GetHeld(bob)
+	require.Equal(t, model.ErrUnAuthorized, err)
+	queue, err := postService.GetHeld(mod)
+	require.NoError(t, err)
+	require.Len(t, queue.Posts, 1)
+	body, err = json.Marshal(queue.Posts[0])
+	require.NoError(t, err)
+	require.Contains(t, string(body), `"filter":{"action":"hold","filter":"words","reason":"contains casino"}`)
+
+	post, err := postService.Moderate(mod, held.ID.Hex(), "approve", "", 0)
+	require.NoError(t, err)
+	require.Equal(t, model.PostStatusLive, post.Status)
+	require.Nil(t, post.Filter)
+	added, _ := notifier.counts()
+	require.Equal(t, 1, added)
+
+	_, err = postService.Moderate(mod, held.ID.Hex(), "approve", "", 0)
+	require.Equal(t, model.ErrNotHeld, err)
+
+	post, err = postService.AddComment(bob, held.ID.Hex(), "casino bonus", "")
+	require.NoError(t, err)
+	require.Len(t, post.Comments, 1)
+	require.Equal(t, model.PostStatusHeld, post.Comments[0].Status)
+	commentID := post.Comments[0].ID.Hex()
+
+	post, err = postService.GetPostComments(alice, held.ID.Hex())
+	require.NoError(t, err)
+	require.Empty(t, post.Comments)
+
+	queue, err = postService.GetHeld(mod)
+	require.NoError(t, err)
+	require.Empty(t, queue.Posts)
+	require.Len(t, queue.Comments, 1)
+
+	post, err = postService.ModerateComment(mod, held.ID.Hex(), commentID, "reject", "", 0)
+	require.NoError(t, err)
+	require.Equal(t, model.PostStatusRejected, post.Comments[0].Status)
+
+	spamModel, err := spamModelStorage.LoadSpamModel(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(1), spamModel.HamDocs)
+	require.Equal(t, int64(1), spamModel.SpamDocs)
+	require.Equal(t, &model.TokenCount{Spam: 1, Ham: 1}, spamModel.Tokens["casino"])
+}
+
+func TestSoftDelete(t *testing.T) {
+	clock := &FakeTimeController{time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)}
+	postStorage := new(FakePostStorage)
+	modLogStorage := inmemory.NewModLogStorage()
+	postService := NewPostService(postStorage, inmemory.NewModeratorStorage("mod"), clock)
+	postService.SetModLogStorage(modLogStorage)
+	postService.SetRestoreWindow(24 * time.Hour)
+
+	alice := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id1", Username: "alice"})
+	bob := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id2", Username: "bob"})
+	mod := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id3", Username: "mod"})
+
+	first, err := postService.AddPost(alice, &model.Post{Type: "text", Title: "First", Category: "news", Text: "hi"})
+	require.NoError(t, err)
+	second, err := postService.AddPost(alice, &model.Post{Type: "text", Title: "Second", Category: "news", Text: "hi"})
+	require.NoError(t, err)
+	second, err = postService.AddComment(bob, second.ID.Hex(), "a comment", "")
+	require.NoError(t, err)
+	commentID := second.Comments[0].ID.Hex()
+
+	_, err = postService.RestorePost(alice, first.ID.Hex())
+	require.Equal(t, model.ErrNotDeleted, err)
+
+	require.NoError(t, postService.DeletePost(alice, first.ID.Hex()))
+	posts, err := postService.GetAllPosts(bob)
+	require.NoError(t, err)
+	require.Len(t, posts, 1)
+	_, err = postService.GetPostByID(alice, first.ID.Hex())
+	require.Equal(t, model.ErrPostNotFound, err)
+	_, err = postService.AddComment(bob, first.ID.Hex(), "late", "")
+	require.Equal(t, model.ErrPostNotFound, err)
+	require.Equal(t, model.ErrPostNotFound, postService.DeletePost(alice, first.ID.Hex()))
+
+	_, err = postService.RestorePost(bob, first.ID.Hex())
+	require.Equal(t, model.ErrUnAuthorized, err)
+	post, err := postService.RestorePost(alice, first.ID.Hex())
+	require.NoError(t, err)
+	require.False(t, post.IsDeleted())
+
+	post, err = postService.DeleteComment(bob, second.ID.Hex(), commentID)
+	require.NoError(t, err)
+	require.Empty(t, post.Comments)
+	_, err = postService.AddComment(alice, second.ID.Hex(), "reply", commentID)
+	require.Equal(t, model.ErrCommentNotFound, err)
+
+	// the author may not undo a removal by a moderator, only a moderator can
+	require.NoError(t, postService.removePost(mod, first.ID, nil))
+	_, err = postService.RestorePost(alice, first.ID.Hex())
+	require.Equal(t, model.ErrUnAuthorized, err)
+
+	clock.fixedTime = clock.fixedTime.Add(time.Hour)
+	post, err = postService.RestoreComment(mod, second.ID.Hex(), commentID)
+	require.NoError(t, err)
+	require.Len(t, post.Comments, 1)
+	entries, err := modLogStorage.GetModActions(context.Background(), model.ModLogFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, model.ModActionRestoreComment, entries[0].Action)
+
+	require.NoError(t, postService.removeComment(bob, second, second.Comments[0].ID, nil))
+	clock.fixedTime = clock.fixedTime.Add(23*time.Hour + 30*time.Minute)
+	_, err = postService.RestorePost(mod, first.ID.Hex())
+	require.Equal(t, model.ErrRestoreExpired, err)
+
+	purged, err := postService.PurgeDeleted(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(1), purged)
+	_, err = postStorage.GetPostByID(context.Background(), first.ID)
+	require.Equal(t, model.ErrPostNotFound, err)
+
+	// the comment was deleted an hour later, so it waits for the next purge
+	clock.fixedTime = clock.fixedTime.Add(time.Hour)
+	purged, err = postService.PurgeDeleted(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(1), purged)
+	stored, err := postStorage.GetPostByID(context.Background(), second.ID)
+	require.NoError(t, err)
+	require.Empty(t, stored.Comments)
+}
+
+func TestGetCommentsByUser(t *testing.T) {
+	alice := &model.Author{ID: "id1", Username: "alice"}
+	post := &model.Post{ID: primitive.NewObjectID(), Title: "Hi", Category: "news"}
+	for _, status := range []string{"", model.PostStatusLive, model.PostStatusHeld} {
+		post.Comments = append(post.Comments, &model.Comment{ID: primitive.NewObjectID(), Body: status, Author: alice, Status: status})
+	}
+	postService := NewPostService(&FakePostStorage{Posts: []*model.Post{post}}, inmemory.NewModeratorStorage(), model.TimeControllerFunc(time.Now))
+
+	// the held comment is the newest one, hiding it must not cut the page short
+	bob := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id2", Username: "bob"})
+	comments, err := postService.GetCommentsByUser(bob, "alice", 0, 2)
+	require.NoError(t, err)
+	require.Len(t, comments, 2)
+	require.Equal(t, model.PostStatusLive, comments[0].Body)
+	require.Equal(t, "", comments[1].Body)
+
+	comments, err = postService.GetCommentsByUser(context.WithValue(context.Background(), middleware.AuthorContextKey, alice), "alice", 0, 2)
+	require.NoError(t, err)
+	require.Len(t, comments, 2)
+	require.Equal(t, model.PostStatusHeld, comments[0].Status)
+}
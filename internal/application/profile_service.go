@@ -0,0 +1,113 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/middleware"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+)
+
+type ProfileService struct {
+	userStorage model.IUserStorage
+	postStorage model.IPostStorage
+	banChecker  model.IBanChecker
+}
+
+func NewProfileService(userStorage model.IUserStorage, postStorage model.IPostStorage) *ProfileService {
+	return &ProfileService{
+		userStorage: userStorage,
+		postStorage: postStorage,
+	}
+}
+
+// SetBanChecker hides the post and comment counts of shadow-banned users from everyone but themselves.
+func (s *ProfileService) SetBanChecker(banChecker model.IBanChecker) {
+	s.banChecker = banChecker
+}
+
+func (s *ProfileService) GetProfile(ctx context.Context, username string) (*model.Profile, error) {
+	user, err := s.userStorage.GetUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	return s.profile(ctx, user)
+}
+
+// UpdateProfile changes the bio and avatar of the current user, the avatar must be an uploaded image or empty.
+func (s *ProfileService) UpdateProfile(ctx context.Context, bio string, avatar string) (*model.Profile, error) {
+	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+
+	bio = strings.TrimSpace(bio)
+	if utf8.RuneCountInString(bio) > model.BioMaxLength {
+		return nil, model.ErrBioTooLong
+	}
+
+	avatar = strings.TrimSpace(avatar)
+	if avatar != "" {
+		imageID := strings.TrimPrefix(avatar, UploadsPath)
+		if !imageIDPattern.MatchString(imageID) {
+			return nil, model.ErrInvalidAvatar
+		}
+		avatar = UploadsPath + imageID
+	}
+
+	user, err := s.userStorage.GetUser(ctx, author.Username)
+	if err != nil {
+		return nil, err
+	}
+	user.Bio = bio
+	user.Avatar = avatar
+	if err := s.userStorage.UpdateProfile(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return s.profile(ctx, user)
+}
+
+func (s *ProfileService) profile(ctx context.Context, user *model.User) (*model.Profile, error) {
+	hidden, err := s.isHidden(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	var posts, comments int64
+	if !hidden {
+		posts, err = s.postStorage.CountPostsByUser(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		comments, err = s.postStorage.CountCommentsByUser(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &model.Profile{
+		ID:           user.ID,
+		Username:     user.Username,
+		Bio:          user.Bio,
+		Avatar:       user.Avatar,
+		Created:      user.Created,
+		PostKarma:    user.PostKarma,
+		CommentKarma: user.CommentKarma,
+		Posts:        posts,
+		Comments:     comments,
+	}, nil
+}
+
+// isHidden reports whether the content of the user is hidden from the viewer in ctx by a shadow ban.
+func (s *ProfileService) isHidden(ctx context.Context, user *model.User) (bool, error) {
+	if s.banChecker == nil {
+		return false, nil
+	}
+	viewer, _ := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+	if viewer != nil && viewer.ID == user.ID {
+		return false, nil
+	}
+	shadowBanned, err := s.banChecker.ShadowBanned(ctx)
+	if err != nil {
+		return false, err
+	}
+	return shadowBanned[user.ID], nil
+}
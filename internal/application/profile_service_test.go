@@ -0,0 +1,51 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/middleware"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/repository/inmemory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileServiceCounts(t *testing.T) {
+	ctx := context.Background()
+
+	userStorage := inmemory.NewUserStorage()
+	alice := &model.User{Username: "alice"}
+	require.NoError(t, userStorage.AddUser(ctx, alice))
+	aliceAuthor := &model.Author{ID: alice.ID, Username: alice.Username}
+
+	postStorage := &FakePostStorage{Posts: []*model.Post{
+		{Author: aliceAuthor, Comments: []*model.Comment{
+			{Author: aliceAuthor},
+			{Author: aliceAuthor, Status: model.PostStatusHeld},
+		}},
+		{Author: aliceAuthor, Status: model.PostStatusPending},
+		{Author: aliceAuthor, Status: model.PostStatusRejected},
+	}}
+	banStorage := inmemory.NewBanStorage()
+	profileService := NewProfileService(userStorage, postStorage)
+	profileService.SetBanChecker(NewBanService(banStorage, nil, nil, nil, model.TimeControllerFunc(time.Now)))
+
+	// content awaiting or refused moderation is not counted
+	profile, err := profileService.GetProfile(ctx, "alice")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), profile.Posts)
+	require.Equal(t, int64(1), profile.Comments)
+
+	// a shadow-banned user's content is hidden from everyone else, so are its counts
+	require.NoError(t, banStorage.AddBan(ctx, model.NewBan(aliceAuthor, "", true, "", nil, nil)))
+	profile, err = profileService.GetProfile(context.WithValue(ctx, middleware.AuthorContextKey, &model.Author{ID: "id2", Username: "bob"}), "alice")
+	require.NoError(t, err)
+	require.Equal(t, int64(0), profile.Posts)
+	require.Equal(t, int64(0), profile.Comments)
+
+	profile, err = profileService.GetProfile(context.WithValue(ctx, middleware.AuthorContextKey, aliceAuthor), "alice")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), profile.Posts)
+	require.Equal(t, int64(1), profile.Comments)
+}
@@ -0,0 +1,46 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Purger periodically removes for good the posts and comments whose restore window has passed.
+type Purger struct {
+	postService *PostService
+	logger      *zap.SugaredLogger
+}
+
+func NewPurger(postService *PostService, logger *zap.SugaredLogger) *Purger {
+	return &Purger{
+		postService: postService,
+		logger:      logger,
+	}
+}
+
+// Run purges once every interval until ctx is done.
+func (p *Purger) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.purge(ctx)
+		}
+	}
+}
+
+func (p *Purger) purge(ctx context.Context) {
+	purged, err := p.postService.PurgeDeleted(ctx)
+	if err != nil {
+		p.logger.Errorw("purging deleted content failed", "error", err)
+		return
+	}
+	if purged > 0 {
+		p.logger.Infow("purged deleted content", "count", purged)
+	}
+}
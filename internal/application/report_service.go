@@ -0,0 +1,245 @@
+package application
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/application/helpers"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/middleware"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	reportExcerptLength     = 200
+	defaultReportQueueLimit = 50
+	maxReportQueueLimit     = 200
+)
+
+// ReportService takes users' reports of posts and comments and lets moderators act on them.
+type ReportService struct {
+	reportStorage model.IReportStorage
+	postService   *PostService
+}
+
+func NewReportService(reportStorage model.IReportStorage, postService *PostService) *ReportService {
+	return &ReportService{
+		reportStorage: reportStorage,
+		postService:   postService,
+	}
+}
+
+// Report flags the post, or its comment when commentID is set, once per reporter until a moderator closes it.
+func (s *ReportService) Report(ctx context.Context, postID string, commentID string, reason string) (*model.Report, error) {
+	author := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, model.ErrReasonEmpty
+	}
+	if utf8.RuneCountInString(reason) > model.ReportReasonMaxLength {
+		return nil, model.ErrReasonTooLong
+	}
+
+	post, comment, err := s.target(ctx, postID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if !post.IsLive() || comment != nil && !comment.IsLive() {
+		return nil, model.ErrPostNotFound
+	}
+
+	var commentObjectID *primitive.ObjectID
+	if comment != nil {
+		commentObjectID = &comment.ID
+	}
+	report := model.NewReport(author, post, commentObjectID, reason)
+
+	if err := s.reportStorage.AddReport(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// GetQueue lists a page of the open reports in the categories the moderator moderates, grouped by what
+// they are about, the most reported first.
+func (s *ReportService) GetQueue(ctx context.Context, offset int, limit int) ([]*model.ReportGroup, error) {
+	all, moderated, err := s.postService.moderatedCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !all && len(moderated) == 0 {
+		return nil, model.ErrUnAuthorized
+	}
+
+	filter := model.ReportQueueFilter{Offset: offset, Limit: limit}
+	if !all {
+		for category := range moderated {
+			filter.Categories = append(filter.Categories, category)
+		}
+		sort.Strings(filter.Categories)
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultReportQueueLimit
+	}
+	if filter.Limit > maxReportQueueLimit {
+		filter.Limit = maxReportQueueLimit
+	}
+
+	groups, err := s.reportStorage.GetReportQueue(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	postIDs := []primitive.ObjectID{}
+	seen := make(map[primitive.ObjectID]bool)
+	for _, group := range groups {
+		postID := group.Reports[0].PostID
+		if !seen[postID] {
+			seen[postID] = true
+			postIDs = append(postIDs, postID)
+		}
+	}
+	found, err := s.postService.postStorage.GetPostsByIDs(ctx, postIDs)
+	if err != nil {
+		return nil, err
+	}
+	posts := make(map[primitive.ObjectID]*model.Post, len(found))
+	for _, post := range found {
+		posts[post.ID] = post
+	}
+
+	queue := make([]*model.ReportGroup, 0, len(groups))
+	for _, group := range groups {
+		// targets that are gone are skipped
+		post := posts[group.Reports[0].PostID]
+		if post == nil || post.IsDeleted() {
+			continue
+		}
+
+		group.Post = &model.PostLink{ID: post.ID, Title: post.Title, Category: post.Category, URL: postPath(post.Category, post.ID)}
+		group.Author = post.Author
+		group.Excerpt = helpers.Excerpt(post.Title, reportExcerptLength)
+		if group.CommentID != nil {
+			commentIdx, err := helpers.FindCommentIdx(post, group.CommentID.Hex())
+			if err != nil || post.Comments[commentIdx].IsDeleted() {
+				continue
+			}
+			comment := post.Comments[commentIdx]
+			group.Author = comment.Author
+			group.Excerpt = helpers.Excerpt(comment.Body, reportExcerptLength)
+		}
+		queue = append(queue, group)
+	}
+	return queue, nil
+}
+
+// Resolve closes the open reports on the post, or on its comment when commentID is set, dismissing them,
+// removing the content or warning its author. Removing a post closes the reports on its comments too.
+// A rule number other than zero cites the category's rule the content broke.
+func (s *ReportService) Resolve(ctx context.Context, postID string, commentID string, action string, note string, rule int) ([]*model.Report, error) {
+	moderator := ctx.Value(middleware.AuthorContextKey).(*model.Author)
+	if action != model.ReportDismiss && action != model.ReportRemove && action != model.ReportWarn {
+		return nil, model.ErrModerateActionNotImplement
+	}
+
+	post, comment, err := s.target(ctx, postID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.postService.checkModerator(ctx, post.Category); err != nil {
+		return nil, err
+	}
+	cited, err := s.postService.citeRule(ctx, post.Category, rule)
+	if err != nil {
+		return nil, err
+	}
+
+	reports, err := s.reportStorage.GetOpenReportsByPost(ctx, post.ID)
+	if err != nil {
+		return nil, err
+	}
+	target := &model.Report{PostID: post.ID}
+	if comment != nil {
+		target.CommentID = &comment.ID
+	}
+	closing := []*model.Report{}
+	reportIDs := []primitive.ObjectID{}
+	for _, report := range reports {
+		if report.SameTarget(target) || action == model.ReportRemove && comment == nil && report.PostID == post.ID {
+			closing = append(closing, report)
+			reportIDs = append(reportIDs, report.ID)
+		}
+	}
+	if len(closing) == 0 {
+		return nil, model.ErrReportNotFound
+	}
+
+	resolution := model.NewReportResolution(action, note, moderator)
+	logAction := model.ModActionDismiss
+	switch action {
+	case model.ReportRemove:
+		if comment != nil {
+			logAction = model.ModActionRemoveComment
+			err = s.postService.removeComment(ctx, post, comment.ID, resolution)
+		} else {
+			logAction = model.ModActionRemovePost
+			err = s.postService.removePost(ctx, post.ID, resolution)
+		}
+	case model.ReportWarn:
+		logAction = model.ModActionWarn
+		if s.postService.notifier != nil {
+			s.postService.notifier.Warned(ctx, post, comment, note)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.postService.recordModAction(ctx, logAction, post, comment, note, cited); err != nil {
+		return nil, err
+	}
+
+	// a removal has closed them already when the post service keeps reports, closing is a no-op then
+	if err := s.reportStorage.CloseReports(ctx, reportIDs, resolution); err != nil {
+		return nil, err
+	}
+	for _, report := range closing {
+		report.Status = model.ReportClosed
+		report.Resolution = resolution
+	}
+	return closing, nil
+}
+
+// target loads the post and, when commentID is set, its comment.
+func (s *ReportService) target(ctx context.Context, postID string, commentID string) (*model.Post, *model.Comment, error) {
+	postObjectID, err := primitive.ObjectIDFromHex(postID)
+	if err != nil {
+		return nil, nil, model.ErrInvalidPostID
+	}
+	post, err := s.postService.postStorage.GetPostByID(ctx, postObjectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if post.IsDeleted() {
+		return nil, nil, model.ErrPostNotFound
+	}
+	if commentID == "" {
+		return post, nil, nil
+	}
+	if _, err := primitive.ObjectIDFromHex(commentID); err != nil {
+		return nil, nil, model.ErrInvalidCommentID
+	}
+	commentIdx, err := helpers.FindCommentIdx(post, commentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if post.Comments[commentIdx].IsDeleted() {
+		return nil, nil, model.ErrCommentNotFound
+	}
+	return post, post.Comments[commentIdx], nil
+}
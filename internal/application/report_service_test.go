@@ -0,0 +1,122 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/middleware"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/repository/inmemory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReports(t *testing.T) {
+	notifier := new(FakeNotifier)
+	postStorage := new(FakePostStorage)
+	postService := NewPostService(postStorage, inmemory.NewModeratorStorage("mod"), model.TimeControllerFunc(time.Now))
+	postService.SetNotifier(notifier)
+	reportStorage := inmemory.NewReportStorage()
+	reportService := NewReportService(reportStorage, postService)
+	postService.SetReportStorage(reportStorage)
+
+	alice := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id1", Username: "alice"})
+	bob := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id2", Username: "bob"})
+	carol := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id4", Username: "carol"})
+	mod := context.WithValue(context.Background(), middleware.AuthorContextKey, &model.Author{ID: "id3", Username: "mod"})
+
+	first, err := postService.AddPost(alice, &model.Post{Type: "text", Title: "First", Category: "news", Text: "hi"})
+	require.NoError(t, err)
+	second, err := postService.AddPost(alice, &model.Post{Type: "text", Title: "Second", Category: "news", Text: "hi"})
+	require.NoError(t, err)
+	second, err = postService.AddComment(alice, second.ID.Hex(), "rude comment", "")
+	require.NoError(t, err)
+	commentID := second.Comments[0].ID.Hex()
+
+	_, err = reportService.Report(bob, first.ID.Hex(), "", "  ")
+	require.Equal(t, model.ErrReasonEmpty, err)
+	_, err = reportService.Report(bob, first.ID.Hex(), "", strings.Repeat("a", model.ReportReasonMaxLength+1))
+	require.Equal(t, model.ErrReasonTooLong, err)
+
+	report, err := reportService.Report(bob, first.ID.Hex(), "", "spam")
+	require.NoError(t, err)
+	require.Equal(t, model.ReportOpen, report.Status)
+	_, err = reportService.Report(bob, first.ID.Hex(), "", "spam again")
+	require.Equal(t, model.ErrAlreadyReported, err)
+
+	_, err = reportService.Report(bob, second.ID.Hex(), commentID, "rude")
+	require.NoError(t, err)
+	_, err = reportService.Report(carol, second.ID.Hex(), commentID, "insulting")
+	require.NoError(t, err)
+
+	_, err = reportService.GetQueue(bob, 0, 0)
+	require.Equal(t, model.ErrUnAuthorized, err)
+	queue, err := reportService.GetQueue(mod, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, queue, 2)
+	require.Equal(t, 2, queue[0].Count)
+	require.Equal(t, "rude comment", queue[0].Excerpt)
+	require.Equal(t, second.Comments[0].ID, *queue[0].CommentID)
+	require.Equal(t, 1, queue[1].Count)
+	require.Equal(t, "First", queue[1].Excerpt)
+	require.Equal(t, "news", queue[1].Reports[0].Category)
+
+	queue, err = reportService.GetQueue(mod, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, queue, 1)
+	require.Equal(t, first.ID, queue[0].Post.ID)
+	queue, err = reportService.GetQueue(mod, 2, 1)
+	require.NoError(t, err)
+	require.Empty(t, queue)
+
+	_, err = reportService.Resolve(mod, first.ID.Hex(), "", "ban", "", 0)
+	require.Equal(t, model.ErrModerateActionNotImplement, err)
+	_, err = reportService.Resolve(bob, first.ID.Hex(), "", model.ReportDismiss, "", 0)
+	require.Equal(t, model.ErrUnAuthorized, err)
+
+	closed, err := reportService.Resolve(mod, second.ID.Hex(), commentID, model.ReportWarn, "be nice", 0)
+	require.NoError(t, err)
+	require.Len(t, closed, 2)
+	require.Equal(t, model.ReportClosed, closed[0].Status)
+	require.Equal(t, "be nice", closed[0].Resolution.Note)
+	require.Equal(t, []string{"be nice"}, notifier.warned)
+
+	_, err = reportService.Resolve(mod, second.ID.Hex(), commentID, model.ReportRemove, "", 0)
+	require.Equal(t, model.ErrReportNotFound, err)
+
+	// a post removal closes the reports on its comments as well
+	_, err = reportService.Report(bob, second.ID.Hex(), commentID, "still rude")
+	require.NoError(t, err)
+	closed, err = reportService.Resolve(mod, first.ID.Hex(), "", model.ReportRemove, "", 0)
+	require.NoError(t, err)
+	require.Len(t, closed, 1)
+	removed, err := postStorage.GetPostByID(context.Background(), first.ID)
+	require.NoError(t, err)
+	require.True(t, removed.IsDeleted())
+	require.Equal(t, "mod", removed.DeletedBy.Username)
+	_, err = postService.GetPostByID(alice, first.ID.Hex())
+	require.Equal(t, model.ErrPostNotFound, err)
+
+	closed, err = reportService.Resolve(mod, second.ID.Hex(), "", model.ReportRemove, "", 0)
+	require.NoError(t, err)
+	require.Len(t, closed, 1)
+
+	queue, err = reportService.GetQueue(mod, 0, 0)
+	require.NoError(t, err)
+	require.Empty(t, queue)
+
+	// deleting the content closes its reports, so it can be reported again once restored
+	third, err := postService.AddPost(alice, &model.Post{Type: "text", Title: "Third", Category: "news", Text: "hi"})
+	require.NoError(t, err)
+	_, err = reportService.Report(bob, third.ID.Hex(), "", "spam")
+	require.NoError(t, err)
+	require.NoError(t, postService.DeletePost(alice, third.ID.Hex()))
+	open, err := reportStorage.GetOpenReportsByPost(context.Background(), third.ID)
+	require.NoError(t, err)
+	require.Empty(t, open)
+	_, err = postService.RestorePost(alice, third.ID.Hex())
+	require.NoError(t, err)
+	_, err = reportService.Report(bob, third.ID.Hex(), "", "spam")
+	require.NoError(t, err)
+}
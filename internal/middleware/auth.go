@@ -2,7 +2,9 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
 	"github.com/gorilla/mux"
@@ -14,7 +16,9 @@ const (
 	AuthorContextKey AuthContextKey = "author"
 )
 
-func Auth(jwtService model.IJWTService, tokenStorage model.ITokenStorage) mux.MiddlewareFunc {
+// Auth lets through requests with a valid session token. When banChecker is set,
+// site-wide banned users get a 403 with the ban, even if their token was revoked.
+func Auth(jwtService model.IJWTService, tokenStorage model.ITokenStorage, banChecker model.IBanChecker) mux.MiddlewareFunc {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -23,15 +27,27 @@ func Auth(jwtService model.IJWTService, tokenStorage model.ITokenStorage) mux.Mi
 				return
 			}
 
-			token := authHeader[len("Bearer "):]
+			token := strings.TrimPrefix(authHeader, "Bearer ")
 			author, err := jwtService.VerifyToken(token)
 			if err != nil {
 				http.Error(w, model.ErrUnAuthorizedHTTP.Error(), http.StatusUnauthorized)
 				return
 			}
 
-			dbtoken, err := tokenStorage.GetToken(r.Context(), author.ID)
-			if err != nil || dbtoken != token {
+			if banChecker != nil {
+				err := banChecker.CheckBanned(r.Context(), author.ID, "")
+				var banErr *model.BanError
+				if errors.As(err, &banErr) {
+					http.Error(w, banErr.Error(), http.StatusForbidden)
+					return
+				}
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+			}
+
+			if err := checkSession(r.Context(), tokenStorage, author, token); err != nil {
 				http.Error(w, model.ErrUnAuthorizedHTTP.Error(), http.StatusUnauthorized)
 				return
 			}
@@ -41,3 +57,48 @@ func Auth(jwtService model.IJWTService, tokenStorage model.ITokenStorage) mux.Mi
 		})
 	}
 }
+
+// OptionalAuth adds the author to the context when the request carries a valid token
+// and lets anonymous requests through unchanged.
+func OptionalAuth(jwtService model.IJWTService, tokenStorage model.ITokenStorage) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if token == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			author, err := Authenticate(r.Context(), jwtService, tokenStorage, token)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), AuthorContextKey, author)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Authenticate verifies a JWT and checks that it is still the active session token of its user.
+func Authenticate(ctx context.Context, jwtService model.IJWTService, tokenStorage model.ITokenStorage, token string) (*model.Author, error) {
+	author, err := jwtService.VerifyToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkSession(ctx, tokenStorage, author, token); err != nil {
+		return nil, err
+	}
+	return author, nil
+}
+
+// checkSession checks that the token is still the active session token of its user.
+func checkSession(ctx context.Context, tokenStorage model.ITokenStorage, author *model.Author, token string) error {
+	dbtoken, err := tokenStorage.GetToken(ctx, author.ID)
+	if err != nil || dbtoken != token {
+		return model.ErrUnAuthorized
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Ban keeps a user from writing, everywhere when Category is empty, in that category otherwise.
+// A shadow ban is site-wide and lets the user write, but nobody else sees it and their votes do not count.
+type Ban struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	User      *Author            `json:"user" bson:"user"`
+	Category  string             `json:"category,omitempty" bson:"category"`
+	Shadow    bool               `json:"shadow,omitempty" bson:"shadow"`
+	Reason    string             `json:"reason,omitempty" bson:"reason,omitempty"`
+	Moderator *Author            `json:"moderator" bson:"moderator"`
+	Created   string             `json:"created" bson:"created"`
+	// Expires is when the ban is lifted, a ban without it is permanent.
+	Expires *time.Time `json:"expires,omitempty" bson:"expires,omitempty"`
+}
+
+func NewBan(user *Author, category string, shadow bool, reason string, moderator *Author, expires *time.Time) *Ban {
+	return &Ban{
+		User:      user,
+		Category:  category,
+		Shadow:    shadow,
+		Reason:    reason,
+		Moderator: moderator,
+		Created:   time.Now().UTC().Format(layout),
+		Expires:   expires,
+	}
+}
+
+func (b *Ban) IsSiteWide() bool {
+	return b.Category == ""
+}
+
+func (b *Ban) IsActive(now time.Time) bool {
+	return b.Expires == nil || now.Before(*b.Expires)
+}
+
+// BanError is returned when a banned user tries to do what the ban forbids, its message is the JSON sent to the client.
+type BanError struct {
+	Ban *Ban
+}
+
+func (e *BanError) Error() string {
+	message := "you are banned"
+	if !e.Ban.IsSiteWide() {
+		message = "you are banned from " + e.Ban.Category
+	}
+	httpErr, err := json.Marshal(struct {
+		Message  string     `json:"message"`
+		Category string     `json:"category,omitempty"`
+		Reason   string     `json:"reason,omitempty"`
+		Expires  *time.Time `json:"expires,omitempty"`
+	}{message, e.Ban.Category, e.Ban.Reason, e.Ban.Expires})
+	if err != nil {
+		return err.Error()
+	}
+	return string(httpErr)
+}
+
+type IBanStorage interface {
+	// AddBan replaces the user's ban of the same scope and kind, if any.
+	AddBan(context.Context, *Ban) error
+	RemoveBan(ctx context.Context, userID string, category string, shadow bool) error
+	GetBans(context.Context) ([]*Ban, error)
+	GetUserBans(ctx context.Context, userID string) ([]*Ban, error)
+	GetShadowBans(context.Context) ([]*Ban, error)
+}
+
+// IVoteRecounter recounts the posts a user voted on once their votes stop or start counting.
+type IVoteRecounter interface {
+	RecountVotes(ctx context.Context, userID string, counted bool) error
+}
+
+type IBanChecker interface {
+	// CheckBanned returns a *BanError when the user is banned site-wide, or from the category when it is set.
+	// Shadow bans never make it fail.
+	CheckBanned(ctx context.Context, userID string, category string) error
+	// ShadowBanned returns the IDs of the shadow-banned users.
+	ShadowBanned(context.Context) (map[string]bool, error)
+}
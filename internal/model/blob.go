@@ -0,0 +1,12 @@
+package model
+
+import (
+	"context"
+	"io"
+)
+
+type IBlobStorage interface {
+	Put(context.Context, string, io.Reader) error
+	Get(context.Context, string) (io.ReadCloser, error)
+	Delete(context.Context, string) error
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// BlockBlock hides the target's content and stops them replying to or messaging the user.
+	BlockBlock = "block"
+	// BlockMute only hides the target's content.
+	BlockMute = "mute"
+)
+
+type Block struct {
+	UserID  string  `json:"-" bson:"user"`
+	Target  *Author `json:"user" bson:"target"`
+	Type    string  `json:"type" bson:"type"`
+	Created string  `json:"created" bson:"created"`
+}
+
+func NewBlock(userID string, target *Author, kind string) *Block {
+	return &Block{
+		UserID:  userID,
+		Target:  target,
+		Type:    kind,
+		Created: time.Now().UTC().Format(layout),
+	}
+}
+
+type IBlockStorage interface {
+	AddBlock(context.Context, *Block) error
+	RemoveBlock(context.Context, string, string) error
+	GetBlocks(context.Context, string) ([]*Block, error)
+	IsBlocked(context.Context, string, string) (bool, error)
+}
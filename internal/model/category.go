@@ -0,0 +1,64 @@
+package model
+
+import "context"
+
+const (
+	CategoryMaxRules      = 20
+	CategoryRuleMaxLength = 500
+)
+
+// Category is a community posts are submitted to, moderated by its own moderators next to the site-wide ones.
+type Category struct {
+	Name       string    `json:"name" bson:"_id"`
+	Rules      []string  `json:"rules" bson:"rules"`
+	Moderators []*Author `json:"moderators" bson:"moderators"`
+}
+
+// NewCategory returns a category without rules or moderators, which is what every category is until it is set up.
+func NewCategory(name string) *Category {
+	return &Category{
+		Name:       name,
+		Rules:      []string{},
+		Moderators: []*Author{},
+	}
+}
+
+// IsModerator reports whether the user is one of the category's own moderators.
+func (c *Category) IsModerator(userID string) bool {
+	for _, moderator := range c.Moderators {
+		if moderator.ID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// Rule cites the rule by its number, counted from one as the rules are shown.
+func (c *Category) Rule(number int) (*CitedRule, error) {
+	if number < 1 || number > len(c.Rules) {
+		return nil, ErrInvalidRule
+	}
+	return &CitedRule{Number: number, Text: c.Rules[number-1]}, nil
+}
+
+// CitedRule is the rule of a category that a moderator acted on content for, as it read at the time.
+type CitedRule struct {
+	Number int    `json:"number" bson:"number"`
+	Text   string `json:"text" bson:"text"`
+}
+
+// CategoryListing is the posts of a category listed together with its rules.
+type CategoryListing struct {
+	Category string   `json:"category"`
+	Rules    []string `json:"rules"`
+	Posts    []*Post  `json:"posts"`
+}
+
+type ICategoryStorage interface {
+	// GetCategories returns the categories that were set up, by name.
+	GetCategories(context.Context) ([]*Category, error)
+	GetCategory(context.Context, string) (*Category, error)
+	SetRules(context.Context, string, []string) error
+	AddModerator(context.Context, string, *Author) error
+	RemoveModerator(context.Context, string, string) error
+}
@@ -9,8 +9,54 @@ import (
 type Comment struct {
 	ID      primitive.ObjectID `json:"id" bson:"_id"`
 	Body    string             `json:"body" bson:"body"`
+	HTML    string             `json:"html" bson:"-"`
 	Created string             `json:"created" bson:"created"`
 	Author  *Author            `json:"author" bson:"author"`
+	PostID  primitive.ObjectID `json:"postId" bson:"post"`
+
+	ParentID *primitive.ObjectID `json:"parent,omitempty" bson:"parent,omitempty"`
+
+	Score int64   `json:"score" bson:"score"`
+	Votes []*Vote `json:"votes" bson:"votes"`
+
+	Status string         `json:"status,omitempty" bson:"status,omitempty"`
+	Filter *FilterVerdict `json:"-" bson:"filter,omitempty"`
+
+	DeletedAt *time.Time `json:"deletedAt,omitempty" bson:"deletedat,omitempty"`
+	DeletedBy *Author    `json:"deletedBy,omitempty" bson:"deletedby,omitempty"`
+}
+
+// IsLive reports whether the comment is visible to everyone.
+func (c *Comment) IsLive() bool {
+	return c.Status == "" || c.Status == PostStatusLive
+}
+
+// IsDeleted reports whether the comment was soft-deleted.
+func (c *Comment) IsDeleted() bool {
+	return c.DeletedAt != nil
+}
+
+// PostLink points a comment listed outside of its thread back to the post.
+type PostLink struct {
+	ID       primitive.ObjectID `json:"id" bson:"_id"`
+	Title    string             `json:"title" bson:"title"`
+	Category string             `json:"category" bson:"category"`
+	URL      string             `json:"url" bson:"-"`
+}
+
+type UserComment struct {
+	Comment `bson:",inline"`
+	Post    *PostLink `json:"post" bson:"postlink"`
+}
+
+// UserCommentsFilter pages through the comments of a user, the comments it drops do not count toward the page.
+type UserCommentsFilter struct {
+	// ExcludeAuthors drops the comments of these users.
+	ExcludeAuthors []string
+	// WithHeld keeps the comments that are not live, for the author looking at their own comments.
+	WithHeld bool
+	Offset   int
+	Limit    int
 }
 
 const layout = "2006-01-02T15:04:05.000Z"
@@ -20,5 +66,11 @@ func NewComment(body string, author *Author) *Comment {
 		Body:    body,
 		Created: time.Now().UTC().Format(layout),
 		Author:  author,
+		Votes:   []*Vote{},
 	}
 }
+
+// CreatedAt parses the Created field, which is stored as a formatted string.
+func (c *Comment) CreatedAt() (time.Time, error) {
+	return time.Parse(layout, c.Created)
+}
@@ -0,0 +1,81 @@
+package model
+
+import "context"
+
+const (
+	FilterAllow  = "allow"
+	FilterHold   = "hold"
+	FilterReject = "reject"
+)
+
+// Content is what a content filter inspects, for comments only the text is set.
+type Content struct {
+	Author *Author
+	Title  string
+	Text   string
+	Url    string
+}
+
+// FilterVerdict tells what to do with content and which filter decided it.
+type FilterVerdict struct {
+	Action string `json:"action" bson:"action"`
+	Filter string `json:"filter" bson:"filter"`
+	Reason string `json:"reason" bson:"reason"`
+}
+
+type IContentFilter interface {
+	// Check returns nil when the filter has nothing against the content.
+	Check(context.Context, *Content) (*FilterVerdict, error)
+}
+
+// ITrainableFilter learns from the moderators' decisions on held content.
+type ITrainableFilter interface {
+	IContentFilter
+	Train(context.Context, *Content, bool) error
+}
+
+// SpamModel holds the counts of a naive Bayes classifier.
+type SpamModel struct {
+	SpamDocs int64                  `json:"spamDocs"`
+	HamDocs  int64                  `json:"hamDocs"`
+	Tokens   map[string]*TokenCount `json:"tokens"`
+}
+
+// Copy returns a copy of the model that shares no counts with it.
+func (m *SpamModel) Copy() *SpamModel {
+	copied := &SpamModel{SpamDocs: m.SpamDocs, HamDocs: m.HamDocs, Tokens: make(map[string]*TokenCount, len(m.Tokens))}
+	for token, count := range m.Tokens {
+		counted := *count
+		copied.Tokens[token] = &counted
+	}
+	return copied
+}
+
+type TokenCount struct {
+	Spam int64 `json:"spam"`
+	Ham  int64 `json:"ham"`
+}
+
+type ISpamModelStorage interface {
+	// LoadSpamModel returns an empty model when none was saved yet.
+	LoadSpamModel(context.Context) (*SpamModel, error)
+	SaveSpamModel(context.Context, *SpamModel) error
+}
+
+// HeldContent is what waits for a moderator to approve or reject it.
+type HeldContent struct {
+	Posts    []*HeldPost    `json:"posts"`
+	Comments []*HeldComment `json:"comments"`
+}
+
+// HeldPost is a held post with the verdict that held it, which is kept from everyone but the moderators.
+type HeldPost struct {
+	*Post
+	Verdict *FilterVerdict `json:"filter,omitempty"`
+}
+
+// HeldComment is a held comment with the verdict that held it, like HeldPost.
+type HeldComment struct {
+	*UserComment
+	Verdict *FilterVerdict `json:"filter,omitempty"`
+}
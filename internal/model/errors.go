@@ -9,11 +9,44 @@ import (
 var (
 	ErrPostNotFoundHTTP = errors.New(`{"message": "post not found"}`)
 
-	ErrPostInvalidHTTP         = errors.New(`{"message":"invalid post id"}`)
-	ErrPostCategoryInvalidHTTP = errors.New(`{"message":"invalid post category"}`)
-	ErrCommentInvalidHTTP      = errors.New(`{"message":"invalid comment id"}`)
-	ErrUserInvalidHTTP         = errors.New(`{"message":"invalid user name"}`)
-	ErrInvalidCredentialsHTTP  = errors.New(`{"message":"invalid username or password"}`)
+	ErrPostInvalidHTTP          = errors.New(`{"message":"invalid post id"}`)
+	ErrPostCategoryInvalidHTTP  = errors.New(`{"message":"invalid post category"}`)
+	ErrCommentInvalidHTTP       = errors.New(`{"message":"invalid comment id"}`)
+	ErrUserInvalidHTTP          = errors.New(`{"message":"invalid user name"}`)
+	ErrInvalidCredentialsHTTP   = errors.New(`{"message":"invalid username or password"}`)
+	ErrPostLockedHTTP           = errors.New(`{"message":"post is locked"}`)
+	ErrPostArchivedHTTP         = errors.New(`{"message":"post is archived"}`)
+	ErrPostPendingHTTP          = errors.New(`{"message":"post is not published yet"}`)
+	ErrModerateActionHTTP       = errors.New(`{"message":"unknown moderate action"}`)
+	ErrPostNotPollHTTP          = errors.New(`{"message":"post is not a poll"}`)
+	ErrPollClosedHTTP           = errors.New(`{"message":"poll is closed"}`)
+	ErrPollOptionInvalidHTTP    = errors.New(`{"message":"invalid poll option"}`)
+	ErrImageTooLargeHTTP        = errors.New(`{"message":"image is too large"}`)
+	ErrImageUnsupportedHTTP     = errors.New(`{"message":"unsupported image type"}`)
+	ErrImageInvalidHTTP         = errors.New(`{"message":"invalid image"}`)
+	ErrNotificationInvalidHTTP  = errors.New(`{"message":"invalid notification id"}`)
+	ErrConversationInvalidHTTP  = errors.New(`{"message":"invalid conversation id"}`)
+	ErrConversationNotFoundHTTP = errors.New(`{"message":"conversation not found"}`)
+	ErrRecipientInvalidHTTP     = errors.New(`{"message":"invalid recipient"}`)
+	ErrBlockedHTTP              = errors.New(`{"message":"you have been blocked by this user"}`)
+	ErrBlockTypeInvalidHTTP     = errors.New(`{"message":"invalid block type"}`)
+	ErrBlockTargetInvalidHTTP   = errors.New(`{"message":"invalid user to block"}`)
+	ErrUserNotFoundHTTP         = errors.New(`{"message":"user not found"}`)
+	ErrAvatarInvalidHTTP        = errors.New(`{"message":"avatar must be an uploaded image"}`)
+	ErrContentRejectedHTTP      = errors.New(`{"message":"content was rejected as spam"}`)
+	ErrNotHeldHTTP              = errors.New(`{"message":"content is not held for moderation"}`)
+	ErrAlreadyReportedHTTP      = errors.New(`{"message":"you have already reported this"}`)
+	ErrReportNotFoundHTTP       = errors.New(`{"message":"no open reports"}`)
+	ErrBanNotFoundHTTP          = errors.New(`{"message":"user is not banned"}`)
+	ErrBanDurationInvalidHTTP   = errors.New(`{"message":"invalid ban duration"}`)
+	ErrBanTargetInvalidHTTP     = errors.New(`{"message":"invalid user to ban"}`)
+	ErrNotDeletedHTTP           = errors.New(`{"message":"content is not deleted"}`)
+	ErrRestoreExpiredHTTP       = errors.New(`{"message":"content can no longer be restored"}`)
+	ErrRuleInvalidHTTP          = errors.New(`{"message":"the category has no such rule"}`)
+	ErrRulesInvalidHTTP         = errors.New(`{"message":"invalid category rules"}`)
+	ErrModeratorNotFoundHTTP    = errors.New(`{"message":"user is not a moderator of the category"}`)
+	ErrModTargetInvalidHTTP     = errors.New(`{"message":"invalid user to make a moderator"}`)
+	ErrPageInvalidHTTP          = errors.New(`{"message":"offset and limit must be non-negative integers"}`)
 
 	// HTTPErrNullComment = errors.New(`{"errors":[{"location":"body","param":"comment","msg":"is required"}]}`)
 
@@ -30,6 +63,15 @@ type ErrorStack struct {
 	MsgErrors []ErrorMessage `json:"errors"`
 }
 
+// Error returns the stack as the JSON sent to the client, so that services can return it as is.
+func (e *ErrorStack) Error() string {
+	httpErr, err := json.Marshal(e)
+	if err != nil {
+		return err.Error()
+	}
+	return string(httpErr)
+}
+
 func NewErrorStack(location string, param string, value string, msg string) (string, error) {
 	msgErr := ErrorStack{
 		MsgErrors: []ErrorMessage{
@@ -53,6 +95,8 @@ type ErrorMessage struct {
 	Param    string `json:"param"`
 	Value    string `json:"value,omitempty"`
 	Msg      string `json:"msg"`
+	// Post is the existing post the value conflicts with.
+	Post *PostLink `json:"post,omitempty"`
 }
 
 var (
@@ -61,18 +105,82 @@ var (
 	ErrCommentNotFound = errors.New("comment doesn't exist")
 	ErrVoteNotFound    = errors.New("vote doesn't exist")
 
+	ErrConversationNotFound = errors.New("conversation doesn't exist")
+	ErrConversationExist    = errors.New("conversation already exist")
+
 	ErrUserExist = errors.New("user already exist")
 
-	ErrInvalidToken       = errors.New("invalid token")
-	ErrInvalidCommentID   = errors.New("invalid comment ID")
-	ErrInvalidPostID      = errors.New("invalid post ID")
-	ErrInvalidCredentials = errors.New("invalid username or password")
-	ErrInvalidUrl         = errors.New("invalid url")
-	ErrInvalidSignMethod  = errors.New("invalid sign method")
+	ErrInvalidToken          = errors.New("invalid token")
+	ErrInvalidCommentID      = errors.New("invalid comment ID")
+	ErrInvalidPostID         = errors.New("invalid post ID")
+	ErrInvalidNotificationID = errors.New("invalid notification ID")
+	ErrInvalidConversationID = errors.New("invalid conversation ID")
+	ErrInvalidRecipient      = errors.New("invalid recipient")
+	ErrInvalidCredentials    = errors.New("invalid username or password")
+	ErrInvalidUrl            = errors.New("invalid url")
+	ErrInvalidSignMethod     = errors.New("invalid sign method")
 
 	ErrUnAuthorized = errors.New("unuthorized")
 
 	ErrCommentTooLong = errors.New("comment is too long")
+	ErrPostTooLong    = errors.New("post is too long")
+
+	ErrBlocked            = errors.New("blocked by user")
+	ErrInvalidBlockType   = errors.New("invalid block type")
+	ErrInvalidBlockTarget = errors.New("invalid block target")
+
+	ErrBioTooLong    = errors.New("bio is too long")
+	ErrInvalidAvatar = errors.New("invalid avatar")
+
+	ErrMessageEmpty   = errors.New("message is empty")
+	ErrMessageTooLong = errors.New("message is too long")
 
 	ErrVotesActionNotImplement = errors.New("not implement vote action")
+
+	ErrPostLocked   = errors.New("post is locked")
+	ErrPostArchived = errors.New("post is archived")
+	ErrPostPending  = errors.New("post is pending")
+
+	ErrModerateActionNotImplement = errors.New("not implement moderate action")
+
+	ErrInvalidPoll       = errors.New("invalid poll")
+	ErrInvalidPollOption = errors.New("invalid poll option")
+	ErrPostNotPoll       = errors.New("post is not a poll")
+	ErrPollClosed        = errors.New("poll is closed")
+
+	ErrImageTooLarge    = errors.New("image is too large")
+	ErrImageUnsupported = errors.New("unsupported image type")
+	ErrInvalidImage     = errors.New("invalid image")
+
+	ErrBlobNotFound   = errors.New("blob doesn't exist")
+	ErrInvalidBlobKey = errors.New("invalid blob key")
+
+	ErrSchemeNotAllowed  = errors.New("url scheme is not allowed")
+	ErrHostNotAllowed    = errors.New("host is not allowed")
+	ErrAddressNotAllowed = errors.New("address is not allowed")
+	ErrTooManyRedirects  = errors.New("too many redirects")
+	ErrResponseTooLarge  = errors.New("response is too large")
+	ErrLinkUnreachable   = errors.New("link is unreachable")
+
+	ErrContentRejected = errors.New("content rejected by filter")
+	ErrNotHeld         = errors.New("content is not held")
+
+	ErrAlreadyReported = errors.New("already reported")
+	ErrReportNotFound  = errors.New("report doesn't exist")
+	ErrReasonEmpty     = errors.New("reason is empty")
+	ErrReasonTooLong   = errors.New("reason is too long")
+
+	ErrBanNotFound        = errors.New("ban doesn't exist")
+	ErrInvalidBanDuration = errors.New("invalid ban duration")
+	ErrInvalidBanTarget   = errors.New("invalid ban target")
+
+	ErrNotDeleted     = errors.New("content is not deleted")
+	ErrRestoreExpired = errors.New("restore window has passed")
+
+	ErrCategoryNotFound       = errors.New("category doesn't exist")
+	ErrInvalidCategory        = errors.New("invalid category")
+	ErrInvalidRule            = errors.New("invalid rule")
+	ErrInvalidRules           = errors.New("invalid rules")
+	ErrModeratorNotFound      = errors.New("moderator doesn't exist")
+	ErrInvalidModeratorTarget = errors.New("invalid moderator target")
 )
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+)
+
+const (
+	EventPostAdded           = "post-added"
+	EventCommentAdded        = "comment-added"
+	EventCommentDeleted      = "comment-deleted"
+	EventScoreChanged        = "score-changed"
+	EventCommentScoreChanged = "comment-score-changed"
+
+	PostsTopic = "posts"
+)
+
+type Event struct {
+	Type   string `json:"type"`
+	PostID string `json:"postId"`
+	// AuthorID is the author of the post or comment the event carries, so that subscribers who do not see
+	// their content can skip it.
+	AuthorID string          `json:"authorId,omitempty"`
+	Data     json.RawMessage `json:"data"`
+}
+
+func NewEvent(kind string, postID string, data interface{}) (*Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Event{
+		Type:   kind,
+		PostID: postID,
+		Data:   raw,
+	}, nil
+}
+
+// PostTopic is the topic carrying the events of a single post.
+func PostTopic(postID string) string {
+	return "post:" + postID
+}
+
+// IEventBroker fans events out to subscribers, the returned func cancels a subscription.
+type IEventBroker interface {
+	Publish(context.Context, string, *Event) error
+	Subscribe(context.Context, string) (<-chan *Event, func(), error)
+}
@@ -0,0 +1,9 @@
+package model
+
+type Image struct {
+	ID           string `json:"id" bson:"id"`
+	URL          string `json:"url" bson:"url"`
+	ThumbnailURL string `json:"thumbnailUrl" bson:"thumbnailurl"`
+	Width        int    `json:"width" bson:"width"`
+	Height       int    `json:"height" bson:"height"`
+}
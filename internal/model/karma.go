@@ -0,0 +1,14 @@
+package model
+
+import "context"
+
+// Karma is what the votes on a user's posts and comments add up to.
+type Karma struct {
+	Post    int64 `json:"post"`
+	Comment int64 `json:"comment"`
+}
+
+type IKarmaStorage interface {
+	AddKarma(context.Context, string, *Karma) error
+	ReplaceKarma(context.Context, map[string]*Karma) error
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LinkCheck is a queued validation of the link of a pending post.
+type LinkCheck struct {
+	PostID  primitive.ObjectID `json:"postId"`
+	URL     string             `json:"url"`
+	Attempt int                `json:"attempt"`
+	Due     time.Time          `json:"due"`
+}
+
+type ILinkQueue interface {
+	Enqueue(context.Context, *LinkCheck) error
+	// Dequeue blocks until a check is due or the context is done. The check stays leased to the caller
+	// until it is acknowledged, a queue that survives restarts hands it out again once the lease runs out.
+	Dequeue(context.Context) (*LinkCheck, error)
+	// Ack removes a dequeued check for good.
+	Ack(context.Context, *LinkCheck) error
+}
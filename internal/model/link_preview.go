@@ -0,0 +1,9 @@
+package model
+
+// LinkPreview is the page metadata of a link post, shown as a rich link card.
+type LinkPreview struct {
+	Title       string `json:"title,omitempty" bson:"title,omitempty"`
+	Description string `json:"description,omitempty" bson:"description,omitempty"`
+	SiteName    string `json:"siteName,omitempty" bson:"sitename,omitempty"`
+	Image       string `json:"image,omitempty" bson:"image,omitempty"`
+}
@@ -0,0 +1,102 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const MessageMaxLength = 4000
+
+type Conversation struct {
+	ID           primitive.ObjectID            `json:"id" bson:"_id"`
+	Key          string                        `json:"-" bson:"key"`
+	Participants []*Author                     `json:"participants" bson:"participants"`
+	Members      []string                      `json:"-" bson:"members"`
+	LastMessage  *Message                      `json:"lastMessage,omitempty" bson:"lastmessage,omitempty"`
+	LastRead     map[string]primitive.ObjectID `json:"lastRead" bson:"lastread"`
+	UnreadBy     map[string]int64              `json:"-" bson:"unread"`
+	Unread       int64                         `json:"unread" bson:"-"`
+	Created      string                        `json:"created" bson:"created"`
+	Updated      string                        `json:"updated" bson:"updated"`
+}
+
+type Message struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id"`
+	ConversationID primitive.ObjectID `json:"conversationId" bson:"conversation"`
+	Author         *Author            `json:"author" bson:"author"`
+	Body           string             `json:"body" bson:"body"`
+	Read           bool               `json:"read" bson:"-"`
+	Created        string             `json:"created" bson:"created"`
+}
+
+func NewConversation(participants ...*Author) *Conversation {
+	now := time.Now().UTC().Format(layout)
+	conversation := &Conversation{
+		Participants: participants,
+		LastRead:     make(map[string]primitive.ObjectID, len(participants)),
+		UnreadBy:     make(map[string]int64, len(participants)),
+		Created:      now,
+		Updated:      now,
+	}
+	for _, participant := range participants {
+		conversation.Members = append(conversation.Members, participant.ID)
+		conversation.UnreadBy[participant.ID] = 0
+	}
+	conversation.Key = ConversationKey(conversation.Members...)
+	return conversation
+}
+
+func NewMessage(conversationID primitive.ObjectID, author *Author, body string) *Message {
+	return &Message{
+		ConversationID: conversationID,
+		Author:         author,
+		Body:           body,
+		Created:        time.Now().UTC().Format(layout),
+	}
+}
+
+// ConversationKey identifies a conversation by its members regardless of their order.
+func ConversationKey(userIDs ...string) string {
+	sorted := append([]string(nil), userIDs...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ":")
+}
+
+func (c *Conversation) HasMember(userID string) bool {
+	for _, member := range c.Members {
+		if member == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadByOthers reports whether every participant except the author has read the message.
+func (c *Conversation) ReadByOthers(message *Message) bool {
+	for _, member := range c.Members {
+		if member == message.Author.ID {
+			continue
+		}
+		lastRead := c.LastRead[member]
+		if bytes.Compare(lastRead[:], message.ID[:]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+type IMessageStorage interface {
+	AddConversation(context.Context, *Conversation) error
+	GetConversation(context.Context, primitive.ObjectID) (*Conversation, error)
+	GetConversationByKey(context.Context, string) (*Conversation, error)
+	GetConversations(context.Context, string, int, int) ([]*Conversation, error)
+	AddMessage(context.Context, *Conversation, *Message) error
+	GetMessages(context.Context, primitive.ObjectID, int, int) ([]*Message, error)
+	MarkConversationRead(context.Context, *Conversation, string, primitive.ObjectID) error
+	CountUnreadMessages(context.Context, string) (int64, error)
+}
@@ -0,0 +1,7 @@
+package model
+
+import "context"
+
+type IModeratorStorage interface {
+	IsModerator(context.Context, string) (bool, error)
+}
@@ -0,0 +1,81 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ModActionRemovePost     = "remove_post"
+	ModActionRemoveComment  = "remove_comment"
+	ModActionLock           = "lock"
+	ModActionUnlock         = "unlock"
+	ModActionArchive        = "archive"
+	ModActionUnarchive      = "unarchive"
+	ModActionApprove        = "approve"
+	ModActionReject         = "reject"
+	ModActionApproveComment = "approve_comment"
+	ModActionRejectComment  = "reject_comment"
+	ModActionDismiss        = "dismiss"
+	ModActionWarn           = "warn"
+	ModActionBan            = "ban"
+	ModActionUnban          = "unban"
+	ModActionShadowBan      = "shadow_ban"
+	ModActionShadowUnban    = "shadow_unban"
+	ModActionRestorePost    = "restore_post"
+	ModActionRestoreComment = "restore_comment"
+	ModActionAddMod         = "add_moderator"
+	ModActionRemoveMod      = "remove_moderator"
+	ModActionEditRules      = "edit_rules"
+)
+
+// ModAction is an entry of the moderation log, it is never changed once recorded.
+type ModAction struct {
+	ID        primitive.ObjectID  `json:"id" bson:"_id"`
+	Action    string              `json:"action" bson:"action"`
+	Moderator *Author             `json:"moderator" bson:"moderator"`
+	Category  string              `json:"category,omitempty" bson:"category,omitempty"`
+	Post      *PostLink           `json:"post,omitempty" bson:"post,omitempty"`
+	CommentID *primitive.ObjectID `json:"commentId,omitempty" bson:"comment,omitempty"`
+	// Target is the user whose content or account the action was about.
+	Target  *Author `json:"target,omitempty" bson:"target,omitempty"`
+	Excerpt string  `json:"excerpt,omitempty" bson:"excerpt,omitempty"`
+	Reason  string  `json:"reason,omitempty" bson:"reason,omitempty"`
+	Created string  `json:"created" bson:"created"`
+
+	// Rule is the category rule the content was acted on for.
+	Rule *CitedRule `json:"rule,omitempty" bson:"rule,omitempty"`
+}
+
+func NewModAction(action string, moderator *Author, reason string) *ModAction {
+	return &ModAction{
+		Action:    action,
+		Moderator: moderator,
+		Reason:    reason,
+		Created:   time.Now().UTC().Format(layout),
+	}
+}
+
+// ModLogFilter narrows the moderation log, empty fields match everything.
+type ModLogFilter struct {
+	Category  string
+	Moderator string
+	Action    string
+	// Categories limits the log to the actions in these categories.
+	Categories []string
+	// TargetID limits the log to the actions about one user.
+	TargetID string
+	// ExcludeActions drops the entries of these actions.
+	ExcludeActions []string
+	Offset         int
+	Limit          int
+}
+
+// IModLogStorage is append only, there is no way to change or drop an entry.
+type IModLogStorage interface {
+	AddModAction(context.Context, *ModAction) error
+	// GetModActions returns the matching entries, newest first.
+	GetModActions(context.Context, ModLogFilter) ([]*ModAction, error)
+}
@@ -15,7 +15,9 @@ type ICollection interface {
 	DeleteOne(context.Context, interface{}, ...*options.DeleteOptions) (*mongo.DeleteResult, error)
 	DeleteMany(context.Context, interface{}, ...*options.DeleteOptions) (*mongo.DeleteResult, error)
 	UpdateOne(context.Context, interface{}, interface{}, ...*options.UpdateOptions) (*mongo.UpdateResult, error)
+	UpdateMany(context.Context, interface{}, interface{}, ...*options.UpdateOptions) (*mongo.UpdateResult, error)
 	UpdateByID(context.Context, interface{}, interface{}, ...*options.UpdateOptions) (*mongo.UpdateResult, error)
+	ReplaceOne(context.Context, interface{}, interface{}, ...*options.ReplaceOptions) (*mongo.UpdateResult, error)
 }
 
 type MyMongoCollection struct {
@@ -50,6 +52,14 @@ func (coll MyMongoCollection) UpdateOne(ctx context.Context, filter interface{},
 	return coll.Collection.UpdateOne(ctx, filter, update, opts...)
 }
 
+func (coll MyMongoCollection) UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return coll.Collection.UpdateMany(ctx, filter, update, opts...)
+}
+
 func (coll MyMongoCollection) UpdateByID(ctx context.Context, id interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
 	return coll.Collection.UpdateByID(ctx, id, update, opts...)
 }
+
+func (coll MyMongoCollection) ReplaceOne(ctx context.Context, filter interface{}, replacement interface{}, opts ...*options.ReplaceOptions) (*mongo.UpdateResult, error) {
+	return coll.Collection.ReplaceOne(ctx, filter, replacement, opts...)
+}
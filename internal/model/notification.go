@@ -0,0 +1,62 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	NotificationMention      = "mention"
+	NotificationPostReply    = "post_reply"
+	NotificationCommentReply = "comment_reply"
+	NotificationPostLive     = "post_live"
+	NotificationPostRejected = "post_rejected"
+	NotificationWarning      = "warning"
+)
+
+type Notification struct {
+	ID        primitive.ObjectID  `json:"id" bson:"_id"`
+	UserID    string              `json:"-" bson:"user"`
+	Type      string              `json:"type" bson:"type"`
+	Actor     *Author             `json:"actor" bson:"actor"`
+	PostID    primitive.ObjectID  `json:"postId" bson:"postid"`
+	PostTitle string              `json:"postTitle" bson:"posttitle"`
+	CommentID *primitive.ObjectID `json:"commentId,omitempty" bson:"commentid,omitempty"`
+	Message   string              `json:"message,omitempty" bson:"message,omitempty"`
+	Read      bool                `json:"read" bson:"read"`
+	Created   string              `json:"created" bson:"created"`
+}
+
+func NewNotification(kind string, userID string, actor *Author, post *Post, comment *Comment) *Notification {
+	notification := &Notification{
+		UserID:    userID,
+		Type:      kind,
+		Actor:     actor,
+		PostID:    post.ID,
+		PostTitle: post.Title,
+		Created:   time.Now().UTC().Format(layout),
+	}
+	if comment != nil {
+		notification.CommentID = &comment.ID
+	}
+	return notification
+}
+
+type INotificationStorage interface {
+	AddNotification(context.Context, *Notification) error
+	GetNotifications(context.Context, string, bool, int, int) ([]*Notification, error)
+	MarkRead(context.Context, string, []primitive.ObjectID) error
+	MarkAllRead(context.Context, string) error
+	CountUnread(context.Context, string) (int64, error)
+}
+
+// INotifier is told about new content so that it can notify the people involved.
+type INotifier interface {
+	PostAdded(context.Context, *Post)
+	CommentAdded(context.Context, *Post, *Comment)
+	LinkChecked(context.Context, *Post)
+	// Warned tells the author of the post, or of the comment when set, that a moderator warned them.
+	Warned(context.Context, *Post, *Comment, string)
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+const (
+	PollMinOptions = 2
+	PollMaxOptions = 10
+)
+
+type Poll struct {
+	Options  []*PollOption `json:"options" bson:"options"`
+	ClosesAt *time.Time    `json:"closesAt,omitempty" bson:"closesat,omitempty"`
+	Closed   bool          `json:"closed" bson:"-"`
+	Total    int64         `json:"total" bson:"-"`
+	Votes    []*PollVote   `json:"votes" bson:"votes"`
+}
+
+type PollOption struct {
+	ID         int    `json:"id" bson:"id"`
+	Text       string `json:"text" bson:"text"`
+	Votes      int64  `json:"votes" bson:"-"`
+	Percentage int64  `json:"percentage" bson:"-"`
+}
+
+type PollVote struct {
+	UserID string `json:"user" bson:"user"`
+	Option int    `json:"option" bson:"option"`
+}
+
+// IsClosed reports whether the poll stopped accepting votes at now.
+func (p *Poll) IsClosed(now time.Time) bool {
+	return p.ClosesAt != nil && !now.Before(*p.ClosesAt)
+}
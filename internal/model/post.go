@@ -8,12 +8,24 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const (
+	PostStatusPending  = "pending"
+	PostStatusLive     = "live"
+	PostStatusRejected = "rejected"
+	PostStatusHeld     = "held"
+)
+
+const PostTextMaxLength = 2000
+
 type Post struct {
 	Category string `json:"category" bson:"category"`
 	Text     string `json:"text" bson:"text"`
+	HTML     string `json:"html" bson:"-"`
 	Title    string `json:"title" bson:"title"`
 	Type     string `json:"type" bson:"type"`
 	Url      string `json:"url" bson:"url"`
+	// CanonicalUrl is the normalized Url that duplicate submissions are detected by.
+	CanonicalUrl string `json:"-" bson:"canonicalurl,omitempty"`
 
 	ID     primitive.ObjectID `json:"id" bson:"_id"`
 	Author *Author            `json:"author" bson:"author"`
@@ -23,11 +35,28 @@ type Post struct {
 	Views            int64  `json:"views" bson:"views"`
 	Score            int64  `json:"score" bson:"score"`
 
+	Status   string `json:"status" bson:"status,omitempty"`
+	Locked   bool   `json:"locked" bson:"locked"`
+	Archived bool   `json:"archived" bson:"archived"`
+	// Unarchived keeps a post a moderator unarchived open past the automatic archive age.
+	Unarchived bool `json:"-" bson:"unarchived,omitempty"`
+
 	Comments []*Comment  `json:"comments" bson:"comments"`
 	CM       *sync.Mutex `json:"-" bson:"-"`
 
 	Votes []*Vote     `json:"votes" bson:"votes"`
 	VM    *sync.Mutex `json:"-" bson:"-"`
+
+	Poll  *Poll  `json:"poll,omitempty" bson:"poll,omitempty"`
+	Image *Image `json:"image,omitempty" bson:"image,omitempty"`
+
+	Preview *LinkPreview `json:"preview,omitempty" bson:"preview,omitempty"`
+
+	// Filter is the verdict that held the post for moderation, only moderators get to see it, through HeldContent.
+	Filter *FilterVerdict `json:"-" bson:"filter,omitempty"`
+
+	DeletedAt *time.Time `json:"deletedAt,omitempty" bson:"deletedat,omitempty"`
+	DeletedBy *Author    `json:"deletedBy,omitempty" bson:"deletedby,omitempty"`
 }
 
 func NewPost() *Post {
@@ -43,6 +72,21 @@ func NewPost() *Post {
 	}
 }
 
+// CreatedAt parses the Created field, which is stored as a formatted string.
+func (p *Post) CreatedAt() (time.Time, error) {
+	return time.Parse(layout, p.Created)
+}
+
+// IsLive reports whether the post is visible to everyone, posts stored before statuses existed are live.
+func (p *Post) IsLive() bool {
+	return p.Status == "" || p.Status == PostStatusLive
+}
+
+// IsDeleted reports whether the post was soft-deleted and waits to be restored or purged.
+func (p *Post) IsDeleted() bool {
+	return p.DeletedAt != nil
+}
+
 type Vote struct {
 	UserID string `json:"user" bson:"user"`
 	Score  int64  `json:"vote" bson:"vote"`
@@ -51,14 +95,38 @@ type Vote struct {
 type IPostStorage interface {
 	GetAllPosts(context.Context) ([]*Post, error)
 	GetPostByID(context.Context, primitive.ObjectID) (*Post, error)
+	// GetPostsByIDs returns the posts found among the given IDs, in no particular order.
+	GetPostsByIDs(context.Context, []primitive.ObjectID) ([]*Post, error)
 	GetPostsByCategory(context.Context, string) ([]*Post, error)
 	GetPostsByUser(context.Context, string) ([]*Post, error)
 	AddPost(context.Context, *Post) error
-	DeletePost(context.Context, primitive.ObjectID) error
+	DeletePost(context.Context, primitive.ObjectID, *Author, time.Time) error
+	RestorePost(context.Context, primitive.ObjectID) error
 	AddView(context.Context, *Post) error
 	AddComment(context.Context, *Post, *Comment) error
-	DeleteComment(context.Context, *Post, primitive.ObjectID) error
+	DeleteComment(context.Context, *Post, primitive.ObjectID, *Author, time.Time) error
+	RestoreComment(context.Context, *Post, primitive.ObjectID) error
 	Vote(context.Context, *Post, *Vote) error
 	UnVote(context.Context, *Post, string) error
 	UpdateScore(context.Context, *Post) error
+	VoteComment(context.Context, *Post, primitive.ObjectID, *Vote) error
+	UnVoteComment(context.Context, *Post, primitive.ObjectID, string) error
+	// UpdateCommentScore stores the score the comment's votes add up to.
+	UpdateCommentScore(context.Context, *Post, *Comment) error
+	// GetCommentsByVoter returns the comments the user voted on.
+	GetCommentsByVoter(context.Context, string) ([]*Comment, error)
+	SetLocked(context.Context, *Post, bool) error
+	SetArchived(context.Context, *Post, bool) error
+	PollVote(context.Context, *Post, *PollVote) error
+	CountPostsByUser(context.Context, string) (int64, error)
+	CountCommentsByUser(context.Context, string) (int64, error)
+	SetLinkStatus(context.Context, *Post, string, *LinkPreview) error
+	GetCommentsByUser(context.Context, string, UserCommentsFilter) ([]*UserComment, error)
+	GetPostsByURL(context.Context, string, time.Time) ([]*Post, error)
+	// GetPostsByVoter returns the posts the user voted on, without their comments.
+	GetPostsByVoter(context.Context, string) ([]*Post, error)
+	SetStatus(context.Context, *Post, string) error
+	SetCommentStatus(context.Context, *Post, primitive.ObjectID, string) error
+	// PurgeDeleted removes for good the posts and comments soft-deleted before the given time.
+	PurgeDeleted(context.Context, time.Time) (int64, error)
 }
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ReportOpen   = "open"
+	ReportClosed = "closed"
+
+	ReportDismiss = "dismiss"
+	ReportRemove  = "remove"
+	ReportWarn    = "warn"
+
+	ReportReasonMaxLength = 500
+)
+
+// Report flags a post, or a comment when CommentID is set, for the moderators.
+type Report struct {
+	ID        primitive.ObjectID  `json:"id" bson:"_id"`
+	PostID    primitive.ObjectID  `json:"postId" bson:"post"`
+	CommentID *primitive.ObjectID `json:"commentId,omitempty" bson:"comment,omitempty"`
+	Category  string              `json:"category" bson:"category"`
+	Reason    string              `json:"reason" bson:"reason"`
+	Reporter  *Author             `json:"reporter" bson:"reporter"`
+	Created   string              `json:"created" bson:"created"`
+
+	Status     string            `json:"status" bson:"status"`
+	Resolution *ReportResolution `json:"resolution,omitempty" bson:"resolution,omitempty"`
+}
+
+// ReportResolution is how a moderator closed a report.
+type ReportResolution struct {
+	Action    string  `json:"action" bson:"action"`
+	Note      string  `json:"note,omitempty" bson:"note,omitempty"`
+	Moderator *Author `json:"moderator" bson:"moderator"`
+	Created   string  `json:"created" bson:"created"`
+}
+
+func NewReport(reporter *Author, post *Post, commentID *primitive.ObjectID, reason string) *Report {
+	return &Report{
+		PostID:    post.ID,
+		CommentID: commentID,
+		Category:  post.Category,
+		Reason:    reason,
+		Reporter:  reporter,
+		Created:   time.Now().UTC().Format(layout),
+		Status:    ReportOpen,
+	}
+}
+
+func NewReportResolution(action string, note string, moderator *Author) *ReportResolution {
+	return &ReportResolution{
+		Action:    action,
+		Note:      note,
+		Moderator: moderator,
+		Created:   time.Now().UTC().Format(layout),
+	}
+}
+
+// SameTarget reports whether both reports are about the same post or comment.
+func (r *Report) SameTarget(other *Report) bool {
+	if r.PostID != other.PostID || (r.CommentID == nil) != (other.CommentID == nil) {
+		return false
+	}
+	return r.CommentID == nil || *r.CommentID == *other.CommentID
+}
+
+// ReportGroup is the open reports on one post or comment, as listed in the moderation queue.
+type ReportGroup struct {
+	Post      *PostLink           `json:"post"`
+	CommentID *primitive.ObjectID `json:"commentId,omitempty"`
+	Author    *Author             `json:"author"`
+	Excerpt   string              `json:"excerpt"`
+	Count     int                 `json:"count"`
+	Reports   []*Report           `json:"reports"`
+}
+
+// ReportQueueFilter selects a page of the moderation queue.
+type ReportQueueFilter struct {
+	// Categories limits the queue to the reports in these categories, none means every category.
+	Categories []string
+	Offset     int
+	Limit      int
+}
+
+type IReportStorage interface {
+	// AddReport returns ErrAlreadyReported when the reporter already has an open report on the same post or comment.
+	AddReport(context.Context, *Report) error
+	// GetReportQueue returns a page of the open reports grouped by the post or comment they are about, the most
+	// reported first and equally reported ones in the order they were first reported. Only CommentID, Count and
+	// the Reports, oldest first, are set on the groups.
+	GetReportQueue(context.Context, ReportQueueFilter) ([]*ReportGroup, error)
+	// GetOpenReportsByPost returns the open reports on the post and its comments, oldest first.
+	GetOpenReportsByPost(context.Context, primitive.ObjectID) ([]*Report, error)
+	CloseReports(context.Context, []primitive.ObjectID, *ReportResolution) error
+}
@@ -1,11 +1,22 @@
 package model
 
-import "context"
+import (
+	"context"
+	"time"
+)
+
+const BioMaxLength = 500
 
 type User struct {
 	ID       string `json:"id"`
 	Username string `json:"username"`
 	Password string `json:"password"`
+
+	Bio          string    `json:"bio"`
+	Avatar       string    `json:"avatar"`
+	PostKarma    int64     `json:"postKarma"`
+	CommentKarma int64     `json:"commentKarma"`
+	Created      time.Time `json:"created"`
 }
 
 type Author struct {
@@ -13,7 +24,21 @@ type Author struct {
 	ID       string `json:"id"`
 }
 
+// Profile is the public view of a user.
+type Profile struct {
+	ID           string    `json:"id"`
+	Username     string    `json:"username"`
+	Bio          string    `json:"bio"`
+	Avatar       string    `json:"avatar"`
+	Created      time.Time `json:"created"`
+	PostKarma    int64     `json:"postKarma"`
+	CommentKarma int64     `json:"commentKarma"`
+	Posts        int64     `json:"posts"`
+	Comments     int64     `json:"comments"`
+}
+
 type IUserStorage interface {
 	GetUser(context.Context, string) (*User, error)
 	AddUser(context.Context, *User) error
+	UpdateProfile(context.Context, *User) error
 }
@@ -0,0 +1,84 @@
+package filesystem_repository
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+)
+
+type BlobStorage struct {
+	root string
+}
+
+func NewBlobStorage(root string) (*BlobStorage, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &BlobStorage{
+		root: root,
+	}, nil
+}
+
+func (s *BlobStorage) Put(ctx context.Context, key string, blob io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.root, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, blob); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *BlobStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, model.ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func (s *BlobStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if os.IsNotExist(err) {
+		return model.ErrBlobNotFound
+	}
+	return err
+}
+
+// path maps a key to a file in root, keys are flat names so nothing can escape it.
+func (s *BlobStorage) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, ".") || key != filepath.Base(key) {
+		return "", model.ErrInvalidBlobKey
+	}
+	return filepath.Join(s.root, key), nil
+}
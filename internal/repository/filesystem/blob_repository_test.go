@@ -0,0 +1,46 @@
+package filesystem_repository
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlobStorage_PutGetDelete(t *testing.T) {
+	ctx := context.Background()
+	storage, err := NewBlobStorage(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, storage.Put(ctx, "image.png", strings.NewReader("data")))
+
+	blob, err := storage.Get(ctx, "image.png")
+	require.NoError(t, err)
+	data, err := io.ReadAll(blob)
+	require.NoError(t, err)
+	require.NoError(t, blob.Close())
+	require.Equal(t, "data", string(data))
+
+	require.NoError(t, storage.Delete(ctx, "image.png"))
+
+	_, err = storage.Get(ctx, "image.png")
+	require.ErrorIs(t, err, model.ErrBlobNotFound)
+	require.ErrorIs(t, storage.Delete(ctx, "image.png"), model.ErrBlobNotFound)
+}
+
+func TestBlobStorage_InvalidKey(t *testing.T) {
+	ctx := context.Background()
+	storage, err := NewBlobStorage(t.TempDir())
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "../secret", "dir/file", ".hidden"} {
+		t.Run(key, func(t *testing.T) {
+			require.ErrorIs(t, storage.Put(ctx, key, strings.NewReader("data")), model.ErrInvalidBlobKey)
+			_, err := storage.Get(ctx, key)
+			require.ErrorIs(t, err, model.ErrInvalidBlobKey)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package filesystem_repository
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+)
+
+// SpamModelStorage keeps the spam classifier in a JSON file.
+type SpamModelStorage struct {
+	path string
+}
+
+func NewSpamModelStorage(path string) (*SpamModelStorage, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	return &SpamModelStorage{
+		path: path,
+	}, nil
+}
+
+func (s *SpamModelStorage) LoadSpamModel(ctx context.Context) (*model.SpamModel, error) {
+	spamModel := &model.SpamModel{Tokens: make(map[string]*model.TokenCount)}
+
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return spamModel, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, spamModel); err != nil {
+		return nil, err
+	}
+	if spamModel.Tokens == nil {
+		spamModel.Tokens = make(map[string]*model.TokenCount)
+	}
+	return spamModel, nil
+}
+
+func (s *SpamModelStorage) SaveSpamModel(ctx context.Context, spamModel *model.SpamModel) error {
+	data, err := json.Marshal(spamModel)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".spam-model-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
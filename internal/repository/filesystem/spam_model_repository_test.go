@@ -0,0 +1,31 @@
+package filesystem_repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpamModelStorage_SaveLoad(t *testing.T) {
+	ctx := context.Background()
+	storage, err := NewSpamModelStorage(filepath.Join(t.TempDir(), "filters", "spam.json"))
+	require.NoError(t, err)
+
+	empty, err := storage.LoadSpamModel(ctx)
+	require.NoError(t, err)
+	require.Equal(t, &model.SpamModel{Tokens: map[string]*model.TokenCount{}}, empty)
+
+	saved := &model.SpamModel{
+		SpamDocs: 2,
+		HamDocs:  1,
+		Tokens:   map[string]*model.TokenCount{"casino": {Spam: 2}, "golang": {Ham: 1}},
+	}
+	require.NoError(t, storage.SaveSpamModel(ctx, saved))
+
+	loaded, err := storage.LoadSpamModel(ctx)
+	require.NoError(t, err)
+	require.Equal(t, saved, loaded)
+}
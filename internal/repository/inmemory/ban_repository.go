@@ -0,0 +1,80 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type BanStorage struct {
+	Storage []*model.Ban
+	mu      *sync.RWMutex
+}
+
+func NewBanStorage() *BanStorage {
+	return &BanStorage{
+		Storage: make([]*model.Ban, 0),
+		mu:      new(sync.RWMutex),
+	}
+}
+
+func (s *BanStorage) AddBan(ctx context.Context, ban *model.Ban) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ban.ID = primitive.NewObjectID()
+	for idx, stored := range s.Storage {
+		if stored.User.ID == ban.User.ID && stored.Category == ban.Category && stored.Shadow == ban.Shadow {
+			ban.ID = stored.ID
+			s.Storage = append(s.Storage[:idx], s.Storage[idx+1:]...)
+			break
+		}
+	}
+	copied := *ban
+	s.Storage = append(s.Storage, &copied)
+	return nil
+}
+
+func (s *BanStorage) RemoveBan(ctx context.Context, userID string, category string, shadow bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for idx, stored := range s.Storage {
+		if stored.User.ID == userID && stored.Category == category && stored.Shadow == shadow {
+			s.Storage = append(s.Storage[:idx], s.Storage[idx+1:]...)
+			return nil
+		}
+	}
+	return model.ErrBanNotFound
+}
+
+func (s *BanStorage) GetBans(ctx context.Context) ([]*model.Ban, error) {
+	return s.getBans("", false), nil
+}
+
+func (s *BanStorage) GetUserBans(ctx context.Context, userID string) ([]*model.Ban, error) {
+	return s.getBans(userID, false), nil
+}
+
+func (s *BanStorage) GetShadowBans(ctx context.Context) ([]*model.Ban, error) {
+	return s.getBans("", true), nil
+}
+
+func (s *BanStorage) getBans(userID string, shadowOnly bool) []*model.Ban {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	bans := []*model.Ban{}
+	for idx := len(s.Storage) - 1; idx >= 0; idx-- {
+		if shadowOnly && !s.Storage[idx].Shadow {
+			continue
+		}
+		if userID == "" || s.Storage[idx].User.ID == userID {
+			copied := *s.Storage[idx]
+			bans = append(bans, &copied)
+		}
+	}
+	return bans
+}
@@ -0,0 +1,69 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+)
+
+type BlockStorage struct {
+	Storage map[string]map[string]*model.Block
+	mu      *sync.RWMutex
+}
+
+func NewBlockStorage() *BlockStorage {
+	return &BlockStorage{
+		Storage: make(map[string]map[string]*model.Block),
+		mu:      new(sync.RWMutex),
+	}
+}
+
+func (s *BlockStorage) AddBlock(ctx context.Context, block *model.Block) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	blocks, ok := s.Storage[block.UserID]
+	if !ok {
+		blocks = make(map[string]*model.Block)
+		s.Storage[block.UserID] = blocks
+	}
+	copied := *block
+	if existing, ok := blocks[block.Target.ID]; ok {
+		copied.Created = existing.Created
+	}
+	blocks[block.Target.ID] = &copied
+	return nil
+}
+
+func (s *BlockStorage) RemoveBlock(ctx context.Context, userID string, targetID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.Storage[userID], targetID)
+	return nil
+}
+
+func (s *BlockStorage) GetBlocks(ctx context.Context, userID string) ([]*model.Block, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	blocks := []*model.Block{}
+	for _, block := range s.Storage[userID] {
+		copied := *block
+		blocks = append(blocks, &copied)
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].Created > blocks[j].Created
+	})
+	return blocks, nil
+}
+
+func (s *BlockStorage) IsBlocked(ctx context.Context, userID string, targetID string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	block, ok := s.Storage[userID][targetID]
+	return ok && block.Type == model.BlockBlock, nil
+}
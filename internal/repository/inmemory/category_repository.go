@@ -0,0 +1,99 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+)
+
+type CategoryStorage struct {
+	Storage map[string]*model.Category
+	mu      *sync.RWMutex
+}
+
+func NewCategoryStorage() *CategoryStorage {
+	return &CategoryStorage{
+		Storage: make(map[string]*model.Category),
+		mu:      new(sync.RWMutex),
+	}
+}
+
+func (s *CategoryStorage) GetCategories(ctx context.Context) ([]*model.Category, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	categories := make([]*model.Category, 0, len(s.Storage))
+	for _, category := range s.Storage {
+		categories = append(categories, copyCategory(category))
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i].Name < categories[j].Name
+	})
+	return categories, nil
+}
+
+func (s *CategoryStorage) GetCategory(ctx context.Context, name string) (*model.Category, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	category, ok := s.Storage[name]
+	if !ok {
+		return nil, model.ErrCategoryNotFound
+	}
+	return copyCategory(category), nil
+}
+
+func (s *CategoryStorage) SetRules(ctx context.Context, name string, rules []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.category(name).Rules = append([]string{}, rules...)
+	return nil
+}
+
+func (s *CategoryStorage) AddModerator(ctx context.Context, name string, moderator *model.Author) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	category := s.category(name)
+	if !category.IsModerator(moderator.ID) {
+		category.Moderators = append(category.Moderators, moderator)
+	}
+	return nil
+}
+
+func (s *CategoryStorage) RemoveModerator(ctx context.Context, name string, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	category, ok := s.Storage[name]
+	if !ok {
+		return model.ErrModeratorNotFound
+	}
+	for idx, moderator := range category.Moderators {
+		if moderator.ID == userID {
+			category.Moderators = append(category.Moderators[:idx], category.Moderators[idx+1:]...)
+			return nil
+		}
+	}
+	return model.ErrModeratorNotFound
+}
+
+// category returns the stored category, setting it up first if needed. The caller holds the lock.
+func (s *CategoryStorage) category(name string) *model.Category {
+	category, ok := s.Storage[name]
+	if !ok {
+		category = model.NewCategory(name)
+		s.Storage[name] = category
+	}
+	return category
+}
+
+func copyCategory(category *model.Category) *model.Category {
+	copied := *category
+	copied.Rules = append([]string{}, category.Rules...)
+	copied.Moderators = append([]*model.Author{}, category.Moderators...)
+	return &copied
+}
@@ -0,0 +1,64 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+)
+
+// subscriberBuffer is how many events a subscriber may lag behind before new ones are dropped.
+const subscriberBuffer = 64
+
+type EventBroker struct {
+	subscribers map[string]map[chan *model.Event]struct{}
+	mu          *sync.RWMutex
+}
+
+func NewEventBroker() *EventBroker {
+	return &EventBroker{
+		subscribers: make(map[string]map[chan *model.Event]struct{}),
+		mu:          new(sync.RWMutex),
+	}
+}
+
+func (b *EventBroker) Publish(ctx context.Context, topic string, event *model.Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for subscriber := range b.subscribers[topic] {
+		select {
+		case subscriber <- event:
+		default:
+			// a slow subscriber must not block the publisher
+		}
+	}
+	return nil
+}
+
+func (b *EventBroker) Subscribe(ctx context.Context, topic string) (<-chan *model.Event, func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	subscriber := make(chan *model.Event, subscriberBuffer)
+	if b.subscribers[topic] == nil {
+		b.subscribers[topic] = make(map[chan *model.Event]struct{})
+	}
+	b.subscribers[topic][subscriber] = struct{}{}
+
+	once := new(sync.Once)
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			delete(b.subscribers[topic], subscriber)
+			if len(b.subscribers[topic]) == 0 {
+				delete(b.subscribers, topic)
+			}
+			close(subscriber)
+		})
+	}
+
+	return subscriber, unsubscribe, nil
+}
@@ -0,0 +1,97 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+)
+
+// LinkQueue keeps link checks ordered by due time, it is lost on restart.
+type LinkQueue struct {
+	checks []*model.LinkCheck
+	mu     *sync.Mutex
+	wake   chan struct{}
+}
+
+func NewLinkQueue() *LinkQueue {
+	return &LinkQueue{
+		checks: make([]*model.LinkCheck, 0),
+		mu:     new(sync.Mutex),
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+func (q *LinkQueue) Enqueue(ctx context.Context, check *model.LinkCheck) error {
+	q.mu.Lock()
+	idx := sort.Search(len(q.checks), func(i int) bool {
+		return q.checks[i].Due.After(check.Due)
+	})
+	q.checks = append(q.checks, nil)
+	copy(q.checks[idx+1:], q.checks[idx:])
+	q.checks[idx] = check
+	q.mu.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (q *LinkQueue) Dequeue(ctx context.Context) (*model.LinkCheck, error) {
+	for {
+		q.mu.Lock()
+		wait := time.Duration(-1)
+		if len(q.checks) > 0 {
+			wait = time.Until(q.checks[0].Due)
+			if wait <= 0 {
+				check := q.checks[0]
+				q.checks[0] = nil
+				q.checks = q.checks[1:]
+				q.mu.Unlock()
+				q.passWake()
+				return check, nil
+			}
+		}
+		q.mu.Unlock()
+
+		var timer *time.Timer
+		var due <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			due = timer.C
+		}
+		select {
+		case <-ctx.Done():
+		case <-q.wake:
+		case <-due:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Ack does nothing, dequeued checks are gone from memory already and pending posts are queued again on startup.
+func (q *LinkQueue) Ack(ctx context.Context, check *model.LinkCheck) error {
+	return nil
+}
+
+// passWake lets another waiting worker look at the rest of the queue.
+func (q *LinkQueue) passWake() {
+	q.mu.Lock()
+	pending := len(q.checks) > 0
+	q.mu.Unlock()
+	if pending {
+		select {
+		case q.wake <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MessageStorage struct {
+	Conversations map[primitive.ObjectID]*model.Conversation
+	Messages      map[primitive.ObjectID][]*model.Message
+	mu            *sync.RWMutex
+}
+
+func NewMessageStorage() *MessageStorage {
+	return &MessageStorage{
+		Conversations: make(map[primitive.ObjectID]*model.Conversation),
+		Messages:      make(map[primitive.ObjectID][]*model.Message),
+		mu:            new(sync.RWMutex),
+	}
+}
+
+func (s *MessageStorage) AddConversation(ctx context.Context, conversation *model.Conversation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stored := range s.Conversations {
+		if stored.Key == conversation.Key {
+			return model.ErrConversationExist
+		}
+	}
+	conversation.ID = primitive.NewObjectID()
+	s.Conversations[conversation.ID] = copyConversation(conversation)
+	return nil
+}
+
+func (s *MessageStorage) GetConversation(ctx context.Context, conversationID primitive.ObjectID) (*model.Conversation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	conversation, ok := s.Conversations[conversationID]
+	if !ok {
+		return nil, model.ErrConversationNotFound
+	}
+	return copyConversation(conversation), nil
+}
+
+func (s *MessageStorage) GetConversationByKey(ctx context.Context, key string) (*model.Conversation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, conversation := range s.Conversations {
+		if conversation.Key == key {
+			return copyConversation(conversation), nil
+		}
+	}
+	return nil, model.ErrConversationNotFound
+}
+
+func (s *MessageStorage) GetConversations(ctx context.Context, userID string, offset int, limit int) ([]*model.Conversation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	conversations := []*model.Conversation{}
+	for _, conversation := range s.Conversations {
+		if conversation.HasMember(userID) {
+			conversations = append(conversations, copyConversation(conversation))
+		}
+	}
+	sort.Slice(conversations, func(i, j int) bool {
+		if conversations[i].Updated == conversations[j].Updated {
+			return conversations[i].ID.Hex() > conversations[j].ID.Hex()
+		}
+		return conversations[i].Updated > conversations[j].Updated
+	})
+
+	if offset >= len(conversations) {
+		return []*model.Conversation{}, nil
+	}
+	conversations = conversations[offset:]
+	if limit < len(conversations) {
+		conversations = conversations[:limit]
+	}
+	return conversations, nil
+}
+
+func (s *MessageStorage) AddMessage(ctx context.Context, conversation *model.Conversation, message *model.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.Conversations[conversation.ID]
+	if !ok {
+		return model.ErrConversationNotFound
+	}
+
+	message.ID = primitive.NewObjectID()
+	copied := *message
+	s.Messages[conversation.ID] = append(s.Messages[conversation.ID], &copied)
+
+	stored.LastMessage = &copied
+	stored.Updated = message.Created
+	stored.LastRead[message.Author.ID] = message.ID
+	for _, member := range stored.Members {
+		if member != message.Author.ID {
+			stored.UnreadBy[member]++
+		}
+	}
+	return nil
+}
+
+func (s *MessageStorage) GetMessages(ctx context.Context, conversationID primitive.ObjectID, offset int, limit int) ([]*model.Message, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stored := s.Messages[conversationID]
+	messages := make([]*model.Message, 0, len(stored))
+	for i := len(stored) - 1; i >= 0; i-- {
+		copied := *stored[i]
+		messages = append(messages, &copied)
+	}
+
+	if offset >= len(messages) {
+		return []*model.Message{}, nil
+	}
+	messages = messages[offset:]
+	if limit < len(messages) {
+		messages = messages[:limit]
+	}
+	return messages, nil
+}
+
+func (s *MessageStorage) MarkConversationRead(ctx context.Context, conversation *model.Conversation, userID string, messageID primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.Conversations[conversation.ID]
+	if !ok {
+		return model.ErrConversationNotFound
+	}
+	stored.LastRead[userID] = messageID
+	stored.UnreadBy[userID] = 0
+	return nil
+}
+
+func (s *MessageStorage) CountUnreadMessages(ctx context.Context, userID string) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var count int64
+	for _, conversation := range s.Conversations {
+		if conversation.HasMember(userID) {
+			count += conversation.UnreadBy[userID]
+		}
+	}
+	return count, nil
+}
+
+func copyConversation(conversation *model.Conversation) *model.Conversation {
+	copied := *conversation
+	copied.Participants = append([]*model.Author(nil), conversation.Participants...)
+	copied.Members = append([]string(nil), conversation.Members...)
+	copied.LastRead = make(map[string]primitive.ObjectID, len(conversation.LastRead))
+	for userID, messageID := range conversation.LastRead {
+		copied.LastRead[userID] = messageID
+	}
+	copied.UnreadBy = make(map[string]int64, len(conversation.UnreadBy))
+	for userID, unread := range conversation.UnreadBy {
+		copied.UnreadBy[userID] = unread
+	}
+	return &copied
+}
@@ -0,0 +1,32 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+)
+
+type ModeratorStorage struct {
+	Storage map[string]struct{}
+	mu      *sync.RWMutex
+}
+
+func NewModeratorStorage(usernames ...string) *ModeratorStorage {
+	storage := make(map[string]struct{}, len(usernames))
+	for _, username := range usernames {
+		if username != "" {
+			storage[username] = struct{}{}
+		}
+	}
+	return &ModeratorStorage{
+		Storage: storage,
+		mu:      new(sync.RWMutex),
+	}
+}
+
+func (s *ModeratorStorage) IsModerator(ctx context.Context, username string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.Storage[username]
+	return ok, nil
+}
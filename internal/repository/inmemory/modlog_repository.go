@@ -0,0 +1,68 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ModLogStorage struct {
+	Storage []*model.ModAction
+	mu      *sync.RWMutex
+}
+
+func NewModLogStorage() *ModLogStorage {
+	return &ModLogStorage{
+		Storage: make([]*model.ModAction, 0),
+		mu:      new(sync.RWMutex),
+	}
+}
+
+func (s *ModLogStorage) AddModAction(ctx context.Context, action *model.ModAction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	action.ID = primitive.NewObjectID()
+	copied := *action
+	s.Storage = append(s.Storage, &copied)
+	return nil
+}
+
+func (s *ModLogStorage) GetModActions(ctx context.Context, filter model.ModLogFilter) ([]*model.ModAction, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	actions := []*model.ModAction{}
+	for idx := len(s.Storage) - 1; idx >= 0; idx-- {
+		action := s.Storage[idx]
+		if filter.Category != "" && action.Category != filter.Category ||
+			len(filter.Categories) > 0 && !contains(filter.Categories, action.Category) ||
+			filter.Moderator != "" && (action.Moderator == nil || action.Moderator.Username != filter.Moderator) ||
+			filter.Action != "" && action.Action != filter.Action ||
+			filter.TargetID != "" && (action.Target == nil || action.Target.ID != filter.TargetID) ||
+			contains(filter.ExcludeActions, action.Action) {
+			continue
+		}
+		if filter.Offset > 0 {
+			filter.Offset--
+			continue
+		}
+		if len(actions) == filter.Limit {
+			break
+		}
+		copied := *action
+		actions = append(actions, &copied)
+	}
+	return actions, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
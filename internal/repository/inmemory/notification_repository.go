@@ -0,0 +1,97 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type NotificationStorage struct {
+	Storage []*model.Notification
+	mu      *sync.RWMutex
+}
+
+func NewNotificationStorage() *NotificationStorage {
+	return &NotificationStorage{
+		Storage: make([]*model.Notification, 0),
+		mu:      new(sync.RWMutex),
+	}
+}
+
+func (s *NotificationStorage) AddNotification(ctx context.Context, notification *model.Notification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	notification.ID = primitive.NewObjectID()
+	s.Storage = append(s.Storage, notification)
+	return nil
+}
+
+func (s *NotificationStorage) GetNotifications(ctx context.Context, userID string, unreadOnly bool, offset int, limit int) ([]*model.Notification, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	notifications := []*model.Notification{}
+	for _, notification := range s.Storage {
+		if notification.UserID == userID && (!unreadOnly || !notification.Read) {
+			copied := *notification
+			notifications = append(notifications, &copied)
+		}
+	}
+	sort.SliceStable(notifications, func(i, j int) bool {
+		return notifications[i].Created > notifications[j].Created
+	})
+
+	if offset >= len(notifications) {
+		return []*model.Notification{}, nil
+	}
+	notifications = notifications[offset:]
+	if limit < len(notifications) {
+		notifications = notifications[:limit]
+	}
+	return notifications, nil
+}
+
+func (s *NotificationStorage) MarkRead(ctx context.Context, userID string, notificationIDs []primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make(map[primitive.ObjectID]struct{}, len(notificationIDs))
+	for _, id := range notificationIDs {
+		ids[id] = struct{}{}
+	}
+	for _, notification := range s.Storage {
+		if _, ok := ids[notification.ID]; ok && notification.UserID == userID {
+			notification.Read = true
+		}
+	}
+	return nil
+}
+
+func (s *NotificationStorage) MarkAllRead(ctx context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, notification := range s.Storage {
+		if notification.UserID == userID {
+			notification.Read = true
+		}
+	}
+	return nil
+}
+
+func (s *NotificationStorage) CountUnread(ctx context.Context, userID string) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var count int64
+	for _, notification := range s.Storage {
+		if notification.UserID == userID && !notification.Read {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -1,8 +1,11 @@
 package inmemory
 
 import (
+	"bytes"
 	"context"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/Totus-Floreo/asperitas-on-go/internal/application/helpers"
 	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
@@ -39,6 +42,20 @@ func (s *PostStorage) GetPostByID(ctx context.Context, postID string) (*model.Po
 	return nil, model.ErrPostNotFound
 }
 
+func (s *PostStorage) GetPostsByIDs(ctx context.Context, postIDs []primitive.ObjectID) ([]*model.Post, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	wanted := make(map[primitive.ObjectID]bool, len(postIDs))
+	for _, postID := range postIDs {
+		wanted[postID] = true
+	}
+	filtredPosts := Filter(s.Storage, func(post *model.Post) bool {
+		return wanted[post.ID]
+	})
+	return filtredPosts, nil
+}
+
 func (s *PostStorage) GetPostsByCategory(ctx context.Context, category string) ([]*model.Post, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -69,29 +86,32 @@ func (s *PostStorage) AddPost(ctx context.Context, post *model.Post) error {
 	return nil
 }
 
-func (s *PostStorage) DeletePost(ctx context.Context, postID string) error {
+func (s *PostStorage) DeletePost(ctx context.Context, postID primitive.ObjectID, deletedBy *model.Author, deletedAt time.Time) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	var postIdx int
-	for idx, post := range s.Storage {
-		if post.ID.Hex() == postID {
-			postIdx = idx
+	for _, post := range s.Storage {
+		if post.ID == postID {
+			post.DeletedAt = &deletedAt
+			post.DeletedBy = deletedBy
+			return nil
 		}
 	}
+	return model.ErrPostNotFound
+}
 
-	if len(s.Storage) <= 1 {
-		s.Storage = []*model.Post{}
-		return nil
-	}
+func (s *PostStorage) RestorePost(ctx context.Context, postID primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	if postIdx != len(s.Storage)-1 {
-		copy(s.Storage[postIdx:], s.Storage[postIdx+1:])
+	for _, post := range s.Storage {
+		if post.ID == postID {
+			post.DeletedAt = nil
+			post.DeletedBy = nil
+			return nil
+		}
 	}
-	s.Storage[len(s.Storage)-1] = nil
-	s.Storage = s.Storage[:len(s.Storage)-1]
-
-	return nil
+	return model.ErrPostNotFound
 }
 
 func (s *PostStorage) AddView(ctx context.Context, post *model.Post) error {
@@ -104,32 +124,65 @@ func (s *PostStorage) AddComment(ctx context.Context, post *model.Post, comment
 	defer post.CM.Unlock()
 
 	comment.ID = primitive.NewObjectID()
+	comment.PostID = post.ID
 	post.Comments = append(post.Comments, comment)
 
 	return nil
 }
 
-func (s *PostStorage) DeleteComment(ctx context.Context, post *model.Post, commentID string) error {
+func (s *PostStorage) DeleteComment(ctx context.Context, post *model.Post, commentID primitive.ObjectID, deletedBy *model.Author, deletedAt time.Time) error {
 	post.CM.Lock()
 	defer post.CM.Unlock()
 
-	if len(post.Comments) == 1 {
-		post.Comments = []*model.Comment{}
-		return nil
+	commentIdx, err := helpers.FindCommentIdx(post, commentID.Hex())
+	if err != nil {
+		return err
 	}
+	post.Comments[commentIdx].DeletedAt = &deletedAt
+	post.Comments[commentIdx].DeletedBy = deletedBy
+
+	return nil
+}
 
-	commentIdx, err := helpers.FindCommentIdx(post, commentID)
+func (s *PostStorage) RestoreComment(ctx context.Context, post *model.Post, commentID primitive.ObjectID) error {
+	post.CM.Lock()
+	defer post.CM.Unlock()
+
+	commentIdx, err := helpers.FindCommentIdx(post, commentID.Hex())
 	if err != nil {
 		return err
 	}
+	post.Comments[commentIdx].DeletedAt = nil
+	post.Comments[commentIdx].DeletedBy = nil
+
+	return nil
+}
 
-	if commentIdx != len(post.Comments)-1 {
-		copy(post.Comments[commentIdx:], post.Comments[commentIdx+1:])
+func (s *PostStorage) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	posts := make([]*model.Post, 0, len(s.Storage))
+	for _, post := range s.Storage {
+		if post.DeletedAt != nil && post.DeletedAt.Before(before) {
+			purged += int64(1 + len(post.Comments))
+			continue
+		}
+		comments := make([]*model.Comment, 0, len(post.Comments))
+		for _, comment := range post.Comments {
+			if comment.DeletedAt != nil && comment.DeletedAt.Before(before) {
+				purged++
+				continue
+			}
+			comments = append(comments, comment)
+		}
+		post.Comments = comments
+		posts = append(posts, post)
 	}
-	post.Comments[len(post.Comments)-1] = nil
-	post.Comments = post.Comments[:len(post.Comments)-1]
+	s.Storage = posts
 
-	return nil
+	return purged, nil
 }
 
 func (s *PostStorage) Vote(ctx context.Context, post *model.Post, vote *model.Vote) error {
@@ -191,6 +244,243 @@ func (s *PostStorage) UpdateScore(ctx context.Context, post *model.Post) error {
 	return nil
 }
 
+func (s *PostStorage) VoteComment(ctx context.Context, post *model.Post, commentID primitive.ObjectID, vote *model.Vote) error {
+	post.CM.Lock()
+	defer post.CM.Unlock()
+
+	commentIdx, err := helpers.FindCommentIdx(post, commentID.Hex())
+	if err != nil {
+		return err
+	}
+	comment := post.Comments[commentIdx]
+	for idx, vt := range comment.Votes {
+		if vt.UserID == vote.UserID {
+			comment.Votes[idx] = vote
+			return nil
+		}
+	}
+	comment.Votes = append(comment.Votes, vote)
+
+	return nil
+}
+
+func (s *PostStorage) UnVoteComment(ctx context.Context, post *model.Post, commentID primitive.ObjectID, userID string) error {
+	post.CM.Lock()
+	defer post.CM.Unlock()
+
+	commentIdx, err := helpers.FindCommentIdx(post, commentID.Hex())
+	if err != nil {
+		return err
+	}
+	comment := post.Comments[commentIdx]
+	votes := make([]*model.Vote, 0, len(comment.Votes))
+	for _, vote := range comment.Votes {
+		if vote.UserID != userID {
+			votes = append(votes, vote)
+		}
+	}
+	comment.Votes = votes
+
+	return nil
+}
+
+func (s *PostStorage) UpdateCommentScore(ctx context.Context, post *model.Post, comment *model.Comment) error {
+	post.CM.Lock()
+	defer post.CM.Unlock()
+
+	commentIdx, err := helpers.FindCommentIdx(post, comment.ID.Hex())
+	if err != nil {
+		return err
+	}
+	var score int64
+	for _, vote := range comment.Votes {
+		score += vote.Score
+	}
+	post.Comments[commentIdx].Score = score
+
+	return nil
+}
+
+func (s *PostStorage) GetCommentsByVoter(ctx context.Context, userID string) ([]*model.Comment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	comments := make([]*model.Comment, 0)
+	for _, post := range s.Storage {
+		for _, comment := range post.Comments {
+			for _, vote := range comment.Votes {
+				if vote.UserID == userID {
+					comments = append(comments, comment)
+					break
+				}
+			}
+		}
+	}
+	return comments, nil
+}
+
+func (s *PostStorage) SetLocked(ctx context.Context, post *model.Post, locked bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	post.Locked = locked
+	return nil
+}
+
+func (s *PostStorage) SetArchived(ctx context.Context, post *model.Post, archived bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	post.Archived = archived
+	post.Unarchived = !archived
+	return nil
+}
+
+func (s *PostStorage) SetLinkStatus(ctx context.Context, post *model.Post, status string, preview *model.LinkPreview) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	post.Status = status
+	post.Preview = preview
+	return nil
+}
+
+func (s *PostStorage) GetPostsByURL(ctx context.Context, canonicalURL string, since time.Time) ([]*model.Post, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	filtredPosts := Filter(s.Storage, func(post *model.Post) bool {
+		created, err := post.CreatedAt()
+		return post.CanonicalUrl == canonicalURL && err == nil && !created.Before(since)
+	})
+	return filtredPosts, nil
+}
+
+func (s *PostStorage) GetPostsByVoter(ctx context.Context, userID string) ([]*model.Post, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	filtredPosts := Filter(s.Storage, func(post *model.Post) bool {
+		for _, vote := range post.Votes {
+			if vote.UserID == userID {
+				return true
+			}
+		}
+		return false
+	})
+	return filtredPosts, nil
+}
+
+func (s *PostStorage) SetStatus(ctx context.Context, post *model.Post, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	post.Status = status
+	post.Filter = nil
+	return nil
+}
+
+func (s *PostStorage) SetCommentStatus(ctx context.Context, post *model.Post, commentID primitive.ObjectID, status string) error {
+	post.CM.Lock()
+	defer post.CM.Unlock()
+
+	for _, comment := range post.Comments {
+		if comment.ID == commentID {
+			comment.Status = status
+			comment.Filter = nil
+			return nil
+		}
+	}
+	return model.ErrCommentNotFound
+}
+
+func (s *PostStorage) PollVote(ctx context.Context, post *model.Post, vote *model.PollVote) error {
+	post.VM.Lock()
+	defer post.VM.Unlock()
+
+	if post.Poll == nil {
+		return model.ErrPostNotPoll
+	}
+
+	for idx, vt := range post.Poll.Votes {
+		if vt.UserID == vote.UserID {
+			post.Poll.Votes[idx] = vote
+			return nil
+		}
+	}
+
+	post.Poll.Votes = append(post.Poll.Votes, vote)
+
+	return nil
+}
+
+func (s *PostStorage) CountPostsByUser(ctx context.Context, userID string) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var count int64
+	for _, post := range s.Storage {
+		if post.Author != nil && post.Author.ID == userID && !post.IsDeleted() && post.IsLive() {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *PostStorage) CountCommentsByUser(ctx context.Context, userID string) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var count int64
+	for _, post := range s.Storage {
+		if post.IsDeleted() {
+			continue
+		}
+		for _, comment := range post.Comments {
+			if comment.Author != nil && comment.Author.ID == userID && !comment.IsDeleted() && comment.IsLive() {
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
+func (s *PostStorage) GetCommentsByUser(ctx context.Context, userName string, filter model.UserCommentsFilter) ([]*model.UserComment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	comments := make([]*model.UserComment, 0)
+	for _, post := range s.Storage {
+		if post.IsDeleted() {
+			continue
+		}
+		for _, comment := range post.Comments {
+			if comment.Author == nil || comment.Author.Username != userName || comment.IsDeleted() {
+				continue
+			}
+			if contains(filter.ExcludeAuthors, comment.Author.ID) || !filter.WithHeld && !comment.IsLive() {
+				continue
+			}
+			comments = append(comments, &model.UserComment{
+				Comment: *comment,
+				Post:    &model.PostLink{ID: post.ID, Title: post.Title, Category: post.Category},
+			})
+		}
+	}
+	sort.Slice(comments, func(i, j int) bool {
+		return bytes.Compare(comments[i].ID[:], comments[j].ID[:]) > 0
+	})
+
+	if filter.Offset >= len(comments) {
+		return []*model.UserComment{}, nil
+	}
+	comments = comments[filter.Offset:]
+	if len(comments) > filter.Limit {
+		comments = comments[:filter.Limit]
+	}
+	return comments, nil
+}
+
 func Filter(posts []*model.Post, fn func(*model.Post) bool) []*model.Post {
 	result := []*model.Post{}
 	for _, post := range posts {
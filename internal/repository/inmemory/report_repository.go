@@ -0,0 +1,113 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ReportStorage struct {
+	Storage []*model.Report
+	mu      *sync.RWMutex
+}
+
+func NewReportStorage() *ReportStorage {
+	return &ReportStorage{
+		Storage: make([]*model.Report, 0),
+		mu:      new(sync.RWMutex),
+	}
+}
+
+func (s *ReportStorage) AddReport(ctx context.Context, report *model.Report) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, open := range s.Storage {
+		if open.Status == model.ReportOpen && open.SameTarget(report) && open.Reporter.ID == report.Reporter.ID {
+			return model.ErrAlreadyReported
+		}
+	}
+
+	report.ID = primitive.NewObjectID()
+	copied := *report
+	s.Storage = append(s.Storage, &copied)
+	return nil
+}
+
+func (s *ReportStorage) GetReportQueue(ctx context.Context, filter model.ReportQueueFilter) ([]*model.ReportGroup, error) {
+	categories := make(map[string]bool, len(filter.Categories))
+	for _, category := range filter.Categories {
+		categories[category] = true
+	}
+	reports := s.getOpenReports(func(report *model.Report) bool {
+		return len(categories) == 0 || categories[report.Category]
+	})
+
+	groups := []*model.ReportGroup{}
+	for _, report := range reports {
+		var group *model.ReportGroup
+		for _, grouped := range groups {
+			if grouped.Reports[0].SameTarget(report) {
+				group = grouped
+				break
+			}
+		}
+		if group == nil {
+			group = &model.ReportGroup{CommentID: report.CommentID}
+			groups = append(groups, group)
+		}
+		group.Reports = append(group.Reports, report)
+		group.Count++
+	}
+	// reports are kept oldest first, so equally reported targets stay in the order they were first reported
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Count > groups[j].Count
+	})
+
+	if filter.Offset >= len(groups) {
+		return []*model.ReportGroup{}, nil
+	}
+	groups = groups[filter.Offset:]
+	if len(groups) > filter.Limit {
+		groups = groups[:filter.Limit]
+	}
+	return groups, nil
+}
+
+func (s *ReportStorage) GetOpenReportsByPost(ctx context.Context, postID primitive.ObjectID) ([]*model.Report, error) {
+	return s.getOpenReports(func(report *model.Report) bool { return report.PostID == postID }), nil
+}
+
+func (s *ReportStorage) getOpenReports(match func(*model.Report) bool) []*model.Report {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	reports := []*model.Report{}
+	for _, report := range s.Storage {
+		if report.Status == model.ReportOpen && match(report) {
+			copied := *report
+			reports = append(reports, &copied)
+		}
+	}
+	return reports
+}
+
+func (s *ReportStorage) CloseReports(ctx context.Context, reportIDs []primitive.ObjectID, resolution *model.ReportResolution) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	closing := make(map[primitive.ObjectID]bool, len(reportIDs))
+	for _, reportID := range reportIDs {
+		closing[reportID] = true
+	}
+	for _, report := range s.Storage {
+		if closing[report.ID] && report.Status == model.ReportOpen {
+			report.Status = model.ReportClosed
+			report.Resolution = resolution
+		}
+	}
+	return nil
+}
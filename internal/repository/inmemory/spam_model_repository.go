@@ -0,0 +1,33 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+)
+
+type SpamModelStorage struct {
+	spamModel *model.SpamModel
+	mu        *sync.Mutex
+}
+
+func NewSpamModelStorage() *SpamModelStorage {
+	return &SpamModelStorage{
+		spamModel: &model.SpamModel{Tokens: make(map[string]*model.TokenCount)},
+		mu:        new(sync.Mutex),
+	}
+}
+
+func (s *SpamModelStorage) LoadSpamModel(ctx context.Context) (*model.SpamModel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.spamModel, nil
+}
+
+func (s *SpamModelStorage) SaveSpamModel(ctx context.Context, spamModel *model.SpamModel) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.spamModel = spamModel
+	return nil
+}
@@ -3,6 +3,7 @@ package inmemory
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
 	uuid "github.com/google/uuid"
@@ -20,14 +21,15 @@ func NewUserStorage() *UserStorage {
 	}
 }
 
-func (s *UserStorage) GetUser(ctx context.Context, userID string) (*model.User, error) {
-	for _, user := range s.Storage {
-		if user.ID == userID {
-			return user, nil
-		}
-	}
+func (s *UserStorage) GetUser(ctx context.Context, username string) (*model.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	return nil, model.ErrUserNotFound
+	user, ok := s.Storage[username]
+	if !ok {
+		return nil, model.ErrUserNotFound
+	}
+	return user, nil
 }
 
 func (s *UserStorage) AddUser(ctx context.Context, user *model.User) error {
@@ -35,6 +37,48 @@ func (s *UserStorage) AddUser(ctx context.Context, user *model.User) error {
 	defer s.mu.Unlock()
 
 	user.ID = uuid.New().String()
+	user.Created = time.Now().UTC()
 	s.Storage[user.Username] = user
 	return nil
 }
+
+func (s *UserStorage) UpdateProfile(ctx context.Context, user *model.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stored := range s.Storage {
+		if stored.ID == user.ID {
+			stored.Bio = user.Bio
+			stored.Avatar = user.Avatar
+			return nil
+		}
+	}
+	return model.ErrUserNotFound
+}
+
+func (s *UserStorage) AddKarma(ctx context.Context, userID string, karma *model.Karma) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, user := range s.Storage {
+		if user.ID == userID {
+			user.PostKarma += karma.Post
+			user.CommentKarma += karma.Comment
+		}
+	}
+	return nil
+}
+
+func (s *UserStorage) ReplaceKarma(ctx context.Context, karma map[string]*model.Karma) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, user := range s.Storage {
+		user.PostKarma, user.CommentKarma = 0, 0
+		if userKarma, ok := karma[user.ID]; ok {
+			user.PostKarma = userKarma.Post
+			user.CommentKarma = userKarma.Comment
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package mongo_repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type BanStorage struct {
+	Storage model.ICollection
+}
+
+func NewBanStorage(client model.IClient) *BanStorage {
+	return &BanStorage{
+		Storage: client.Database("asperitas").Collection("bans"),
+	}
+}
+
+// AddBan replaces the user's ban of the same scope, if any, in a single upsert. A replaced ban keeps its ID.
+func (s *BanStorage) AddBan(ctx context.Context, ban *model.Ban) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "user.id", Value: ban.User.ID},
+		{Key: "category", Value: ban.Category},
+		{Key: "shadow", Value: ban.Shadow},
+	}
+
+	ban.ID = primitive.NilObjectID
+	result, err := s.Storage.ReplaceOne(ctx, filter, ban, options.Replace().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+	if id, ok := result.UpsertedID.(primitive.ObjectID); ok {
+		ban.ID = id
+		return nil
+	}
+
+	var stored struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := s.Storage.FindOne(ctx, filter, options.FindOne().SetProjection(bson.D{{Key: "_id", Value: 1}})).Decode(&stored); err != nil {
+		return err
+	}
+	ban.ID = stored.ID
+	return nil
+}
+
+func (s *BanStorage) RemoveBan(ctx context.Context, userID string, category string, shadow bool) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "user.id", Value: userID},
+		{Key: "category", Value: category},
+		{Key: "shadow", Value: shadow},
+	}
+
+	result, err := s.Storage.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return model.ErrBanNotFound
+	}
+	return nil
+}
+
+func (s *BanStorage) GetBans(ctx context.Context) ([]*model.Ban, error) {
+	return s.getBans(ctx, bson.D{})
+}
+
+func (s *BanStorage) GetUserBans(ctx context.Context, userID string) ([]*model.Ban, error) {
+	return s.getBans(ctx, bson.D{{Key: "user.id", Value: userID}})
+}
+
+func (s *BanStorage) GetShadowBans(ctx context.Context) ([]*model.Ban, error) {
+	return s.getBans(ctx, bson.D{{Key: "shadow", Value: true}})
+}
+
+func (s *BanStorage) getBans(ctx context.Context, match bson.D) ([]*model.Ban, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: match}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "created", Value: -1}, {Key: "_id", Value: -1}}}},
+	}
+
+	cursor, err := s.Storage.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	bans := []*model.Ban{}
+	if err := cursor.All(ctx, &bans); err != nil {
+		return nil, err
+	}
+	return bans, nil
+}
@@ -0,0 +1,101 @@
+package mongo_repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type BlockStorage struct {
+	Storage model.ICollection
+}
+
+func NewBlockStorage(client model.IClient) *BlockStorage {
+	return &BlockStorage{
+		Storage: client.Database("asperitas").Collection("blocks"),
+	}
+}
+
+// AddBlock creates the block or changes the type of an existing one.
+func (s *BlockStorage) AddBlock(ctx context.Context, block *model.Block) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "user", Value: block.UserID},
+		{Key: "target.id", Value: block.Target.ID},
+	}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{{Key: "type", Value: block.Type}, {Key: "target", Value: block.Target}}},
+		{Key: "$setOnInsert", Value: bson.D{{Key: "created", Value: block.Created}}},
+	}
+
+	_, err := s.Storage.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (s *BlockStorage) RemoveBlock(ctx context.Context, userID string, targetID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "user", Value: userID},
+		{Key: "target.id", Value: targetID},
+	}
+
+	_, err := s.Storage.DeleteOne(ctx, filter)
+	return err
+}
+
+func (s *BlockStorage) GetBlocks(ctx context.Context, userID string) ([]*model.Block, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.D{{Key: "user", Value: userID}}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "created", Value: -1}}}},
+	}
+
+	cursor, err := s.Storage.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	blocks := []*model.Block{}
+	if err := cursor.All(ctx, &blocks); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// IsBlocked reports whether userID has blocked, not just muted, targetID.
+func (s *BlockStorage) IsBlocked(ctx context.Context, userID string, targetID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.D{
+			{Key: "user", Value: userID},
+			{Key: "target.id", Value: targetID},
+			{Key: "type", Value: model.BlockBlock},
+		}}},
+		bson.D{{Key: "$limit", Value: 1}},
+	}
+
+	cursor, err := s.Storage.Aggregate(ctx, pipeline)
+	if err != nil {
+		return false, err
+	}
+	defer cursor.Close(ctx)
+
+	found := cursor.TryNext(ctx)
+	if cursor.Err() != nil {
+		return false, cursor.Err()
+	}
+	return found, nil
+}
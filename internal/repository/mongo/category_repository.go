@@ -0,0 +1,102 @@
+package mongo_repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CategoryStorage struct {
+	Storage model.ICollection
+}
+
+func NewCategoryStorage(client model.IClient) *CategoryStorage {
+	return &CategoryStorage{
+		Storage: client.Database("asperitas").Collection("categories"),
+	}
+}
+
+func (s *CategoryStorage) GetCategories(ctx context.Context) ([]*model.Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}
+
+	cursor, err := s.Storage.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	categories := []*model.Category{}
+	if err := cursor.All(ctx, &categories); err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (s *CategoryStorage) GetCategory(ctx context.Context, name string) (*model.Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var category *model.Category
+	err := s.Storage.FindOne(ctx, bson.D{{Key: "_id", Value: name}}).Decode(&category)
+	if err == mongo.ErrNoDocuments {
+		return nil, model.ErrCategoryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// SetRules replaces the rules of the category, setting it up if it was not yet.
+func (s *CategoryStorage) SetRules(ctx context.Context, name string, rules []string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "rules", Value: rules}}}}
+	_, err := s.Storage.UpdateOne(ctx, bson.D{{Key: "_id", Value: name}}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// AddModerator makes the user a moderator of the category, setting it up if it was not yet.
+func (s *CategoryStorage) AddModerator(ctx context.Context, name string, moderator *model.Author) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "_id", Value: name},
+		{Key: "moderators.id", Value: bson.D{{Key: "$ne", Value: moderator.ID}}},
+	}
+	update := bson.D{{Key: "$push", Value: bson.D{{Key: "moderators", Value: moderator}}}}
+
+	_, err := s.Storage.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		// the category exists and the user already moderates it
+		return nil
+	}
+	return err
+}
+
+func (s *CategoryStorage) RemoveModerator(ctx context.Context, name string, userID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	update := bson.D{{Key: "$pull", Value: bson.D{{Key: "moderators", Value: bson.D{{Key: "id", Value: userID}}}}}}
+
+	result, err := s.Storage.UpdateOne(ctx, bson.D{{Key: "_id", Value: name}}, update)
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount == 0 {
+		return model.ErrModeratorNotFound
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package mongo_repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type MessageStorage struct {
+	Conversations model.ICollection
+	Messages      model.ICollection
+}
+
+func NewMessageStorage(client model.IClient) *MessageStorage {
+	database := client.Database("asperitas")
+	return &MessageStorage{
+		Conversations: database.Collection("conversations"),
+		Messages:      database.Collection("messages"),
+	}
+}
+
+func (s *MessageStorage) AddConversation(ctx context.Context, conversation *model.Conversation) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	conversation.ID = primitive.NewObjectID()
+	_, err := s.Conversations.InsertOne(ctx, conversation)
+	if mongo.IsDuplicateKeyError(err) {
+		// the members started the conversation concurrently
+		return model.ErrConversationExist
+	}
+	return err
+}
+
+func (s *MessageStorage) GetConversation(ctx context.Context, conversationID primitive.ObjectID) (*model.Conversation, error) {
+	return s.findConversation(ctx, bson.D{{Key: "_id", Value: conversationID}})
+}
+
+func (s *MessageStorage) GetConversationByKey(ctx context.Context, key string) (*model.Conversation, error) {
+	return s.findConversation(ctx, bson.D{{Key: "key", Value: key}})
+}
+
+func (s *MessageStorage) GetConversations(ctx context.Context, userID string, offset int, limit int) ([]*model.Conversation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.D{{Key: "members", Value: userID}}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "updated", Value: -1}, {Key: "_id", Value: -1}}}},
+		bson.D{{Key: "$skip", Value: offset}},
+		bson.D{{Key: "$limit", Value: limit}},
+	}
+
+	cursor, err := s.Conversations.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	conversations := []*model.Conversation{}
+	if err := cursor.All(ctx, &conversations); err != nil {
+		return nil, err
+	}
+	return conversations, nil
+}
+
+// AddMessage stores the message and bumps the unread counters of everyone but the author.
+func (s *MessageStorage) AddMessage(ctx context.Context, conversation *model.Conversation, message *model.Message) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	message.ID = primitive.NewObjectID()
+	if _, err := s.Messages.InsertOne(ctx, message); err != nil {
+		return err
+	}
+
+	increments := bson.D{}
+	for _, member := range conversation.Members {
+		if member != message.Author.ID {
+			increments = append(increments, bson.E{Key: "unread." + member, Value: 1})
+		}
+	}
+	update := bson.D{
+		{
+			Key: "$set",
+			Value: bson.D{
+				{Key: "lastmessage", Value: message},
+				{Key: "updated", Value: message.Created},
+				{Key: "lastread." + message.Author.ID, Value: message.ID},
+			},
+		},
+	}
+	if len(increments) > 0 {
+		update = append(update, bson.E{Key: "$inc", Value: increments})
+	}
+
+	result, err := s.Conversations.UpdateByID(ctx, conversation.ID, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return model.ErrConversationNotFound
+	}
+	return nil
+}
+
+func (s *MessageStorage) GetMessages(ctx context.Context, conversationID primitive.ObjectID, offset int, limit int) ([]*model.Message, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.D{{Key: "conversation", Value: conversationID}}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "_id", Value: -1}}}},
+		bson.D{{Key: "$skip", Value: offset}},
+		bson.D{{Key: "$limit", Value: limit}},
+	}
+
+	cursor, err := s.Messages.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	messages := []*model.Message{}
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (s *MessageStorage) MarkConversationRead(ctx context.Context, conversation *model.Conversation, userID string, messageID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	update := bson.D{
+		{
+			Key: "$set",
+			Value: bson.D{
+				{Key: "lastread." + userID, Value: messageID},
+				{Key: "unread." + userID, Value: 0},
+			},
+		},
+	}
+
+	result, err := s.Conversations.UpdateByID(ctx, conversation.ID, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return model.ErrConversationNotFound
+	}
+	return nil
+}
+
+func (s *MessageStorage) CountUnreadMessages(ctx context.Context, userID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.D{{Key: "members", Value: userID}}}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: nil},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: "$unread." + userID}}},
+		}}},
+	}
+
+	cursor, err := s.Conversations.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var result struct {
+		Count int64 `bson:"count"`
+	}
+	if cursor.TryNext(ctx) {
+		if err := cursor.Decode(&result); err != nil {
+			return 0, err
+		}
+	}
+	if cursor.Err() != nil {
+		return 0, cursor.Err()
+	}
+	return result.Count, nil
+}
+
+func (s *MessageStorage) findConversation(ctx context.Context, filter bson.D) (*model.Conversation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	cursor, err := s.Conversations.Aggregate(ctx, mongo.Pipeline{
+		bson.D{{Key: "$match", Value: filter}},
+		bson.D{{Key: "$limit", Value: 1}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var conversation *model.Conversation
+	if cursor.TryNext(ctx) {
+		if err := cursor.Decode(&conversation); err != nil {
+			return nil, err
+		}
+	}
+	if cursor.Err() != nil {
+		return nil, cursor.Err()
+	}
+	if conversation == nil {
+		return nil, model.ErrConversationNotFound
+	}
+	return conversation, nil
+}
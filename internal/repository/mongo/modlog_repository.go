@@ -0,0 +1,78 @@
+package mongo_repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type ModLogStorage struct {
+	Storage model.ICollection
+}
+
+func NewModLogStorage(client model.IClient) *ModLogStorage {
+	return &ModLogStorage{
+		Storage: client.Database("asperitas").Collection("modlog"),
+	}
+}
+
+func (s *ModLogStorage) AddModAction(ctx context.Context, action *model.ModAction) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	action.ID = primitive.NewObjectID()
+	_, err := s.Storage.InsertOne(ctx, action)
+	return err
+}
+
+func (s *ModLogStorage) GetModActions(ctx context.Context, filter model.ModLogFilter) ([]*model.ModAction, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	match := bson.D{}
+	if filter.Category != "" {
+		match = append(match, bson.E{Key: "category", Value: filter.Category})
+	}
+	if len(filter.Categories) > 0 {
+		match = append(match, bson.E{Key: "category", Value: bson.D{{Key: "$in", Value: filter.Categories}}})
+	}
+	if filter.Moderator != "" {
+		match = append(match, bson.E{Key: "moderator.username", Value: filter.Moderator})
+	}
+	if filter.TargetID != "" {
+		match = append(match, bson.E{Key: "target.id", Value: filter.TargetID})
+	}
+	action := bson.D{}
+	if filter.Action != "" {
+		action = append(action, bson.E{Key: "$eq", Value: filter.Action})
+	}
+	if len(filter.ExcludeActions) > 0 {
+		action = append(action, bson.E{Key: "$nin", Value: filter.ExcludeActions})
+	}
+	if len(action) > 0 {
+		match = append(match, bson.E{Key: "action", Value: action})
+	}
+
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: match}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "_id", Value: -1}}}},
+		bson.D{{Key: "$skip", Value: filter.Offset}},
+		bson.D{{Key: "$limit", Value: filter.Limit}},
+	}
+
+	cursor, err := s.Storage.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	actions := []*model.ModAction{}
+	if err := cursor.All(ctx, &actions); err != nil {
+		return nil, err
+	}
+	return actions, nil
+}
@@ -0,0 +1,116 @@
+package mongo_repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type NotificationStorage struct {
+	Storage model.ICollection
+}
+
+func NewNotificationStorage(client model.IClient) *NotificationStorage {
+	return &NotificationStorage{
+		Storage: client.Database("asperitas").Collection("notifications"),
+	}
+}
+
+func (s *NotificationStorage) AddNotification(ctx context.Context, notification *model.Notification) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	notification.ID = primitive.NewObjectID()
+	_, err := s.Storage.InsertOne(ctx, notification)
+	return err
+}
+
+func (s *NotificationStorage) GetNotifications(ctx context.Context, userID string, unreadOnly bool, offset int, limit int) ([]*model.Notification, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: userFilter(userID, unreadOnly)}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "created", Value: -1}, {Key: "_id", Value: -1}}}},
+		bson.D{{Key: "$skip", Value: offset}},
+		bson.D{{Key: "$limit", Value: limit}},
+	}
+
+	cursor, err := s.Storage.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	notifications := []*model.Notification{}
+	if err := cursor.All(ctx, &notifications); err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (s *NotificationStorage) MarkRead(ctx context.Context, userID string, notificationIDs []primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "user", Value: userID},
+		{Key: "_id", Value: bson.D{{Key: "$in", Value: notificationIDs}}},
+	}
+
+	_, err := s.Storage.UpdateMany(ctx, filter, markRead())
+	return err
+}
+
+func (s *NotificationStorage) MarkAllRead(ctx context.Context, userID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := s.Storage.UpdateMany(ctx, userFilter(userID, true), markRead())
+	return err
+}
+
+func (s *NotificationStorage) CountUnread(ctx context.Context, userID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: userFilter(userID, true)}},
+		bson.D{{Key: "$count", Value: "count"}},
+	}
+
+	cursor, err := s.Storage.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var result struct {
+		Count int64 `bson:"count"`
+	}
+	if cursor.TryNext(ctx) {
+		if err := cursor.Decode(&result); err != nil {
+			return 0, err
+		}
+	}
+	if cursor.Err() != nil {
+		return 0, cursor.Err()
+	}
+	return result.Count, nil
+}
+
+func userFilter(userID string, unreadOnly bool) bson.D {
+	filter := bson.D{{Key: "user", Value: userID}}
+	if unreadOnly {
+		filter = append(filter, bson.E{Key: "read", Value: false})
+	}
+	return filter
+}
+
+func markRead() bson.D {
+	return bson.D{{Key: "$set", Value: bson.D{{Key: "read", Value: true}}}}
+}
@@ -2,6 +2,7 @@ package mongo_repository
 
 import (
 	"context"
+	"encoding/binary"
 	"sync"
 	"time"
 
@@ -101,6 +102,30 @@ func (s *PostStorage) GetPostByID(ctx context.Context, postID primitive.ObjectID
 
 }
 
+func (s *PostStorage) GetPostsByIDs(ctx context.Context, postIDs []primitive.ObjectID) ([]*model.Post, error) {
+	client := s.ReadersPool.GetConnection()
+	defer s.ReadersPool.ReleaseConnection(client)
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	collection := client.Database("asperitas").Collection("posts")
+
+	match := bson.D{{Key: "$match", Value: bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: postIDs}}}}}}
+
+	cursor, err := collection.Aggregate(ctx, mongo.Pipeline{match, GetLookup()})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	posts := make([]*model.Post, 0, len(postIDs))
+	if err := cursor.All(ctx, &posts); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
 func (s *PostStorage) GetPostsByCategory(ctx context.Context, category string) ([]*model.Post, error) {
 	client := s.ReadersPool.GetConnection()
 	defer s.ReadersPool.ReleaseConnection(client)
@@ -185,59 +210,45 @@ func (s *PostStorage) AddPost(ctx context.Context, post *model.Post) error {
 	return nil
 }
 
-func (s *PostStorage) DeletePost(ctx context.Context, postID primitive.ObjectID) error {
+// DeletePost soft-deletes the post, it keeps its comments until it is restored or purged.
+func (s *PostStorage) DeletePost(ctx context.Context, postID primitive.ObjectID, deletedBy *model.Author, deletedAt time.Time) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	filter := bson.D{
-		{
-			Key:   "_id",
-			Value: postID,
-		},
+	update := bson.D{
+		{Key: "$set", Value: bson.D{{Key: "deletedat", Value: deletedAt}, {Key: "deletedby", Value: deletedBy}}},
 	}
 
-	post, err := s.GetPostByID(ctx, postID)
+	postResult, err := s.PostStorage.UpdateByID(ctx, postID, update)
 	if err != nil {
 		return err
 	}
-
-	commentObjectIDs := make([]primitive.ObjectID, 0)
-	for _, comment := range post.Comments {
-		commentObjectIDs = append(commentObjectIDs, comment.ID)
-	}
-
-	result := s.PostStorage.FindOneAndDelete(ctx, filter)
-	if result.Err() == mongo.ErrNoDocuments {
+	if postResult.MatchedCount == 0 {
 		return model.ErrPostNotFound
 	}
-	if result.Err() != nil {
-		return result.Err()
-	}
 
-	if len(post.Comments) == 0 {
-		return nil
-	}
+	return nil
+}
 
-	delete := bson.D{
-		{
-			Key: "_id",
-			Value: bson.D{
-				{
-					Key:   "$in",
-					Value: commentObjectIDs,
-				},
-			},
-		},
+func (s *PostStorage) RestorePost(ctx context.Context, postID primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	update := bson.D{
+		{Key: "$unset", Value: bson.D{{Key: "deletedat", Value: ""}, {Key: "deletedby", Value: ""}}},
 	}
 
-	commentResult, err := s.CommentStorage.DeleteMany(ctx, delete)
+	postResult, err := s.PostStorage.UpdateByID(ctx, postID, update)
 	if err != nil {
 		return err
 	}
-	if commentResult.DeletedCount == 0 {
+	if postResult.MatchedCount == 0 {
 		return model.ErrPostNotFound
 	}
 
@@ -281,6 +292,7 @@ func (s *PostStorage) AddComment(ctx context.Context, post *model.Post, comment
 	defer cancel()
 
 	comment.ID = primitive.NewObjectID()
+	comment.PostID = post.ID
 	_, err := s.CommentStorage.InsertOne(ctx, comment)
 	if err != nil {
 		return err
@@ -309,54 +321,176 @@ func (s *PostStorage) AddComment(ctx context.Context, post *model.Post, comment
 	return nil
 }
 
-func (s *PostStorage) DeleteComment(ctx context.Context, post *model.Post, commentID primitive.ObjectID) error {
+// DeleteComment soft-deletes a comment of the post.
+func (s *PostStorage) DeleteComment(ctx context.Context, post *model.Post, commentID primitive.ObjectID, deletedBy *model.Author, deletedAt time.Time) error {
+	update := bson.D{
+		{Key: "$set", Value: bson.D{{Key: "deletedat", Value: deletedAt}, {Key: "deletedby", Value: deletedBy}}},
+	}
+	return s.updateComment(ctx, post, commentID, update)
+}
+
+func (s *PostStorage) RestoreComment(ctx context.Context, post *model.Post, commentID primitive.ObjectID) error {
+	update := bson.D{
+		{Key: "$unset", Value: bson.D{{Key: "deletedat", Value: ""}, {Key: "deletedby", Value: ""}}},
+	}
+	return s.updateComment(ctx, post, commentID, update)
+}
+
+func (s *PostStorage) updateComment(ctx context.Context, post *model.Post, commentID primitive.ObjectID, update interface{}) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	update := bson.D{
-		{
-			Key: "$pull",
-			Value: bson.D{
-				{
-					Key: "comments",
-					Value: bson.D{
-						{
-							Key:   "$eq",
-							Value: commentID,
-						},
-					},
-				},
-			},
-		},
+	filter := bson.D{
+		{Key: "_id", Value: commentID},
+		{Key: "post", Value: post.ID},
 	}
 
-	result, err := s.PostStorage.UpdateByID(ctx, post.ID, update)
+	commentResult, err := s.CommentStorage.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return err
 	}
-	if result.MatchedCount == 0 {
-		return model.ErrPostNotFound
+	if commentResult.MatchedCount == 0 {
+		return model.ErrCommentNotFound
 	}
 
-	filter := bson.D{
-		{
-			Key:   "_id",
-			Value: commentID,
-		},
+	return nil
+}
+
+// purgeBatchSize is how many posts or comments PurgeDeleted removes at a time.
+const purgeBatchSize = 500
+
+// PurgeDeleted removes the posts soft-deleted before the given time together with all their comments,
+// then the comments soft-deleted before it. It returns how many posts and comments it removed.
+// It works in batches without the storage lock, content restored meanwhile is left alone.
+func (s *PostStorage) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	for _, purge := range []func(context.Context, time.Time) (int64, int, error){s.purgePosts, s.purgeComments} {
+		for {
+			count, batch, err := purge(ctx, before)
+			purged += count
+			if err != nil {
+				return purged, err
+			}
+			if batch < purgeBatchSize {
+				break
+			}
+		}
 	}
+	return purged, nil
+}
 
-	commentResult, err := s.CommentStorage.DeleteOne(ctx, filter)
+// purgePosts removes a batch of expired posts and the comments of those it removed, it returns how many
+// posts and comments it removed and how many posts it found expired.
+func (s *PostStorage) purgePosts(ctx context.Context, before time.Time) (int64, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	postIDs, err := s.collectIDs(ctx, s.PostStorage, expiredBatch(before))
+	if err != nil || len(postIDs) == 0 {
+		return 0, 0, err
+	}
+	postResult, err := s.PostStorage.DeleteMany(ctx, expiredAmong(postIDs, before))
 	if err != nil {
-		return err
+		return 0, 0, err
 	}
-	if commentResult.DeletedCount == 0 {
-		return model.ErrCommentNotFound
+	gone, err := s.goneAmong(ctx, s.PostStorage, postIDs)
+	if err != nil {
+		return postResult.DeletedCount, 0, err
+	}
+	commentResult, err := s.CommentStorage.DeleteMany(ctx, bson.D{{Key: "post", Value: bson.D{{Key: "$in", Value: gone}}}})
+	if err != nil {
+		return postResult.DeletedCount, 0, err
 	}
+	return postResult.DeletedCount + commentResult.DeletedCount, len(postIDs), nil
+}
 
-	return nil
+// purgeComments removes a batch of expired comments and takes those it removed off their posts, it returns
+// how many comments it removed and how many it found expired.
+func (s *PostStorage) purgeComments(ctx context.Context, before time.Time) (int64, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	commentIDs, err := s.collectIDs(ctx, s.CommentStorage, expiredBatch(before))
+	if err != nil || len(commentIDs) == 0 {
+		return 0, 0, err
+	}
+	commentResult, err := s.CommentStorage.DeleteMany(ctx, expiredAmong(commentIDs, before))
+	if err != nil {
+		return 0, 0, err
+	}
+	gone, err := s.goneAmong(ctx, s.CommentStorage, commentIDs)
+	if err != nil {
+		return commentResult.DeletedCount, 0, err
+	}
+	in := bson.D{{Key: "$in", Value: gone}}
+	pull := bson.D{{Key: "$pull", Value: bson.D{{Key: "comments", Value: in}}}}
+	if _, err := s.PostStorage.UpdateMany(ctx, bson.D{{Key: "comments", Value: in}}, pull); err != nil {
+		return commentResult.DeletedCount, 0, err
+	}
+	return commentResult.DeletedCount, len(commentIDs), nil
+}
+
+// goneAmong returns the IDs no longer found in the collection, those restored before they were removed are kept.
+func (s *PostStorage) goneAmong(ctx context.Context, collection model.ICollection, ids []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	kept, err := s.collectIDs(ctx, collection, mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}}}},
+		bson.D{{Key: "$project", Value: bson.D{{Key: "_id", Value: 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[primitive.ObjectID]bool, len(kept))
+	for _, id := range kept {
+		found[id] = true
+	}
+	gone := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		if !found[id] {
+			gone = append(gone, id)
+		}
+	}
+	return gone, nil
+}
+
+// expiredBatch finds the next batch of documents soft-deleted before the given time.
+func expiredBatch(before time.Time) mongo.Pipeline {
+	return mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.D{{Key: "deletedat", Value: bson.D{{Key: "$lt", Value: before}}}}}},
+		bson.D{{Key: "$limit", Value: purgeBatchSize}},
+		bson.D{{Key: "$project", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}
+}
+
+// expiredAmong matches the documents among ids that are still soft-deleted before the given time.
+func expiredAmong(ids []primitive.ObjectID, before time.Time) bson.D {
+	return bson.D{
+		{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}},
+		{Key: "deletedat", Value: bson.D{{Key: "$lt", Value: before}}},
+	}
+}
+
+func (s *PostStorage) collectIDs(ctx context.Context, collection model.ICollection, pipeline mongo.Pipeline) ([]primitive.ObjectID, error) {
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(docs))
+	for _, doc := range docs {
+		ids = append(ids, doc.ID)
+	}
+	return ids, nil
 }
 
 func (s *PostStorage) Vote(ctx context.Context, post *model.Post, vote *model.Vote) error {
@@ -555,6 +689,401 @@ func (s *PostStorage) UpdateScore(ctx context.Context, post *model.Post) error {
 	return nil
 }
 
+// VoteComment replaces the user's vote on the comment, if any, in a single update.
+func (s *PostStorage) VoteComment(ctx context.Context, post *model.Post, commentID primitive.ObjectID, vote *model.Vote) error {
+	return s.updateComment(ctx, post, commentID, replaceVoteUpdate("votes", vote.UserID, vote))
+}
+
+func (s *PostStorage) UnVoteComment(ctx context.Context, post *model.Post, commentID primitive.ObjectID, userID string) error {
+	update := bson.D{
+		{Key: "$pull", Value: bson.D{{Key: "votes", Value: bson.D{{Key: "user", Value: userID}}}}},
+	}
+	return s.updateComment(ctx, post, commentID, update)
+}
+
+func (s *PostStorage) UpdateCommentScore(ctx context.Context, post *model.Post, comment *model.Comment) error {
+	var score int64
+	for _, vote := range comment.Votes {
+		score += vote.Score
+	}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{{Key: "score", Value: score}}},
+	}
+	return s.updateComment(ctx, post, comment.ID, update)
+}
+
+func (s *PostStorage) SetLocked(ctx context.Context, post *model.Post, locked bool) error {
+	return s.setFlag(ctx, post, "locked", locked)
+}
+
+// SetArchived archives or unarchives the post, unarchiving also keeps it from being archived by age again.
+func (s *PostStorage) SetArchived(ctx context.Context, post *model.Post, archived bool) error {
+	return s.setFields(ctx, post, bson.D{{Key: "archived", Value: archived}, {Key: "unarchived", Value: !archived}})
+}
+
+func (s *PostStorage) SetLinkStatus(ctx context.Context, post *model.Post, status string, preview *model.LinkPreview) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	update := bson.D{
+		{
+			Key: "$set",
+			Value: bson.D{
+				{Key: "status", Value: status},
+				{Key: "preview", Value: preview},
+			},
+		},
+	}
+
+	postResult, err := s.PostStorage.UpdateByID(ctx, post.ID, update)
+	if err != nil {
+		return err
+	}
+	if postResult.MatchedCount == 0 {
+		return model.ErrPostNotFound
+	}
+
+	return nil
+}
+
+// SetStatus changes the status of the post and drops the filter verdict that held it.
+func (s *PostStorage) SetStatus(ctx context.Context, post *model.Post, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	update := bson.D{
+		{Key: "$set", Value: bson.D{{Key: "status", Value: status}}},
+		{Key: "$unset", Value: bson.D{{Key: "filter", Value: ""}}},
+	}
+
+	postResult, err := s.PostStorage.UpdateByID(ctx, post.ID, update)
+	if err != nil {
+		return err
+	}
+	if postResult.MatchedCount == 0 {
+		return model.ErrPostNotFound
+	}
+
+	return nil
+}
+
+// SetCommentStatus changes the status of a comment of the post and drops the filter verdict that held it.
+func (s *PostStorage) SetCommentStatus(ctx context.Context, post *model.Post, commentID primitive.ObjectID, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "_id", Value: commentID},
+		{Key: "post", Value: post.ID},
+	}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{{Key: "status", Value: status}}},
+		{Key: "$unset", Value: bson.D{{Key: "filter", Value: ""}}},
+	}
+
+	commentResult, err := s.CommentStorage.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if commentResult.MatchedCount == 0 {
+		return model.ErrCommentNotFound
+	}
+
+	return nil
+}
+
+// PollVote replaces the user's vote on the poll, if any, in a single update so that concurrent votes of
+// the same user cannot both be kept.
+func (s *PostStorage) PollVote(ctx context.Context, post *model.Post, vote *model.PollVote) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	update := pollVoteUpdate(vote)
+
+	result, err := s.PostStorage.UpdateByID(ctx, post.ID, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return model.ErrPostNotFound
+	}
+
+	return nil
+}
+
+// pollVoteUpdate is an update pipeline that drops the user's vote from poll.votes and appends the new one.
+func pollVoteUpdate(vote *model.PollVote) mongo.Pipeline {
+	return replaceVoteUpdate("poll.votes", vote.UserID, vote)
+}
+
+// replaceVoteUpdate is an update pipeline that drops the user's vote from the votes under key and appends the new one.
+func replaceVoteUpdate(key string, userID string, vote interface{}) mongo.Pipeline {
+	otherVotes := bson.D{{Key: "$filter", Value: bson.D{
+		{Key: "input", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$" + key, bson.A{}}}}},
+		{Key: "cond", Value: bson.D{{Key: "$ne", Value: bson.A{"$$this.user", userID}}}},
+	}}}
+
+	return mongo.Pipeline{
+		bson.D{{Key: "$set", Value: bson.D{{
+			Key: key,
+			Value: bson.D{{Key: "$concatArrays", Value: bson.A{
+				otherVotes,
+				bson.D{{Key: "$literal", Value: bson.A{vote}}},
+			}}},
+		}}}},
+	}
+}
+
+func (s *PostStorage) setFlag(ctx context.Context, post *model.Post, key string, value bool) error {
+	return s.setFields(ctx, post, bson.D{{Key: key, Value: value}})
+}
+
+func (s *PostStorage) setFields(ctx context.Context, post *model.Post, fields bson.D) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	update := bson.D{
+		{
+			Key:   "$set",
+			Value: fields,
+		},
+	}
+
+	postResult, err := s.PostStorage.UpdateByID(ctx, post.ID, update)
+	if err != nil {
+		return err
+	}
+	if postResult.MatchedCount == 0 {
+		return model.ErrPostNotFound
+	}
+
+	return nil
+}
+
+// CountPostsByUser counts the live posts of the user.
+func (s *PostStorage) CountPostsByUser(ctx context.Context, userID string) (int64, error) {
+	return s.countByAuthor(ctx, "posts", userID)
+}
+
+// CountCommentsByUser counts the live comments of the user, leaving out those of deleted posts like GetCommentsByUser.
+func (s *PostStorage) CountCommentsByUser(ctx context.Context, userID string) (int64, error) {
+	return s.countByAuthor(ctx, "comments", userID,
+		bson.D{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: "posts"},
+			{Key: "localField", Value: "post"},
+			{Key: "foreignField", Value: "_id"},
+			{Key: "as", Value: "postlink"},
+		}}},
+		bson.D{{Key: "$unwind", Value: "$postlink"}},
+		bson.D{{Key: "$match", Value: bson.D{notDeleted("postlink.deletedat")}}},
+	)
+}
+
+// GetCommentsByUser returns the newest comments of the user together with the posts they belong to.
+func (s *PostStorage) GetCommentsByUser(ctx context.Context, userName string, filter model.UserCommentsFilter) ([]*model.UserComment, error) {
+	client := s.ReadersPool.GetConnection()
+	defer s.ReadersPool.ReleaseConnection(client)
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	collection := client.Database("asperitas").Collection("comments")
+
+	match := bson.D{{Key: "author.username", Value: userName}, notDeleted("deletedat")}
+	if len(filter.ExcludeAuthors) > 0 {
+		match = append(match, bson.E{Key: "author.id", Value: bson.D{{Key: "$nin", Value: filter.ExcludeAuthors}}})
+	}
+	if !filter.WithHeld {
+		match = append(match, live())
+	}
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: match}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "_id", Value: -1}}}},
+		bson.D{
+			{
+				Key: "$lookup",
+				Value: bson.D{
+					{Key: "from", Value: "posts"},
+					{Key: "localField", Value: "post"},
+					{Key: "foreignField", Value: "_id"},
+					{Key: "as", Value: "postlink"},
+				},
+			},
+		},
+		bson.D{{Key: "$unwind", Value: "$postlink"}},
+		// the comments of deleted posts go with them, so they are dropped before paging
+		bson.D{{Key: "$match", Value: bson.D{notDeleted("postlink.deletedat")}}},
+		bson.D{{Key: "$skip", Value: filter.Offset}},
+		bson.D{{Key: "$limit", Value: filter.Limit}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	comments := make([]*model.UserComment, 0)
+	if err := cursor.All(ctx, &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// GetPostsByURL returns the posts linking to the canonical URL created since the given time, newest first.
+// A zero time returns them all.
+func (s *PostStorage) GetPostsByURL(ctx context.Context, canonicalURL string, since time.Time) ([]*model.Post, error) {
+	client := s.ReadersPool.GetConnection()
+	defer s.ReadersPool.ReleaseConnection(client)
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	collection := client.Database("asperitas").Collection("posts")
+
+	match := bson.D{{Key: "canonicalurl", Value: canonicalURL}}
+	if !since.IsZero() {
+		match = append(match, bson.E{Key: "_id", Value: bson.D{{Key: "$gte", Value: minObjectID(since)}}})
+	}
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: match}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "_id", Value: -1}}}},
+		GetLookup(),
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	posts := make([]*model.Post, 0)
+	if err := cursor.All(ctx, &posts); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+func (s *PostStorage) GetPostsByVoter(ctx context.Context, userID string) ([]*model.Post, error) {
+	client := s.ReadersPool.GetConnection()
+	defer s.ReadersPool.ReleaseConnection(client)
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	collection := client.Database("asperitas").Collection("posts")
+
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.D{{Key: "votes.user", Value: userID}}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	posts := make([]*model.Post, 0)
+	if err := cursor.All(ctx, &posts); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+func (s *PostStorage) GetCommentsByVoter(ctx context.Context, userID string) ([]*model.Comment, error) {
+	client := s.ReadersPool.GetConnection()
+	defer s.ReadersPool.ReleaseConnection(client)
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	collection := client.Database("asperitas").Collection("comments")
+
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.D{{Key: "votes.user", Value: userID}}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	comments := make([]*model.Comment, 0)
+	if err := cursor.All(ctx, &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// minObjectID is the smallest ObjectID generated at t, unlike NewObjectIDFromTimestamp which fills in the counter.
+func minObjectID(t time.Time) primitive.ObjectID {
+	var id primitive.ObjectID
+	binary.BigEndian.PutUint32(id[:4], uint32(t.Unix()))
+	return id
+}
+
+// countByAuthor counts the live documents of the author that make it through the stages.
+func (s *PostStorage) countByAuthor(ctx context.Context, collectionName string, userID string, stages ...bson.D) (int64, error) {
+	client := s.ReadersPool.GetConnection()
+	defer s.ReadersPool.ReleaseConnection(client)
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	collection := client.Database("asperitas").Collection(collectionName)
+
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.D{{Key: "author.id", Value: userID}, notDeleted("deletedat"), live()}}},
+	}
+	pipeline = append(pipeline, stages...)
+	pipeline = append(pipeline, bson.D{{Key: "$count", Value: "count"}})
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var result struct {
+		Count int64 `bson:"count"`
+	}
+	if cursor.TryNext(ctx) {
+		if err := cursor.Decode(&result); err != nil {
+			return 0, err
+		}
+	}
+	if cursor.Err() != nil {
+		return 0, cursor.Err()
+	}
+	return result.Count, nil
+}
+
+// notDeleted matches the documents whose deletion time under key is unset.
+func notDeleted(key string) bson.E {
+	return bson.E{Key: key, Value: bson.D{{Key: "$exists", Value: false}}}
+}
+
+// live matches the live posts or comments, those saved before moderation have no status at all.
+func live() bson.E {
+	return bson.E{Key: "status", Value: bson.D{{Key: "$in", Value: bson.A{nil, model.PostStatusLive}}}}
+}
+
 func GetSort() primitive.D {
 	sort := bson.D{
 		{
@@ -2,13 +2,17 @@ package mongo_repository
 
 import (
 	"context"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
 	"github.com/Totus-Floreo/asperitas-on-go/internal/model/mocks"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func TestGetAllPost_Success(t *testing.T) {
@@ -71,3 +75,381 @@ func TestGetAllPost_Success(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, expected, posts)
 }
+
+func TestSetLocked_Success(t *testing.T) {
+	postObjectID, _ := primitive.ObjectIDFromHex("64534b74aed82e0020e916e8")
+	post := &model.Post{ID: postObjectID}
+
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mocks.NewMockIClient(ctrl)
+	pool := mocks.NewMockIDBReadersPool(ctrl)
+	mockDB := mocks.NewMockIMongoDB(ctrl)
+	mockPostColl := mocks.NewMockICollection(ctrl)
+	mockCommentColl := mocks.NewMockICollection(ctrl)
+
+	client.EXPECT().Database("asperitas").Return(mockDB)
+	mockDB.EXPECT().Collection("posts").Return(mockPostColl)
+	mockDB.EXPECT().Collection("comments").Return(mockCommentColl)
+
+	postStorage := NewPostStorage(client, pool)
+
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "locked", Value: true}}}}
+	mockPostColl.EXPECT().UpdateByID(gomock.Any(), postObjectID, update).Return(&mongo.UpdateResult{MatchedCount: 1}, nil)
+
+	require.NoError(t, postStorage.SetLocked(ctx, post, true))
+}
+
+func TestPollVote_Success(t *testing.T) {
+	postObjectID, _ := primitive.ObjectIDFromHex("64534b74aed82e0020e916e8")
+	post := &model.Post{ID: postObjectID}
+	vote := &model.PollVote{UserID: "id1", Option: 2}
+
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mocks.NewMockIClient(ctrl)
+	pool := mocks.NewMockIDBReadersPool(ctrl)
+	mockDB := mocks.NewMockIMongoDB(ctrl)
+	mockPostColl := mocks.NewMockICollection(ctrl)
+	mockCommentColl := mocks.NewMockICollection(ctrl)
+
+	client.EXPECT().Database("asperitas").Return(mockDB)
+	mockDB.EXPECT().Collection("posts").Return(mockPostColl)
+	mockDB.EXPECT().Collection("comments").Return(mockCommentColl)
+
+	postStorage := NewPostStorage(client, pool)
+
+	// the previous vote is dropped and the new one appended by the same update
+	update := mongo.Pipeline{
+		bson.D{{Key: "$set", Value: bson.D{{Key: "poll.votes", Value: bson.D{{Key: "$concatArrays", Value: bson.A{
+			bson.D{{Key: "$filter", Value: bson.D{
+				{Key: "input", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$poll.votes", bson.A{}}}}},
+				{Key: "cond", Value: bson.D{{Key: "$ne", Value: bson.A{"$$this.user", "id1"}}}},
+			}}},
+			bson.D{{Key: "$literal", Value: bson.A{vote}}},
+		}}}}}}},
+	}
+	mockPostColl.EXPECT().UpdateByID(gomock.Any(), postObjectID, update).Return(&mongo.UpdateResult{MatchedCount: 1}, nil).Times(1)
+
+	require.NoError(t, postStorage.PollVote(ctx, post, vote))
+}
+
+func TestDeletePost_Soft(t *testing.T) {
+	postObjectID, _ := primitive.ObjectIDFromHex("64534b74aed82e0020e916e8")
+	author := &model.Author{ID: "id1", Username: "alice"}
+	deletedAt := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mocks.NewMockIClient(ctrl)
+	pool := mocks.NewMockIDBReadersPool(ctrl)
+	mockDB := mocks.NewMockIMongoDB(ctrl)
+	mockPostColl := mocks.NewMockICollection(ctrl)
+	mockCommentColl := mocks.NewMockICollection(ctrl)
+
+	client.EXPECT().Database("asperitas").Return(mockDB)
+	mockDB.EXPECT().Collection("posts").Return(mockPostColl)
+	mockDB.EXPECT().Collection("comments").Return(mockCommentColl)
+
+	postStorage := NewPostStorage(client, pool)
+
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "deletedat", Value: deletedAt}, {Key: "deletedby", Value: author}}}}
+	mockPostColl.EXPECT().UpdateByID(gomock.Any(), postObjectID, update).Return(&mongo.UpdateResult{MatchedCount: 1}, nil)
+
+	require.NoError(t, postStorage.DeletePost(ctx, postObjectID, author, deletedAt))
+}
+
+func TestSetArchived_NotFound(t *testing.T) {
+	post := &model.Post{ID: primitive.NewObjectID()}
+
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mocks.NewMockIClient(ctrl)
+	pool := mocks.NewMockIDBReadersPool(ctrl)
+	mockDB := mocks.NewMockIMongoDB(ctrl)
+	mockPostColl := mocks.NewMockICollection(ctrl)
+	mockCommentColl := mocks.NewMockICollection(ctrl)
+
+	client.EXPECT().Database("asperitas").Return(mockDB)
+	mockDB.EXPECT().Collection("posts").Return(mockPostColl)
+	mockDB.EXPECT().Collection("comments").Return(mockCommentColl)
+
+	postStorage := NewPostStorage(client, pool)
+
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "archived", Value: true}, {Key: "unarchived", Value: false}}}}
+	mockPostColl.EXPECT().UpdateByID(gomock.Any(), post.ID, update).Return(&mongo.UpdateResult{MatchedCount: 0}, nil)
+
+	require.ErrorIs(t, postStorage.SetArchived(ctx, post, true), model.ErrPostNotFound)
+}
+
+func TestVoteComment_Success(t *testing.T) {
+	post := &model.Post{ID: primitive.NewObjectID()}
+	commentID := primitive.NewObjectID()
+	vote := &model.Vote{UserID: "id1", Score: 1}
+
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mocks.NewMockIClient(ctrl)
+	pool := mocks.NewMockIDBReadersPool(ctrl)
+	mockDB := mocks.NewMockIMongoDB(ctrl)
+	mockPostColl := mocks.NewMockICollection(ctrl)
+	mockCommentColl := mocks.NewMockICollection(ctrl)
+
+	client.EXPECT().Database("asperitas").Return(mockDB)
+	mockDB.EXPECT().Collection("posts").Return(mockPostColl)
+	mockDB.EXPECT().Collection("comments").Return(mockCommentColl)
+
+	postStorage := NewPostStorage(client, pool)
+
+	filter := bson.D{{Key: "_id", Value: commentID}, {Key: "post", Value: post.ID}}
+	mockCommentColl.EXPECT().UpdateOne(gomock.Any(), filter, replaceVoteUpdate("votes", "id1", vote)).Return(&mongo.UpdateResult{MatchedCount: 1}, nil)
+	score := bson.D{{Key: "$set", Value: bson.D{{Key: "score", Value: int64(1)}}}}
+	mockCommentColl.EXPECT().UpdateOne(gomock.Any(), filter, score).Return(&mongo.UpdateResult{MatchedCount: 1}, nil)
+	mockCommentColl.EXPECT().UpdateOne(gomock.Any(), filter, gomock.Any()).Return(&mongo.UpdateResult{MatchedCount: 0}, nil)
+
+	require.NoError(t, postStorage.VoteComment(ctx, post, commentID, vote))
+	require.NoError(t, postStorage.UpdateCommentScore(ctx, post, &model.Comment{ID: commentID, Votes: []*model.Vote{vote}}))
+	require.ErrorIs(t, postStorage.UnVoteComment(ctx, post, commentID, "id1"), model.ErrCommentNotFound)
+}
+
+func TestCountCommentsByUser_Success(t *testing.T) {
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mocks.NewMockIClient(ctrl)
+	pool := mocks.NewMockIDBReadersPool(ctrl)
+	mockDB := mocks.NewMockIMongoDB(ctrl)
+	mockPostColl := mocks.NewMockICollection(ctrl)
+	mockCommentColl := mocks.NewMockICollection(ctrl)
+	mockCursor := mocks.NewMockICursor(ctrl)
+
+	client.EXPECT().Database("asperitas").Return(mockDB)
+	mockDB.EXPECT().Collection("posts").Return(mockPostColl)
+	mockDB.EXPECT().Collection("comments").Return(mockCommentColl)
+
+	postStorage := NewPostStorage(client, pool)
+
+	pool.EXPECT().GetConnection().Return(client)
+	client.EXPECT().Database("asperitas").Return(mockDB)
+	mockDB.EXPECT().Collection("comments").Return(mockCommentColl)
+
+	mockCommentColl.EXPECT().Aggregate(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, pipeline interface{}, opts ...interface{}) (model.ICursor, error) {
+		stages := pipeline.(mongo.Pipeline)
+		notDeleted := bson.D{{Key: "$exists", Value: false}}
+		// held, pending and rejected comments are not counted, nor are the comments of deleted posts
+		require.Equal(t, bson.D{{Key: "$match", Value: bson.D{
+			{Key: "author.id", Value: "id1"},
+			{Key: "deletedat", Value: notDeleted},
+			{Key: "status", Value: bson.D{{Key: "$in", Value: bson.A{nil, model.PostStatusLive}}}},
+		}}}, stages[0])
+		require.Equal(t, bson.D{{Key: "$match", Value: bson.D{{Key: "postlink.deletedat", Value: notDeleted}}}}, stages[3])
+		require.Equal(t, bson.D{{Key: "$count", Value: "count"}}, stages[4])
+		return mockCursor, nil
+	})
+	mockCursor.EXPECT().TryNext(gomock.Any()).Return(true)
+	mockCursor.EXPECT().Decode(gomock.Any()).DoAndReturn(func(result interface{}) error {
+		return bson.Unmarshal(mustMarshal(t, bson.D{{Key: "count", Value: int64(3)}}), result)
+	})
+	mockCursor.EXPECT().Err().Return(nil)
+	mockCursor.EXPECT().Close(gomock.Any())
+	pool.EXPECT().ReleaseConnection(client)
+
+	count, err := postStorage.CountCommentsByUser(ctx, "id1")
+
+	require.NoError(t, err)
+	require.Equal(t, int64(3), count)
+}
+
+func mustMarshal(t *testing.T, document interface{}) []byte {
+	raw, err := bson.Marshal(document)
+	require.NoError(t, err)
+	return raw
+}
+
+func TestGetCommentsByUser_Success(t *testing.T) {
+	postObjectID, _ := primitive.ObjectIDFromHex("64534b74aed82e0020e916e8")
+	expected := []*model.UserComment{
+		{
+			Comment: model.Comment{
+				Body:    "TestBody",
+				Created: "2006-01-02T15:04:05.000Z",
+				Author:  &model.Author{ID: "id1", Username: "alice"},
+				PostID:  postObjectID,
+			},
+			Post: &model.PostLink{ID: postObjectID, Title: "TestTitle", Category: "programming"},
+		},
+	}
+
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mocks.NewMockIClient(ctrl)
+	pool := mocks.NewMockIDBReadersPool(ctrl)
+	mockDB := mocks.NewMockIMongoDB(ctrl)
+	mockPostColl := mocks.NewMockICollection(ctrl)
+	mockCommentColl := mocks.NewMockICollection(ctrl)
+	mockCursor := mocks.NewMockICursor(ctrl)
+
+	client.EXPECT().Database("asperitas").Return(mockDB)
+	mockDB.EXPECT().Collection("posts").Return(mockPostColl)
+	mockDB.EXPECT().Collection("comments").Return(mockCommentColl)
+
+	postStorage := NewPostStorage(client, pool)
+
+	pool.EXPECT().GetConnection().Return(client)
+	client.EXPECT().Database("asperitas").Return(mockDB)
+	mockDB.EXPECT().Collection("comments").Return(mockCommentColl)
+
+	mockCommentColl.EXPECT().Aggregate(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, pipeline interface{}, opts ...interface{}) (model.ICursor, error) {
+		stages := pipeline.(mongo.Pipeline)
+		notDeleted := bson.D{{Key: "$exists", Value: false}}
+		require.Equal(t, bson.D{{Key: "$match", Value: bson.D{
+			{Key: "author.username", Value: "alice"},
+			{Key: "deletedat", Value: notDeleted},
+			{Key: "author.id", Value: bson.D{{Key: "$nin", Value: []string{"id1"}}}},
+			{Key: "status", Value: bson.D{{Key: "$in", Value: bson.A{nil, model.PostStatusLive}}}},
+		}}}, stages[0])
+		require.Equal(t, bson.D{{Key: "$match", Value: bson.D{{Key: "postlink.deletedat", Value: notDeleted}}}}, stages[4])
+		require.Equal(t, bson.D{{Key: "$skip", Value: 10}}, stages[5])
+		require.Equal(t, bson.D{{Key: "$limit", Value: 5}}, stages[6])
+		return mockCursor, nil
+	})
+	mockCursor.EXPECT().All(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, comments *[]*model.UserComment) error {
+		*comments = append(*comments, expected[0])
+		return nil
+	})
+	mockCursor.EXPECT().Close(gomock.Any())
+	pool.EXPECT().ReleaseConnection(client)
+
+	comments, err := postStorage.GetCommentsByUser(ctx, "alice", model.UserCommentsFilter{ExcludeAuthors: []string{"id1"}, Offset: 10, Limit: 5})
+
+	require.NoError(t, err)
+	require.Equal(t, expected, comments)
+}
+
+func TestGetPostsByURL_Success(t *testing.T) {
+	postObjectID, _ := primitive.ObjectIDFromHex("64534b74aed82e0020e916e8")
+	expected := []*model.Post{
+		{ID: postObjectID, Title: "TestTitle", Category: "music", Url: "http://www.example.com/a/", CanonicalUrl: "https://example.com/a"},
+	}
+	since := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	sinceObjectID, _ := primitive.ObjectIDFromHex("644f01000000000000000000")
+
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mocks.NewMockIClient(ctrl)
+	pool := mocks.NewMockIDBReadersPool(ctrl)
+	mockDB := mocks.NewMockIMongoDB(ctrl)
+	mockPostColl := mocks.NewMockICollection(ctrl)
+	mockCommentColl := mocks.NewMockICollection(ctrl)
+	mockCursor := mocks.NewMockICursor(ctrl)
+
+	client.EXPECT().Database("asperitas").Return(mockDB)
+	mockDB.EXPECT().Collection("posts").Return(mockPostColl)
+	mockDB.EXPECT().Collection("comments").Return(mockCommentColl)
+
+	postStorage := NewPostStorage(client, pool)
+
+	pool.EXPECT().GetConnection().Return(client)
+	client.EXPECT().Database("asperitas").Return(mockDB)
+	mockDB.EXPECT().Collection("posts").Return(mockPostColl)
+
+	mockPostColl.EXPECT().Aggregate(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, pipeline interface{}, opts ...interface{}) (model.ICursor, error) {
+		stages := pipeline.(mongo.Pipeline)
+		require.Equal(t, bson.D{{Key: "$match", Value: bson.D{
+			{Key: "canonicalurl", Value: "https://example.com/a"},
+			{Key: "_id", Value: bson.D{{Key: "$gte", Value: sinceObjectID}}},
+		}}}, stages[0])
+		return mockCursor, nil
+	})
+	mockCursor.EXPECT().All(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, posts *[]*model.Post) error {
+		*posts = append(*posts, expected[0])
+		return nil
+	})
+	mockCursor.EXPECT().Close(gomock.Any())
+	pool.EXPECT().ReleaseConnection(client)
+
+	posts, err := postStorage.GetPostsByURL(ctx, "https://example.com/a", since)
+
+	require.NoError(t, err)
+	require.Equal(t, expected, posts)
+}
+
+func TestPurgeDeleted_KeepsRestored(t *testing.T) {
+	purgedID, _ := primitive.ObjectIDFromHex("64534b74aed82e0020e916e8")
+	restoredID, _ := primitive.ObjectIDFromHex("64534b74aed82e0020e916e9")
+	before := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mocks.NewMockIClient(ctrl)
+	pool := mocks.NewMockIDBReadersPool(ctrl)
+	mockDB := mocks.NewMockIMongoDB(ctrl)
+	mockPostColl := mocks.NewMockICollection(ctrl)
+	mockCommentColl := mocks.NewMockICollection(ctrl)
+
+	client.EXPECT().Database("asperitas").Return(mockDB)
+	mockDB.EXPECT().Collection("posts").Return(mockPostColl)
+	mockDB.EXPECT().Collection("comments").Return(mockCommentColl)
+
+	postStorage := NewPostStorage(client, pool)
+
+	// both posts expired, one of them is restored before it is removed
+	expired := bson.D{{Key: "$match", Value: bson.D{{Key: "deletedat", Value: bson.D{{Key: "$lt", Value: before}}}}}}
+	gomock.InOrder(
+		mockPostColl.EXPECT().Aggregate(gomock.Any(), expiredBatch(before)).Return(idCursor(ctrl, purgedID, restoredID), nil),
+		mockPostColl.EXPECT().DeleteMany(gomock.Any(), expiredAmong([]primitive.ObjectID{purgedID, restoredID}, before)).Return(&mongo.DeleteResult{DeletedCount: 1}, nil),
+		mockPostColl.EXPECT().Aggregate(gomock.Any(), gomock.Any()).Return(idCursor(ctrl, restoredID), nil),
+	)
+	mockCommentColl.EXPECT().DeleteMany(gomock.Any(), bson.D{{Key: "post", Value: bson.D{{Key: "$in", Value: []primitive.ObjectID{purgedID}}}}}).Return(&mongo.DeleteResult{DeletedCount: 2}, nil)
+	mockCommentColl.EXPECT().Aggregate(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, pipeline interface{}, opts ...interface{}) (model.ICursor, error) {
+		require.Equal(t, expired, pipeline.(mongo.Pipeline)[0])
+		return idCursor(ctrl), nil
+	})
+
+	purged, err := postStorage.PurgeDeleted(ctx, before)
+
+	require.NoError(t, err)
+	require.Equal(t, int64(3), purged)
+}
+
+// idCursor returns a cursor over documents holding only the given IDs.
+func idCursor(ctrl *gomock.Controller, ids ...primitive.ObjectID) model.ICursor {
+	cursor := mocks.NewMockICursor(ctrl)
+	cursor.EXPECT().All(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, docs interface{}) error {
+		slice := reflect.ValueOf(docs).Elem()
+		for _, id := range ids {
+			doc := reflect.New(slice.Type().Elem()).Elem()
+			doc.Field(0).Set(reflect.ValueOf(id))
+			slice.Set(reflect.Append(slice, doc))
+		}
+		return nil
+	})
+	cursor.EXPECT().Close(gomock.Any())
+	return cursor
+}
@@ -0,0 +1,110 @@
+package mongo_repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type ReportStorage struct {
+	Storage model.ICollection
+}
+
+func NewReportStorage(client model.IClient) *ReportStorage {
+	return &ReportStorage{
+		Storage: client.Database("asperitas").Collection("reports"),
+	}
+}
+
+func (s *ReportStorage) AddReport(ctx context.Context, report *model.Report) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	report.ID = primitive.NewObjectID()
+	_, err := s.Storage.InsertOne(ctx, report)
+	if mongo.IsDuplicateKeyError(err) {
+		return model.ErrAlreadyReported
+	}
+	return err
+}
+
+func (s *ReportStorage) GetReportQueue(ctx context.Context, filter model.ReportQueueFilter) ([]*model.ReportGroup, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	match := bson.D{{Key: "status", Value: model.ReportOpen}}
+	if len(filter.Categories) > 0 {
+		match = append(match, bson.E{Key: "category", Value: bson.D{{Key: "$in", Value: filter.Categories}}})
+	}
+
+	// the groups decode into ReportGroup by its default field names
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: match}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "post", Value: "$post"}, {Key: "comment", Value: "$comment"}}},
+			{Key: "commentid", Value: bson.D{{Key: "$first", Value: "$comment"}}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "first", Value: bson.D{{Key: "$min", Value: "$_id"}}},
+			{Key: "reports", Value: bson.D{{Key: "$push", Value: "$$ROOT"}}},
+		}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "first", Value: 1}}}},
+		bson.D{{Key: "$skip", Value: filter.Offset}},
+		bson.D{{Key: "$limit", Value: filter.Limit}},
+	}
+
+	cursor, err := s.Storage.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	groups := []*model.ReportGroup{}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+func (s *ReportStorage) GetOpenReportsByPost(ctx context.Context, postID primitive.ObjectID) ([]*model.Report, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.D{{Key: "post", Value: postID}, {Key: "status", Value: model.ReportOpen}}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}
+
+	cursor, err := s.Storage.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	reports := []*model.Report{}
+	if err := cursor.All(ctx, &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+func (s *ReportStorage) CloseReports(ctx context.Context, reportIDs []primitive.ObjectID, resolution *model.ReportResolution) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{Key: "_id", Value: bson.D{{Key: "$in", Value: reportIDs}}},
+		{Key: "status", Value: model.ReportOpen},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "status", Value: model.ReportClosed},
+		{Key: "resolution", Value: resolution},
+	}}}
+
+	_, err := s.Storage.UpdateMany(ctx, filter, update)
+	return err
+}
@@ -0,0 +1,143 @@
+package mongo_repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Migrate creates the indexes and fills in the post of comments stored before it was recorded, it is safe to run again.
+func Migrate(ctx context.Context, db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	comments := db.Collection("comments")
+	_, err := comments.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "author.username", Value: 1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "author.id", Value: 1}}},
+		{Keys: bson.D{{Key: "post", Value: 1}}},
+		// soft-deleted comments only, for the purge
+		{Keys: bson.D{{Key: "deletedat", Value: 1}}, Options: options.Index().SetSparse(true)},
+		// the comments a user voted on are recounted when they are shadow-banned
+		{Keys: bson.D{{Key: "votes.user", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	posts := db.Collection("posts")
+	_, err = posts.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "canonicalurl", Value: 1}, {Key: "_id", Value: -1}},
+			// link posts only, the others have no canonical URL
+			Options: options.Index().SetSparse(true),
+		},
+		// soft-deleted posts only, for the purge
+		{Keys: bson.D{{Key: "deletedat", Value: 1}}, Options: options.Index().SetSparse(true)},
+		// the posts a user voted on are recounted when they are shadow-banned
+		{Keys: bson.D{{Key: "votes.user", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Collection("reports").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "category", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "post", Value: 1}, {Key: "status", Value: 1}, {Key: "_id", Value: 1}}},
+		{
+			// one open report per reporter and post or comment, post reports have no comment
+			Keys: bson.D{{Key: "reporter.id", Value: 1}, {Key: "post", Value: 1}, {Key: "comment", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.D{{Key: "status", Value: model.ReportOpen}}),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Collection("modlog").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "category", Value: 1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "moderator.username", Value: 1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "target.id", Value: 1}, {Key: "_id", Value: -1}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Collection("conversations").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		// one conversation per set of members, a concurrent second insert fails
+		{Keys: bson.D{{Key: "key", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "members", Value: 1}, {Key: "updated", Value: -1}, {Key: "_id", Value: -1}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Collection("messages").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "conversation", Value: 1}, {Key: "_id", Value: -1}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Collection("notifications").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user", Value: 1}, {Key: "created", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "user", Value: 1}, {Key: "read", Value: 1}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Collection("blocks").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "user", Value: 1}, {Key: "target.id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Collection("bans").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user.id", Value: 1}, {Key: "category", Value: 1}, {Key: "shadow", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "shadow", Value: 1}}},
+		// temporary bans are dropped once they expire, permanent ones have no expires field
+		{Keys: bson.D{{Key: "expires", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	if err != nil {
+		return err
+	}
+
+	cursor, err := posts.Find(ctx, bson.D{}, options.Find().SetProjection(bson.D{{Key: "comments", Value: 1}}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var post struct {
+			ID       primitive.ObjectID   `bson:"_id"`
+			Comments []primitive.ObjectID `bson:"comments"`
+		}
+		if err := cursor.Decode(&post); err != nil {
+			return err
+		}
+		if len(post.Comments) == 0 {
+			continue
+		}
+
+		filter := bson.D{
+			{Key: "_id", Value: bson.D{{Key: "$in", Value: post.Comments}}},
+			{Key: "post", Value: bson.D{{Key: "$exists", Value: false}}},
+		}
+		update := bson.D{{Key: "$set", Value: bson.D{{Key: "post", Value: post.ID}}}}
+		if _, err := comments.UpdateMany(ctx, filter, update); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
@@ -26,7 +26,8 @@ func (s *UserStorage) GetUser(ctx context.Context, username string) (*model.User
 	defer tx.Rollback(ctx)
 
 	user := &model.User{}
-	if err := tx.QueryRow(ctx, "SELECT * FROM users WHERE username = $1", username).Scan(&user.ID, &user.Username, &user.Password); err != nil {
+	row := tx.QueryRow(ctx, "SELECT id, username, password, bio, avatar, post_karma, comment_karma, created_at FROM users WHERE username = $1", username)
+	if err := row.Scan(&user.ID, &user.Username, &user.Password, &user.Bio, &user.Avatar, &user.PostKarma, &user.CommentKarma, &user.Created); err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, model.ErrUserNotFound
 		} else {
@@ -62,3 +63,58 @@ func (s *UserStorage) AddUser(ctx context.Context, user *model.User) error {
 
 	return nil
 }
+
+func (s *UserStorage) UpdateProfile(ctx context.Context, user *model.User) error {
+	tx, err := s.connPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, "UPDATE users SET bio = $1, avatar = $2 WHERE id = $3", user.Bio, user.Avatar, user.ID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return model.ErrUserNotFound
+	}
+
+	return tx.Commit(ctx)
+}
+
+// AddKarma adjusts the karma of a user by the given amounts.
+func (s *UserStorage) AddKarma(ctx context.Context, userID string, karma *model.Karma) error {
+	tx, err := s.connPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, "UPDATE users SET post_karma = post_karma + $1, comment_karma = comment_karma + $2 WHERE id = $3", karma.Post, karma.Comment, userID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ReplaceKarma resets the karma of every user and sets the given values in a single transaction.
+func (s *UserStorage) ReplaceKarma(ctx context.Context, karma map[string]*model.Karma) error {
+	tx, err := s.connPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "UPDATE users SET post_karma = 0, comment_karma = 0"); err != nil {
+		return err
+	}
+	for userID, userKarma := range karma {
+		_, err := tx.Exec(ctx, "UPDATE users SET post_karma = $1, comment_karma = $2 WHERE id = $3", userKarma.Post, userKarma.Comment, userID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
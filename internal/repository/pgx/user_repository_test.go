@@ -293,3 +293,71 @@ func TestAddUser_CommitError(t *testing.T) {
 	require.Error(t, err)
 	require.True(t, errors.Is(err, ErrCommit))
 }
+
+func TestUpdateProfile_Success(t *testing.T) {
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pool := mocks.NewMockIPool(ctrl)
+	userStorage := NewUserStorage(pool)
+
+	mockTx := mocks.NewMockTx(ctrl)
+
+	pool.EXPECT().Begin(gomock.Any()).Return(mockTx, nil)
+
+	mockTx.EXPECT().Exec(gomock.Any(), gomock.Any(), "bio", "/uploads/avatar.png", Test.Result.User.ID).Return(pgconn.NewCommandTag("UPDATE 1"), nil)
+
+	mockTx.EXPECT().Commit(gomock.Any()).Return(nil)
+
+	mockTx.EXPECT().Rollback(gomock.Any()).Return(nil)
+
+	err := userStorage.UpdateProfile(ctx, &model.User{ID: Test.Result.User.ID, Bio: "bio", Avatar: "/uploads/avatar.png"})
+
+	require.NoError(t, err)
+}
+
+func TestUpdateProfile_UserNotFound(t *testing.T) {
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pool := mocks.NewMockIPool(ctrl)
+	userStorage := NewUserStorage(pool)
+
+	mockTx := mocks.NewMockTx(ctrl)
+
+	pool.EXPECT().Begin(gomock.Any()).Return(mockTx, nil)
+
+	mockTx.EXPECT().Exec(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(pgconn.NewCommandTag("UPDATE 0"), nil)
+
+	mockTx.EXPECT().Rollback(gomock.Any()).Return(nil)
+
+	err := userStorage.UpdateProfile(ctx, Test.Result.User)
+
+	require.True(t, errors.Is(err, model.ErrUserNotFound))
+}
+
+func TestMigrate_ExecError(t *testing.T) {
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pool := mocks.NewMockIPool(ctrl)
+
+	mockTx := mocks.NewMockTx(ctrl)
+
+	pool.EXPECT().Begin(gomock.Any()).Return(mockTx, nil)
+
+	mockTx.EXPECT().Exec(gomock.Any(), gomock.Any()).Return(pgconn.CommandTag{}, nil).Times(2)
+	mockTx.EXPECT().Exec(gomock.Any(), gomock.Any()).Return(pgconn.CommandTag{}, ErrExec)
+
+	mockTx.EXPECT().Rollback(gomock.Any()).Return(nil)
+
+	err := Migrate(ctx, pool)
+
+	require.True(t, errors.Is(err, ErrExec))
+}
@@ -0,0 +1,68 @@
+package redis_repository
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+const eventChannelPrefix = "asperitas:events:"
+
+// EventBroker publishes events through redis pub/sub so that every server
+// instance receives them, local subscribers are served by the wrapped broker.
+type EventBroker struct {
+	rdb   *redis.Client
+	local model.IEventBroker
+}
+
+func NewEventBroker(rdb *redis.Client, local model.IEventBroker) *EventBroker {
+	return &EventBroker{
+		rdb:   rdb,
+		local: local,
+	}
+}
+
+func (b *EventBroker) Publish(ctx context.Context, topic string, event *model.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return b.rdb.Publish(ctx, eventChannelPrefix+topic, payload).Err()
+}
+
+func (b *EventBroker) Subscribe(ctx context.Context, topic string) (<-chan *model.Event, func(), error) {
+	return b.local.Subscribe(ctx, topic)
+}
+
+// Run forwards events from redis to the local subscribers until ctx is done.
+func (b *EventBroker) Run(ctx context.Context) error {
+	pubsub := b.rdb.PSubscribe(ctx, eventChannelPrefix+"*")
+	defer pubsub.Close()
+
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return err
+	}
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case message, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			event := new(model.Event)
+			if err := json.Unmarshal([]byte(message.Payload), event); err != nil {
+				continue
+			}
+			topic := strings.TrimPrefix(message.Channel, eventChannelPrefix)
+			if err := b.local.Publish(ctx, topic, event); err != nil {
+				return err
+			}
+		}
+	}
+}
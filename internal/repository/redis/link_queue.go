@@ -0,0 +1,94 @@
+package redis_repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	linkQueueKey           = "asperitas:linkchecks"
+	linkQueueProcessingKey = "asperitas:linkchecks:processing"
+	linkQueuePollInterval  = time.Second
+	linkQueueLease         = 5 * time.Minute
+)
+
+// claimLinkCheck moves the checks whose lease ran out back to the queue, then moves the first due
+// check to the processing set, scored by the time its lease runs out.
+var claimLinkCheck = redis.NewScript(`
+local expired = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', ARGV[1])
+for _, member in ipairs(expired) do
+	redis.call('ZREM', KEYS[2], member)
+	redis.call('ZADD', KEYS[1], ARGV[1], member)
+end
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, 1)
+if #due == 0 then
+	return false
+end
+redis.call('ZREM', KEYS[1], due[1])
+redis.call('ZADD', KEYS[2], ARGV[2], due[1])
+return due[1]
+`)
+
+// LinkQueue keeps link checks in a sorted set scored by due time, so that
+// they survive restarts and are shared by the workers of every instance.
+// A dequeued check sits in a processing set until it is acknowledged, if its
+// worker dies it goes back to the queue once its lease runs out.
+type LinkQueue struct {
+	rdb *redis.Client
+}
+
+func NewLinkQueue(rdb *redis.Client) *LinkQueue {
+	return &LinkQueue{
+		rdb: rdb,
+	}
+}
+
+func (q *LinkQueue) Enqueue(ctx context.Context, check *model.LinkCheck) error {
+	payload, err := json.Marshal(check)
+	if err != nil {
+		return err
+	}
+	return q.rdb.ZAdd(ctx, linkQueueKey, redis.Z{
+		Score:  float64(check.Due.UnixMilli()),
+		Member: string(payload),
+	}).Err()
+}
+
+func (q *LinkQueue) Dequeue(ctx context.Context) (*model.LinkCheck, error) {
+	for {
+		now := time.Now()
+		member, err := claimLinkCheck.Run(ctx, q.rdb,
+			[]string{linkQueueKey, linkQueueProcessingKey},
+			now.UnixMilli(), now.Add(linkQueueLease).UnixMilli(),
+		).Text()
+		if err == nil {
+			check := new(model.LinkCheck)
+			if err := json.Unmarshal([]byte(member), check); err != nil {
+				q.rdb.ZRem(ctx, linkQueueProcessingKey, member)
+				continue
+			}
+			return check, nil
+		}
+		if err != redis.Nil {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(linkQueuePollInterval):
+		}
+	}
+}
+
+func (q *LinkQueue) Ack(ctx context.Context, check *model.LinkCheck) error {
+	payload, err := json.Marshal(check)
+	if err != nil {
+		return err
+	}
+	return q.rdb.ZRem(ctx, linkQueueProcessingKey, string(payload)).Err()
+}
@@ -0,0 +1,69 @@
+package redis_repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/go-redis/redismock/v9"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestLinkQueue(t *testing.T) {
+	ctx := context.Background()
+	client, mock := redismock.NewClientMock()
+	queue := NewLinkQueue(client)
+
+	check := &model.LinkCheck{
+		PostID:  primitive.NewObjectID(),
+		URL:     "https://example.com",
+		Attempt: 1,
+		Due:     time.UnixMilli(1700000000000).UTC(),
+	}
+	payload, err := json.Marshal(check)
+	require.NoError(t, err)
+
+	t.Run("Enqueue", func(t *testing.T) {
+		mock.ExpectZAdd(linkQueueKey, redis.Z{Score: 1700000000000, Member: string(payload)}).SetVal(1)
+
+		require.NoError(t, queue.Enqueue(ctx, check))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// the lease bounds are derived from the current time, the keys are compared
+	ignoreTimes := func(expected, actual []interface{}) error {
+		require.Equal(t, expected[:5], actual[:5])
+		return nil
+	}
+	keys := []string{linkQueueKey, linkQueueProcessingKey}
+
+	t.Run("Dequeue", func(t *testing.T) {
+		mock.CustomMatch(ignoreTimes).ExpectEvalSha(claimLinkCheck.Hash(), keys, "now", "lease").SetErr(redis.Nil)
+		mock.CustomMatch(ignoreTimes).ExpectEvalSha(claimLinkCheck.Hash(), keys, "now", "lease").SetVal(string(payload))
+
+		dequeued, err := queue.Dequeue(ctx)
+		require.NoError(t, err)
+		require.Equal(t, check, dequeued)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Ack", func(t *testing.T) {
+		mock.ExpectZRem(linkQueueProcessingKey, string(payload)).SetVal(1)
+
+		require.NoError(t, queue.Ack(ctx, check))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Dequeue Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		mock.CustomMatch(ignoreTimes).ExpectEvalSha(claimLinkCheck.Hash(), keys, "now", "lease").SetErr(redis.Nil)
+
+		_, err := queue.Dequeue(ctx)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
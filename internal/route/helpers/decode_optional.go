@@ -0,0 +1,17 @@
+package helpers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// DecodeOptionalBody decodes the JSON body into data, leaving data untouched when there is no body.
+func DecodeOptionalBody(r *http.Request, data interface{}) error {
+	err := json.NewDecoder(r.Body).Decode(data)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
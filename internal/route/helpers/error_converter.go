@@ -14,6 +14,72 @@ func HTTPError(err error) string {
 		return model.ErrUnAuthorizedHTTP.Error()
 	case model.ErrInvalidCommentID:
 		return model.ErrCommentInvalidHTTP.Error()
+	case model.ErrInvalidNotificationID:
+		return model.ErrNotificationInvalidHTTP.Error()
+	case model.ErrInvalidConversationID:
+		return model.ErrConversationInvalidHTTP.Error()
+	case model.ErrConversationNotFound:
+		return model.ErrConversationNotFoundHTTP.Error()
+	case model.ErrInvalidRecipient:
+		return model.ErrRecipientInvalidHTTP.Error()
+	case model.ErrBlocked:
+		return model.ErrBlockedHTTP.Error()
+	case model.ErrInvalidBlockType:
+		return model.ErrBlockTypeInvalidHTTP.Error()
+	case model.ErrInvalidBlockTarget:
+		return model.ErrBlockTargetInvalidHTTP.Error()
+	case model.ErrUserNotFound:
+		return model.ErrUserNotFoundHTTP.Error()
+	case model.ErrInvalidAvatar:
+		return model.ErrAvatarInvalidHTTP.Error()
+	case model.ErrPostLocked:
+		return model.ErrPostLockedHTTP.Error()
+	case model.ErrPostArchived:
+		return model.ErrPostArchivedHTTP.Error()
+	case model.ErrPostPending:
+		return model.ErrPostPendingHTTP.Error()
+	case model.ErrModerateActionNotImplement:
+		return model.ErrModerateActionHTTP.Error()
+	case model.ErrPostNotPoll:
+		return model.ErrPostNotPollHTTP.Error()
+	case model.ErrPollClosed:
+		return model.ErrPollClosedHTTP.Error()
+	case model.ErrInvalidPollOption:
+		return model.ErrPollOptionInvalidHTTP.Error()
+	case model.ErrImageTooLarge:
+		return model.ErrImageTooLargeHTTP.Error()
+	case model.ErrImageUnsupported:
+		return model.ErrImageUnsupportedHTTP.Error()
+	case model.ErrInvalidImage:
+		return model.ErrImageInvalidHTTP.Error()
+	case model.ErrContentRejected:
+		return model.ErrContentRejectedHTTP.Error()
+	case model.ErrNotHeld:
+		return model.ErrNotHeldHTTP.Error()
+	case model.ErrAlreadyReported:
+		return model.ErrAlreadyReportedHTTP.Error()
+	case model.ErrReportNotFound:
+		return model.ErrReportNotFoundHTTP.Error()
+	case model.ErrBanNotFound:
+		return model.ErrBanNotFoundHTTP.Error()
+	case model.ErrInvalidBanDuration:
+		return model.ErrBanDurationInvalidHTTP.Error()
+	case model.ErrInvalidBanTarget:
+		return model.ErrBanTargetInvalidHTTP.Error()
+	case model.ErrNotDeleted:
+		return model.ErrNotDeletedHTTP.Error()
+	case model.ErrRestoreExpired:
+		return model.ErrRestoreExpiredHTTP.Error()
+	case model.ErrInvalidCategory:
+		return model.ErrPostCategoryInvalidHTTP.Error()
+	case model.ErrInvalidRule:
+		return model.ErrRuleInvalidHTTP.Error()
+	case model.ErrInvalidRules:
+		return model.ErrRulesInvalidHTTP.Error()
+	case model.ErrModeratorNotFound:
+		return model.ErrModeratorNotFoundHTTP.Error()
+	case model.ErrInvalidModeratorTarget:
+		return model.ErrModTargetInvalidHTTP.Error()
 	}
 	return err.Error()
 }
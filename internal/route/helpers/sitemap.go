@@ -0,0 +1,46 @@
+package helpers
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// SitemapLimit is the most URLs a single sitemap may list.
+const SitemapLimit = 50000
+
+type SitemapURL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type urlSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+// RenderSitemap encodes the urls as a sitemaps.org document, dropping any past SitemapLimit.
+func RenderSitemap(urls []*SitemapURL) ([]byte, error) {
+	if len(urls) > SitemapLimit {
+		urls = urls[:SitemapLimit]
+	}
+
+	document := urlSet{URLs: make([]sitemapURL, 0, len(urls))}
+	for _, url := range urls {
+		entry := sitemapURL{Loc: url.Loc}
+		if !url.LastMod.IsZero() {
+			entry.LastMod = url.LastMod.UTC().Format(time.RFC3339)
+		}
+		document.URLs = append(document.URLs, entry)
+	}
+
+	body, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
@@ -0,0 +1,132 @@
+package route
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/application"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/route/helpers"
+	"github.com/gorilla/mux"
+
+	"go.uber.org/zap"
+)
+
+type BanHandler struct {
+	Logger     *zap.SugaredLogger
+	BanService *application.BanService
+}
+
+func (h *BanHandler) GetBans(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	bans, err := h.BanService.GetBans(r.Context())
+	if err == model.ErrUnAuthorized {
+		http.Error(w, helpers.HTTPError(err), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.SendResponse(w, http.StatusOK, bans)
+}
+
+// Ban bans a user site-wide, or from a category when one is given, for a Go duration such as "72h" or for good.
+// With "shadow" set the user is shadow-banned instead.
+func (h *BanHandler) Ban(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var data struct {
+		Username string `json:"username"`
+		Category string `json:"category"`
+		Shadow   bool   `json:"shadow"`
+		Reason   string `json:"reason"`
+		Duration string `json:"duration"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusBadRequest)
+		return
+	}
+	if data.Username == "" {
+		msg, err := model.NewErrorStack("body", "username", "", "is required")
+		if err != nil {
+			http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+			return
+		}
+		http.Error(w, msg, http.StatusUnprocessableEntity)
+		return
+	}
+	var duration time.Duration
+	if data.Duration != "" {
+		parsed, err := time.ParseDuration(data.Duration)
+		if err != nil {
+			http.Error(w, helpers.HTTPError(model.ErrInvalidBanDuration), http.StatusUnprocessableEntity)
+			return
+		}
+		duration = parsed
+	}
+
+	ban, err := h.BanService.Ban(r.Context(), data.Username, data.Category, data.Shadow, data.Reason, duration)
+	if err == model.ErrUnAuthorized {
+		http.Error(w, helpers.HTTPError(err), http.StatusForbidden)
+		return
+	}
+	if err == model.ErrReasonTooLong {
+		msg, err := model.NewErrorStack("body", "reason", data.Reason, "must be at most 500 characters long")
+		if err != nil {
+			http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+			return
+		}
+		http.Error(w, msg, http.StatusUnprocessableEntity)
+		return
+	}
+	if err == model.ErrInvalidBanDuration || err == model.ErrInvalidBanTarget {
+		http.Error(w, helpers.HTTPError(err), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.SendResponse(w, http.StatusCreated, ban)
+}
+
+// Unban lifts the user's site-wide ban, or their ban from the category query parameter,
+// or their shadow ban when the shadow query parameter is true.
+func (h *BanHandler) Unban(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	vars := mux.Vars(r)
+	username, found := vars["user"]
+	if !found {
+		http.Error(w, model.ErrUserInvalidHTTP.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	query := r.URL.Query()
+	shadow, _ := strconv.ParseBool(query.Get("shadow"))
+
+	err := h.BanService.Unban(r.Context(), username, query.Get("category"), shadow)
+	if err == model.ErrUnAuthorized {
+		http.Error(w, helpers.HTTPError(err), http.StatusForbidden)
+		return
+	}
+	if err == model.ErrInvalidBanTarget {
+		http.Error(w, helpers.HTTPError(err), http.StatusUnprocessableEntity)
+		return
+	}
+	if err == model.ErrBanNotFound {
+		http.Error(w, helpers.HTTPError(err), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"success"}`))
+}
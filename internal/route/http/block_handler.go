@@ -0,0 +1,83 @@
+package route
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/application"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/route/helpers"
+	"github.com/gorilla/mux"
+
+	"go.uber.org/zap"
+)
+
+type BlockHandler struct {
+	Logger       *zap.SugaredLogger
+	BlockService *application.BlockService
+}
+
+func (h *BlockHandler) GetBlocks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	blocks, err := h.BlockService.GetBlocks(r.Context())
+	if err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.SendResponse(w, http.StatusOK, blocks)
+}
+
+func (h *BlockHandler) Block(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var data map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	username, ok := data["username"]
+	if !ok {
+		msg, err := model.NewErrorStack("body", "username", "", "is required")
+		if err != nil {
+			http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+			return
+		}
+		http.Error(w, msg, http.StatusUnprocessableEntity)
+		return
+	}
+
+	block, err := h.BlockService.Block(r.Context(), username, data["type"])
+	if err == model.ErrInvalidBlockType || err == model.ErrInvalidBlockTarget {
+		http.Error(w, helpers.HTTPError(err), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.SendResponse(w, http.StatusCreated, block)
+}
+
+func (h *BlockHandler) Unblock(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	vars := mux.Vars(r)
+	username, found := vars["user"]
+	if !found {
+		http.Error(w, model.ErrBlockTargetInvalidHTTP.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	err := h.BlockService.Unblock(r.Context(), username)
+	if err == model.ErrInvalidBlockTarget {
+		http.Error(w, helpers.HTTPError(err), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"success"}`))
+}
@@ -0,0 +1,109 @@
+package route
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/application"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/route/helpers"
+	"github.com/gorilla/mux"
+
+	"go.uber.org/zap"
+)
+
+type CategoryHandler struct {
+	Logger          *zap.SugaredLogger
+	CategoryService *application.CategoryService
+}
+
+// GetCategories lists the categories with their rules and moderators.
+func (h *CategoryHandler) GetCategories(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	categories, err := h.CategoryService.GetCategories(r.Context())
+	if err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.SendResponse(w, http.StatusOK, categories)
+}
+
+func (h *CategoryHandler) GetCategory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	category, err := h.CategoryService.GetCategory(r.Context(), mux.Vars(r)["category"])
+	if err == model.ErrInvalidCategory {
+		http.Error(w, helpers.HTTPError(err), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.SendResponse(w, http.StatusOK, category)
+}
+
+// SetRules replaces the rules of the category with the "rules" list of the body.
+func (h *CategoryHandler) SetRules(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var data struct {
+		Rules []string `json:"rules"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusBadRequest)
+		return
+	}
+
+	category, err := h.CategoryService.SetRules(r.Context(), mux.Vars(r)["category"], data.Rules)
+	h.respond(w, category, err)
+}
+
+// AddModerator makes the user named in the body a moderator of the category.
+func (h *CategoryHandler) AddModerator(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var data struct {
+		Username string `json:"username"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusBadRequest)
+		return
+	}
+	if data.Username == "" {
+		msg, err := model.NewErrorStack("body", "username", "", "is required")
+		if err != nil {
+			http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+			return
+		}
+		http.Error(w, msg, http.StatusUnprocessableEntity)
+		return
+	}
+
+	category, err := h.CategoryService.AddModerator(r.Context(), mux.Vars(r)["category"], data.Username)
+	h.respond(w, category, err)
+}
+
+func (h *CategoryHandler) RemoveModerator(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	vars := mux.Vars(r)
+
+	category, err := h.CategoryService.RemoveModerator(r.Context(), vars["category"], vars["user"])
+	h.respond(w, category, err)
+}
+
+func (h *CategoryHandler) respond(w http.ResponseWriter, category *model.Category, err error) {
+	switch err {
+	case nil:
+		helpers.SendResponse(w, http.StatusOK, category)
+	case model.ErrUnAuthorized:
+		http.Error(w, helpers.HTTPError(err), http.StatusForbidden)
+	case model.ErrInvalidCategory, model.ErrInvalidRules, model.ErrInvalidModeratorTarget:
+		http.Error(w, helpers.HTTPError(err), http.StatusUnprocessableEntity)
+	case model.ErrModeratorNotFound:
+		http.Error(w, helpers.HTTPError(err), http.StatusNotFound)
+	default:
+		http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,94 @@
+package route
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/application"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/route/helpers"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+const sseHeartbeat = 25 * time.Second
+
+type EventHandler struct {
+	Logger      *zap.SugaredLogger
+	PostService *application.PostService
+	Broker      model.IEventBroker
+}
+
+func (h *EventHandler) PostEvents(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	postObjectID, err := primitive.ObjectIDFromHex(vars["postID"])
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		http.Error(w, helpers.HTTPError(model.ErrInvalidPostID), http.StatusUnprocessableEntity)
+		return
+	}
+	// only the posts the user can see may be followed
+	if _, err := h.PostService.GetPostComments(r.Context(), postObjectID.Hex()); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		http.Error(w, helpers.HTTPError(err), http.StatusNotFound)
+		return
+	}
+
+	h.stream(w, r, model.PostTopic(postObjectID.Hex()))
+}
+
+func (h *EventHandler) PostsEvents(w http.ResponseWriter, r *http.Request) {
+	h.stream(w, r, model.PostsTopic)
+}
+
+func (h *EventHandler) stream(w http.ResponseWriter, r *http.Request, topic string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	events, unsubscribe, err := h.Broker.Subscribe(r.Context(), topic)
+	if err != nil {
+		h.Logger.Errorw("event subscription failed", "topic", topic, "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 3000\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if visible, err := h.PostService.CanSeeEvent(r.Context(), event); err != nil || !visible {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, event.Data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
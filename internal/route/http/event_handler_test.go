@@ -0,0 +1,101 @@
+package route
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/application"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/repository/inmemory"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+func TestEventHandler_PostEvents(t *testing.T) {
+	postID := "64534b74aed82e0020e916e8"
+	postObjectID, _ := primitive.ObjectIDFromHex(postID)
+	pending := &model.Post{ID: primitive.NewObjectID(), Category: "music", Author: &model.Author{ID: "id2", Username: "bob"}, Status: model.PostStatusPending}
+	banned := &model.Post{ID: primitive.NewObjectID(), Category: "music", Author: &model.Author{ID: "id9", Username: "spammer"}}
+	postStorage := &FakePostStorage{Posts: []*model.Post{
+		{ID: postObjectID, Category: "music", Author: &model.Author{ID: "id2", Username: "bob"}},
+		pending,
+		banned,
+	}}
+	banStorage := inmemory.NewBanStorage()
+	require.NoError(t, banStorage.AddBan(context.Background(), model.NewBan(banned.Author, "", true, "", nil, nil)))
+	postService := application.NewPostService(postStorage, inmemory.NewModeratorStorage(), model.TimeControllerFunc(time.Now))
+	postService.SetBanChecker(application.NewBanService(banStorage, nil, nil, nil, model.TimeControllerFunc(time.Now)))
+
+	broker := inmemory.NewEventBroker()
+	handler := &EventHandler{
+		Logger:      zap.NewNop().Sugar(),
+		PostService: postService,
+		Broker:      broker,
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/post/{postID}/events", handler.PostEvents)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("Invalid Post ID", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/api/post/bad/events")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	})
+
+	t.Run("Hidden Post", func(t *testing.T) {
+		for _, post := range []*model.Post{pending, banned} {
+			resp, err := http.Get(server.URL + "/api/post/" + post.ID.Hex() + "/events")
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		}
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/post/"+postID+"/events", nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		reader := bufio.NewReader(resp.Body)
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		require.Equal(t, "retry: 3000\n", line)
+
+		// the comments of a shadow-banned user never reach anyone else
+		hidden, err := model.NewEvent(model.EventCommentAdded, postID, map[string]string{"body": "spam"})
+		require.NoError(t, err)
+		hidden.AuthorID = banned.Author.ID
+		require.NoError(t, broker.Publish(ctx, model.PostTopic(postID), hidden))
+
+		event, err := model.NewEvent(model.EventScoreChanged, postID, map[string]int64{"score": 2})
+		require.NoError(t, err)
+		require.NoError(t, broker.Publish(ctx, model.PostTopic("00000000000000000000000a"), event))
+		require.NoError(t, broker.Publish(ctx, model.PostTopic(postID), event))
+
+		var frame []string
+		for len(frame) < 2 {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			if strings.TrimSpace(line) != "" {
+				frame = append(frame, line)
+			}
+		}
+		require.Equal(t, []string{"event: score-changed\n", "data: {\"score\":2}\n"}, frame)
+	})
+}
@@ -0,0 +1,174 @@
+package route
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"html"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/application"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/route/helpers"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+const feedSize = 50
+
+type FeedHandler struct {
+	Logger      *zap.SugaredLogger
+	PostService *application.PostService
+	// BaseURL is the public address of the site. It must be set, the Host header is up to the client
+	// and the pages are cached publicly.
+	BaseURL string
+}
+
+func (h *FeedHandler) FrontPage(w http.ResponseWriter, r *http.Request) {
+	posts, err := h.PostService.GetAllPosts(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	base := publicURL(h.BaseURL)
+	h.serve(w, r, h.postsFeed(r, base, "asperitas", base+"/", "Newest posts on asperitas", posts))
+}
+
+func (h *FeedHandler) Category(w http.ResponseWriter, r *http.Request) {
+	category := mux.Vars(r)["category"]
+	posts, err := h.PostService.GetPostsByCategory(r.Context(), category)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	base := publicURL(h.BaseURL)
+	h.serve(w, r, h.postsFeed(r, base, "asperitas: "+category, base+"/a/"+url.PathEscape(category), "Newest posts in "+category, posts))
+}
+
+func (h *FeedHandler) User(w http.ResponseWriter, r *http.Request) {
+	userName := mux.Vars(r)["user"]
+	posts, err := h.PostService.GetPostsByUser(r.Context(), userName)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	base := publicURL(h.BaseURL)
+	h.serve(w, r, h.postsFeed(r, base, "asperitas: "+userName, base+"/u/"+url.PathEscape(userName), "Newest posts by "+userName, posts))
+}
+
+func (h *FeedHandler) Comments(w http.ResponseWriter, r *http.Request) {
+	post, err := h.PostService.GetPostComments(r.Context(), mux.Vars(r)["postID"])
+	if err == model.ErrInvalidPostID || err == model.ErrPostNotFound {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	base := publicURL(h.BaseURL)
+	link := postURL(base, post)
+	feed := &helpers.Feed{
+		Title:       "Comments on " + post.Title,
+		Link:        link,
+		Self:        base + r.URL.RequestURI(),
+		Description: "Newest comments on " + post.Title,
+		Items:       make([]*helpers.FeedItem, 0, len(post.Comments)),
+	}
+	feed.Updated, _ = post.CreatedAt()
+	for _, comment := range post.Comments {
+		created, err := comment.CreatedAt()
+		if err != nil {
+			h.Logger.Warnw("skipping comment with invalid date", "comment", comment.ID.Hex(), "error", err)
+			continue
+		}
+		item := &helpers.FeedItem{
+			ID:        link + "#" + comment.ID.Hex(),
+			Title:     "Comment on " + post.Title,
+			Link:      link + "#" + comment.ID.Hex(),
+			Content:   comment.HTML,
+			Published: created,
+		}
+		if comment.Author != nil {
+			item.Author = comment.Author.Username
+			item.Title = comment.Author.Username + " on " + post.Title
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	h.serve(w, r, feed)
+}
+
+func (h *FeedHandler) postsFeed(r *http.Request, base string, title string, link string, description string, posts []*model.Post) *helpers.Feed {
+	feed := &helpers.Feed{
+		Title:       title,
+		Link:        link,
+		Self:        base + r.URL.RequestURI(),
+		Description: description,
+		Items:       make([]*helpers.FeedItem, 0, len(posts)),
+	}
+	for _, post := range posts {
+		created, err := post.CreatedAt()
+		if err != nil {
+			h.Logger.Warnw("skipping post with invalid date", "post", post.ID.Hex(), "error", err)
+			continue
+		}
+		item := &helpers.FeedItem{
+			ID:        postURL(base, post),
+			Title:     post.Title,
+			Link:      postURL(base, post),
+			Content:   post.HTML,
+			Published: created,
+		}
+		if post.Type == "link" && post.Url != "" {
+			item.Content = `<p><a href="` + html.EscapeString(post.Url) + `">` + html.EscapeString(post.Url) + `</a></p>`
+		}
+		if post.Author != nil {
+			item.Author = post.Author.Username
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return feed
+}
+
+// serve renders the newest items in the requested format and answers conditional requests with 304.
+func (h *FeedHandler) serve(w http.ResponseWriter, r *http.Request, feed *helpers.Feed) {
+	sort.SliceStable(feed.Items, func(i, j int) bool {
+		return feed.Items[i].Published.After(feed.Items[j].Published)
+	})
+	if len(feed.Items) > feedSize {
+		feed.Items = feed.Items[:feedSize]
+	}
+	if len(feed.Items) > 0 && feed.Items[0].Published.After(feed.Updated) {
+		feed.Updated = feed.Items[0].Published
+	}
+
+	body, contentType, err := helpers.RenderFeed(feed, mux.Vars(r)["format"])
+	if err != nil {
+		h.Logger.Errorw("feed rendering failed", "url", r.URL.String(), "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha1.Sum(body)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	http.ServeContent(w, r, "", feed.Updated.Truncate(time.Second), bytes.NewReader(body))
+}
+
+// publicURL returns the configured site address without a trailing slash.
+func publicURL(configured string) string {
+	return strings.TrimSuffix(configured, "/")
+}
+
+func postURL(base string, post *model.Post) string {
+	return base + "/a/" + url.PathEscape(post.Category) + "/" + post.ID.Hex()
+}
@@ -0,0 +1,196 @@
+package route
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/application"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+type FakePostStorage struct {
+	model.IPostStorage
+	Posts []*model.Post
+	mu    sync.Mutex
+}
+
+func (s *FakePostStorage) GetAllPosts(ctx context.Context) ([]*model.Post, error) {
+	return s.Posts, nil
+}
+
+func (s *FakePostStorage) GetPostsByCategory(ctx context.Context, category string) ([]*model.Post, error) {
+	posts := make([]*model.Post, 0)
+	for _, post := range s.Posts {
+		if post.Category == category {
+			posts = append(posts, post)
+		}
+	}
+	return posts, nil
+}
+
+func (s *FakePostStorage) GetPostByID(ctx context.Context, postID primitive.ObjectID) (*model.Post, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, post := range s.Posts {
+		if post.ID == postID {
+			return post, nil
+		}
+	}
+	return nil, model.ErrPostNotFound
+}
+
+func (s *FakePostStorage) AddComment(ctx context.Context, post *model.Post, comment *model.Comment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	comment.ID = primitive.NewObjectID()
+	comment.PostID = post.ID
+	post.Comments = append(post.Comments, comment)
+	return nil
+}
+
+func (s *FakePostStorage) Vote(ctx context.Context, post *model.Post, vote *model.Vote) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range post.Votes {
+		if existing.UserID == vote.UserID {
+			existing.Score = vote.Score
+			return nil
+		}
+	}
+	post.Votes = append(post.Votes, vote)
+	return nil
+}
+
+func (s *FakePostStorage) UpdateScore(ctx context.Context, post *model.Post) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, stored := range s.Posts {
+		if stored.ID == post.ID {
+			stored.Score = 0
+			for _, vote := range post.Votes {
+				stored.Score += vote.Score
+			}
+		}
+	}
+	return nil
+}
+
+func TestFeedHandler(t *testing.T) {
+	older := &model.Post{
+		ID:       primitive.NewObjectID(),
+		Category: "music",
+		Title:    "Older",
+		Type:     "text",
+		Text:     "*hello*",
+		Created:  "2023-05-01T10:00:00.000Z",
+		Author:   &model.Author{ID: "id1", Username: "alice"},
+		Comments: []*model.Comment{
+			{ID: primitive.NewObjectID(), Body: "reply", Created: "2023-05-03T10:00:00.000Z", Author: &model.Author{ID: "id2", Username: "bob"}},
+		},
+	}
+	newer := &model.Post{
+		ID:       primitive.NewObjectID(),
+		Category: "news",
+		Title:    "Newer",
+		Type:     "link",
+		Url:      "https://example.com/?a=1&b=2",
+		Created:  "2023-05-02T10:00:00.000Z",
+		Author:   &model.Author{ID: "id2", Username: "bob"},
+	}
+	postService := application.NewPostService(&FakePostStorage{Posts: []*model.Post{older, newer}}, nil, nil)
+
+	handler := &FeedHandler{
+		Logger:      zap.NewNop().Sugar(),
+		PostService: postService,
+		BaseURL:     "https://asperitas.test/",
+	}
+	router := mux.NewRouter()
+	router.HandleFunc("/feeds/{format:rss|atom}", handler.FrontPage)
+	router.HandleFunc("/feeds/{format:rss|atom}/post/{postID}", handler.Comments)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	t.Run("RSS", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/feeds/rss")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "application/rss+xml; charset=utf-8", resp.Header.Get("Content-Type"))
+		require.Equal(t, "Tue, 02 May 2023 10:00:00 GMT", resp.Header.Get("Last-Modified"))
+		require.NotEmpty(t, resp.Header.Get("ETag"))
+
+		var feed struct {
+			Items []struct {
+				Title       string `xml:"title"`
+				Link        string `xml:"link"`
+				Description string `xml:"description"`
+			} `xml:"channel>item"`
+		}
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, xml.Unmarshal(body, &feed))
+		require.Len(t, feed.Items, 2)
+		require.Equal(t, "Newer", feed.Items[0].Title)
+		require.Equal(t, "https://asperitas.test/a/news/"+newer.ID.Hex(), feed.Items[0].Link)
+		require.Contains(t, feed.Items[0].Description, `href="https://example.com/?a=1&amp;b=2"`)
+		require.Contains(t, feed.Items[1].Description, "<em>hello</em>")
+	})
+
+	t.Run("Not Modified", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/feeds/atom")
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, "application/atom+xml; charset=utf-8", resp.Header.Get("Content-Type"))
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/feeds/atom", nil)
+		require.NoError(t, err)
+		req.Header.Set("If-None-Match", resp.Header.Get("ETag"))
+		resp, err = http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+		req.Header.Del("If-None-Match")
+		req.Header.Set("If-Modified-Since", "Tue, 02 May 2023 10:00:00 GMT")
+		resp, err = http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusNotModified, resp.StatusCode)
+	})
+
+	t.Run("Comments", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/feeds/atom/post/" + older.ID.Hex())
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "Wed, 03 May 2023 10:00:00 GMT", resp.Header.Get("Last-Modified"))
+
+		var feed struct {
+			Entries []struct {
+				Title  string `xml:"title"`
+				Author string `xml:"author>name"`
+			} `xml:"entry"`
+		}
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, xml.Unmarshal(body, &feed))
+		require.Len(t, feed.Entries, 1)
+		require.Equal(t, "bob", feed.Entries[0].Author)
+	})
+
+	t.Run("Unknown Post", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/feeds/rss/post/" + primitive.NewObjectID().Hex())
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -0,0 +1,161 @@
+package route
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/application"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/route/helpers"
+	"github.com/gorilla/mux"
+
+	"go.uber.org/zap"
+)
+
+type MessageHandler struct {
+	Logger         *zap.SugaredLogger
+	MessageService *application.MessageService
+}
+
+func (h *MessageHandler) GetConversations(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	query := r.URL.Query()
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	limit, _ := strconv.Atoi(query.Get("limit"))
+
+	conversations, err := h.MessageService.GetConversations(r.Context(), offset, limit)
+	if err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.SendResponse(w, http.StatusOK, conversations)
+}
+
+func (h *MessageHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var data map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, ok := data["to"]
+	if !ok {
+		msg, err := model.NewErrorStack("body", "to", "", "is required")
+		if err != nil {
+			http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+			return
+		}
+		http.Error(w, msg, http.StatusUnprocessableEntity)
+		return
+	}
+
+	message, err := h.MessageService.SendMessage(r.Context(), to, data["body"])
+	if err != nil {
+		h.sendError(w, err, data["body"])
+		return
+	}
+
+	helpers.SendResponse(w, http.StatusCreated, message)
+}
+
+func (h *MessageHandler) Reply(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	vars := mux.Vars(r)
+	conversationID, found := vars["conversationID"]
+	if !found {
+		http.Error(w, model.ErrConversationInvalidHTTP.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	var data map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	message, err := h.MessageService.Reply(r.Context(), conversationID, data["body"])
+	if err != nil {
+		h.sendError(w, err, data["body"])
+		return
+	}
+
+	helpers.SendResponse(w, http.StatusCreated, message)
+}
+
+func (h *MessageHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	vars := mux.Vars(r)
+	conversationID, found := vars["conversationID"]
+	if !found {
+		http.Error(w, model.ErrConversationInvalidHTTP.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	query := r.URL.Query()
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	limit, _ := strconv.Atoi(query.Get("limit"))
+
+	messages, err := h.MessageService.GetMessages(r.Context(), conversationID, offset, limit)
+	if err != nil {
+		h.sendError(w, err, "")
+		return
+	}
+
+	helpers.SendResponse(w, http.StatusOK, messages)
+}
+
+func (h *MessageHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	vars := mux.Vars(r)
+	conversationID, found := vars["conversationID"]
+	if !found {
+		http.Error(w, model.ErrConversationInvalidHTTP.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := h.MessageService.MarkRead(r.Context(), conversationID); err != nil {
+		h.sendError(w, err, "")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"success"}`))
+}
+
+func (h *MessageHandler) CountUnread(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	count, err := h.MessageService.CountUnread(r.Context())
+	if err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.SendResponse(w, http.StatusOK, map[string]interface{}{
+		"count": count,
+	})
+}
+
+func (h *MessageHandler) sendError(w http.ResponseWriter, err error, body string) {
+	switch err {
+	case model.ErrMessageEmpty, model.ErrMessageTooLong:
+		value, msg := "", "is required"
+		if err == model.ErrMessageTooLong {
+			value, msg = body, "must be at most "+strconv.Itoa(model.MessageMaxLength)+" characters long"
+		}
+		stack, err := model.NewErrorStack("body", "body", value, msg)
+		if err != nil {
+			http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+			return
+		}
+		http.Error(w, stack, http.StatusUnprocessableEntity)
+	case model.ErrInvalidConversationID, model.ErrInvalidRecipient:
+		http.Error(w, helpers.HTTPError(err), http.StatusUnprocessableEntity)
+	case model.ErrConversationNotFound:
+		http.Error(w, helpers.HTTPError(err), http.StatusNotFound)
+	case model.ErrBlocked:
+		http.Error(w, helpers.HTTPError(err), http.StatusForbidden)
+	default:
+		http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+	}
+}
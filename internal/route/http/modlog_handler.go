@@ -0,0 +1,39 @@
+package route
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/application"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/route/helpers"
+
+	"go.uber.org/zap"
+)
+
+type ModLogHandler struct {
+	Logger        *zap.SugaredLogger
+	ModLogService *application.ModLogService
+}
+
+// GetModLog lists the moderation log, filtered by the category, moderator and action query parameters.
+func (h *ModLogHandler) GetModLog(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	query := r.URL.Query()
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	limit, _ := strconv.Atoi(query.Get("limit"))
+
+	actions, err := h.ModLogService.GetModLog(r.Context(), model.ModLogFilter{
+		Category:  query.Get("category"),
+		Moderator: query.Get("moderator"),
+		Action:    query.Get("action"),
+		Offset:    offset,
+		Limit:     limit,
+	})
+	if err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.SendResponse(w, http.StatusOK, actions)
+}
@@ -2,8 +2,11 @@ package route
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
 	"path/filepath"
+	"strconv"
 
 	"github.com/Totus-Floreo/asperitas-on-go/internal/application"
 	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
@@ -56,13 +59,13 @@ func (h *PostHandler) GetPostsByCategory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	posts, err := h.PostService.GetPostsByCategory(r.Context(), postCategory)
+	listing, err := h.PostService.GetCategoryListing(r.Context(), postCategory)
 	if err != nil {
 		http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
 		return
 	}
 
-	helpers.SendResponse(w, http.StatusOK, posts)
+	helpers.SendResponse(w, http.StatusOK, listing)
 }
 
 func (h *PostHandler) GetPostsByUser(w http.ResponseWriter, r *http.Request) {
@@ -83,6 +86,29 @@ func (h *PostHandler) GetPostsByUser(w http.ResponseWriter, r *http.Request) {
 	helpers.SendResponse(w, http.StatusOK, posts)
 }
 
+func (h *PostHandler) GetCommentsByUser(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	vars := mux.Vars(r)
+	userName, found := vars["user"]
+	if !found {
+		http.Error(w, model.ErrUserInvalidHTTP.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	offset, limit, err := pageParams(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	comments, err := h.PostService.GetCommentsByUser(r.Context(), userName, offset, limit)
+	if err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.SendResponse(w, http.StatusOK, comments)
+}
+
 func (h *PostHandler) AddPost(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	post := model.NewPost()
@@ -90,12 +116,39 @@ func (h *PostHandler) AddPost(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, helpers.HTTPError(err), http.StatusBadRequest)
 		return
 	}
-	if post.Text == "" || post.Title == "" {
+	if post.Title == "" || (post.Text == "" && post.Poll == nil && post.Image == nil) {
 		http.Error(w, helpers.HTTPError(model.ErrPostInvalidHTTP), http.StatusBadRequest)
 		return
 	}
 
 	response, err := h.PostService.AddPost(r.Context(), post)
+	if err == model.ErrPostTooLong {
+		msg, err := model.NewErrorStack("body", "text", post.Text, "must be at most "+strconv.Itoa(model.PostTextMaxLength)+" characters long")
+		if err != nil {
+			http.Error(w, helpers.HTTPError(err), http.StatusUnprocessableEntity)
+			return
+		}
+		http.Error(w, msg, http.StatusUnprocessableEntity)
+		return
+	}
+	if err == model.ErrInvalidPoll {
+		msg, err := model.NewErrorStack("body", "poll", "", "must have 2-10 non-empty options and a future closing time")
+		if err != nil {
+			http.Error(w, helpers.HTTPError(err), http.StatusUnprocessableEntity)
+			return
+		}
+		http.Error(w, msg, http.StatusUnprocessableEntity)
+		return
+	}
+	if err == model.ErrInvalidImage {
+		msg, err := model.NewErrorStack("body", "image", "", "is invalid")
+		if err != nil {
+			http.Error(w, helpers.HTTPError(err), http.StatusUnprocessableEntity)
+			return
+		}
+		http.Error(w, msg, http.StatusUnprocessableEntity)
+		return
+	}
 	if err == model.ErrInvalidUrl {
 		msg, err := model.NewErrorStack("body", "url", post.Url, "is invalid")
 		if err != nil {
@@ -105,6 +158,20 @@ func (h *PostHandler) AddPost(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, msg, http.StatusUnprocessableEntity)
 		return
 	}
+	if err == model.ErrContentRejected {
+		http.Error(w, helpers.HTTPError(err), http.StatusForbidden)
+		return
+	}
+	var banErr *model.BanError
+	if errors.As(err, &banErr) {
+		http.Error(w, banErr.Error(), http.StatusForbidden)
+		return
+	}
+	var stack *model.ErrorStack
+	if errors.As(err, &stack) {
+		http.Error(w, stack.Error(), http.StatusUnprocessableEntity)
+		return
+	}
 	if err != nil {
 		http.Error(w, helpers.HTTPError(err), http.StatusBadRequest)
 		return
@@ -113,6 +180,31 @@ func (h *PostHandler) AddPost(w http.ResponseWriter, r *http.Request) {
 	helpers.SendResponse(w, http.StatusCreated, response)
 }
 
+// GetDuplicates lists the posts of the link given as the url query parameter, optionally within a category,
+// so that clients can warn before submitting it again.
+func (h *PostHandler) GetDuplicates(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	query := r.URL.Query()
+	link := query.Get("url")
+
+	duplicates, err := h.PostService.GetDuplicates(r.Context(), link, query.Get("category"))
+	if err == model.ErrInvalidUrl {
+		msg, err := model.NewErrorStack("query", "url", link, "is invalid")
+		if err != nil {
+			http.Error(w, helpers.HTTPError(err), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.SendResponse(w, http.StatusOK, duplicates)
+}
+
 func (h *PostHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	vars := mux.Vars(r)
@@ -157,7 +249,16 @@ func (h *PostHandler) AddComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	post, err := h.PostService.AddComment(r.Context(), postID, comment)
+	post, err := h.PostService.AddComment(r.Context(), postID, comment, data["parent"])
+	if err == model.ErrPostLocked || err == model.ErrPostArchived || err == model.ErrPostPending || err == model.ErrBlocked || err == model.ErrContentRejected {
+		http.Error(w, helpers.HTTPError(err), http.StatusForbidden)
+		return
+	}
+	var banErr *model.BanError
+	if errors.As(err, &banErr) {
+		http.Error(w, banErr.Error(), http.StatusForbidden)
+		return
+	}
 	if err == model.ErrCommentTooLong {
 		msg, err := model.NewErrorStack("body", "comment", comment, "must be at most 2000 characters long")
 		if err != nil {
@@ -199,6 +300,64 @@ func (h *PostHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
 	helpers.SendResponse(w, http.StatusOK, post)
 }
 
+// RestorePost brings back a deleted post within the restore window.
+func (h *PostHandler) RestorePost(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	vars := mux.Vars(r)
+	postID, found := vars["postID"]
+	if !found {
+		http.Error(w, model.ErrPostInvalidHTTP.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	post, err := h.PostService.RestorePost(r.Context(), postID)
+	if err != nil {
+		h.restoreError(w, err)
+		return
+	}
+
+	helpers.SendResponse(w, http.StatusOK, post)
+}
+
+// RestoreComment brings back a deleted comment within the restore window.
+func (h *PostHandler) RestoreComment(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	vars := mux.Vars(r)
+	postID, found := vars["postID"]
+	if !found {
+		http.Error(w, model.ErrPostInvalidHTTP.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	commentID, found := vars["commentID"]
+	if !found {
+		http.Error(w, model.ErrCommentInvalidHTTP.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	post, err := h.PostService.RestoreComment(r.Context(), postID, commentID)
+	if err != nil {
+		h.restoreError(w, err)
+		return
+	}
+
+	helpers.SendResponse(w, http.StatusOK, post)
+}
+
+func (h *PostHandler) restoreError(w http.ResponseWriter, err error) {
+	switch err {
+	case model.ErrUnAuthorized:
+		http.Error(w, helpers.HTTPError(err), http.StatusForbidden)
+	case model.ErrInvalidPostID, model.ErrInvalidCommentID:
+		http.Error(w, helpers.HTTPError(err), http.StatusBadRequest)
+	case model.ErrNotDeleted:
+		http.Error(w, helpers.HTTPError(err), http.StatusConflict)
+	case model.ErrRestoreExpired:
+		http.Error(w, helpers.HTTPError(err), http.StatusGone)
+	default:
+		http.Error(w, helpers.HTTPError(err), http.StatusNotFound)
+	}
+}
+
 func (h *PostHandler) Vote(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	vars := mux.Vars(r)
@@ -209,6 +368,48 @@ func (h *PostHandler) Vote(w http.ResponseWriter, r *http.Request) {
 	}
 
 	post, err := h.PostService.Vote(r.Context(), postID, filepath.Base(filepath.Clean(r.URL.Path)))
+	if err == model.ErrPostLocked || err == model.ErrPostArchived || err == model.ErrPostPending {
+		http.Error(w, helpers.HTTPError(err), http.StatusForbidden)
+		return
+	}
+	var banErr *model.BanError
+	if errors.As(err, &banErr) {
+		http.Error(w, banErr.Error(), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusNotFound)
+		return
+	}
+
+	helpers.SendResponse(w, http.StatusOK, post)
+}
+
+// VoteComment upvotes, downvotes or unvotes a comment, the method is the last segment of the path.
+func (h *PostHandler) VoteComment(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	vars := mux.Vars(r)
+	postID, found := vars["postID"]
+	if !found {
+		http.Error(w, model.ErrPostInvalidHTTP.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	commentID, found := vars["commentID"]
+	if !found {
+		http.Error(w, model.ErrCommentInvalidHTTP.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	post, err := h.PostService.VoteComment(r.Context(), postID, commentID, filepath.Base(filepath.Clean(r.URL.Path)))
+	if err == model.ErrPostLocked || err == model.ErrPostArchived || err == model.ErrPostPending {
+		http.Error(w, helpers.HTTPError(err), http.StatusForbidden)
+		return
+	}
+	var banErr *model.BanError
+	if errors.As(err, &banErr) {
+		http.Error(w, banErr.Error(), http.StatusForbidden)
+		return
+	}
 	if err != nil {
 		http.Error(w, helpers.HTTPError(err), http.StatusNotFound)
 		return
@@ -216,3 +417,205 @@ func (h *PostHandler) Vote(w http.ResponseWriter, r *http.Request) {
 
 	helpers.SendResponse(w, http.StatusOK, post)
 }
+
+func (h *PostHandler) Moderate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	vars := mux.Vars(r)
+	postID, found := vars["postID"]
+	if !found {
+		http.Error(w, model.ErrPostInvalidHTTP.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	var data struct {
+		Reason string `json:"reason"`
+		Rule   int    `json:"rule"`
+	}
+	if err := helpers.DecodeOptionalBody(r, &data); err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusBadRequest)
+		return
+	}
+
+	post, err := h.PostService.Moderate(r.Context(), postID, filepath.Base(filepath.Clean(r.URL.Path)), data.Reason, data.Rule)
+	if err == model.ErrUnAuthorized {
+		http.Error(w, helpers.HTTPError(err), http.StatusForbidden)
+		return
+	}
+	if err == model.ErrModerateActionNotImplement || err == model.ErrInvalidRule {
+		http.Error(w, helpers.HTTPError(err), http.StatusBadRequest)
+		return
+	}
+	if err == model.ErrNotHeld {
+		http.Error(w, helpers.HTTPError(err), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusNotFound)
+		return
+	}
+
+	helpers.SendResponse(w, http.StatusOK, post)
+}
+
+func (h *PostHandler) ModerateComment(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	vars := mux.Vars(r)
+	postID, found := vars["postID"]
+	if !found {
+		http.Error(w, model.ErrPostInvalidHTTP.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	commentID, found := vars["commentID"]
+	if !found {
+		http.Error(w, model.ErrCommentInvalidHTTP.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	var data struct {
+		Reason string `json:"reason"`
+		Rule   int    `json:"rule"`
+	}
+	if err := helpers.DecodeOptionalBody(r, &data); err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusBadRequest)
+		return
+	}
+
+	post, err := h.PostService.ModerateComment(r.Context(), postID, commentID, filepath.Base(filepath.Clean(r.URL.Path)), data.Reason, data.Rule)
+	if err == model.ErrUnAuthorized {
+		http.Error(w, helpers.HTTPError(err), http.StatusForbidden)
+		return
+	}
+	if err == model.ErrModerateActionNotImplement || err == model.ErrInvalidPostID || err == model.ErrInvalidCommentID || err == model.ErrInvalidRule {
+		http.Error(w, helpers.HTTPError(err), http.StatusBadRequest)
+		return
+	}
+	if err == model.ErrNotHeld {
+		http.Error(w, helpers.HTTPError(err), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusNotFound)
+		return
+	}
+
+	helpers.SendResponse(w, http.StatusOK, post)
+}
+
+// GetHeld lists what the content filters held for moderation.
+func (h *PostHandler) GetHeld(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	held, err := h.PostService.GetHeld(r.Context())
+	if err == model.ErrUnAuthorized {
+		http.Error(w, helpers.HTTPError(err), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.SendResponse(w, http.StatusOK, held)
+}
+
+// TrainFilters feeds an example of spam or ham to the trainable content filters.
+func (h *PostHandler) TrainFilters(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var data struct {
+		Title string `json:"title"`
+		Text  string `json:"text"`
+		Url   string `json:"url"`
+		Spam  *bool  `json:"spam"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusBadRequest)
+		return
+	}
+	if data.Spam == nil {
+		msg, err := model.NewErrorStack("body", "spam", "", "is required")
+		if err != nil {
+			http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+			return
+		}
+		http.Error(w, msg, http.StatusUnprocessableEntity)
+		return
+	}
+
+	content := &model.Content{Title: data.Title, Text: data.Text, Url: data.Url}
+	err := h.PostService.TrainFilters(r.Context(), content, *data.Spam)
+	if err == model.ErrUnAuthorized {
+		http.Error(w, helpers.HTTPError(err), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *PostHandler) VotePoll(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	vars := mux.Vars(r)
+	postID, found := vars["postID"]
+	if !found {
+		http.Error(w, model.ErrPostInvalidHTTP.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	var data map[string]int
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	option, ok := data["option"]
+	if !ok {
+		msg, err := model.NewErrorStack("body", "option", "", "is required")
+		if err != nil {
+			http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+			return
+		}
+		http.Error(w, msg, http.StatusUnprocessableEntity)
+		return
+	}
+
+	post, err := h.PostService.VotePoll(r.Context(), postID, option)
+	var banErr *model.BanError
+	if errors.As(err, &banErr) {
+		http.Error(w, banErr.Error(), http.StatusForbidden)
+		return
+	}
+	switch err {
+	case nil:
+	case model.ErrPostLocked, model.ErrPostArchived, model.ErrPostPending, model.ErrPollClosed:
+		http.Error(w, helpers.HTTPError(err), http.StatusForbidden)
+		return
+	case model.ErrPostNotPoll, model.ErrInvalidPollOption:
+		http.Error(w, helpers.HTTPError(err), http.StatusUnprocessableEntity)
+		return
+	default:
+		http.Error(w, helpers.HTTPError(err), http.StatusNotFound)
+		return
+	}
+
+	helpers.SendResponse(w, http.StatusOK, post)
+}
+
+// pageParams reads the optional offset and limit of a listing, a missing value is zero.
+func pageParams(query url.Values) (int, int, error) {
+	values := make([]int, 2)
+	for i, key := range []string{"offset", "limit"} {
+		raw := query.Get(key)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 0 {
+			return 0, 0, model.ErrPageInvalidHTTP
+		}
+		values[i] = value
+	}
+	return values[0], values[1], nil
+}
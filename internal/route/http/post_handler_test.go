@@ -0,0 +1,31 @@
+package route
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/application"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestPostHandler_GetCommentsByUser_InvalidPage(t *testing.T) {
+	handler := &PostHandler{
+		Logger:      zap.NewNop().Sugar(),
+		PostService: application.NewPostService(new(FakePostStorage), nil, nil),
+	}
+	router := mux.NewRouter()
+	router.HandleFunc("/api/user/{user}/comments", handler.GetCommentsByUser)
+
+	for _, query := range []string{"offset=ten", "limit=5x", "offset=-1", "offset=0&limit=-5"} {
+		t.Run(query, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/user/alice/comments?"+query, nil))
+			require.Equal(t, http.StatusBadRequest, recorder.Code)
+			require.JSONEq(t, model.ErrPageInvalidHTTP.Error(), recorder.Body.String())
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package route
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/application"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/route/helpers"
+	"github.com/gorilla/mux"
+
+	"go.uber.org/zap"
+)
+
+type ProfileHandler struct {
+	Logger         *zap.SugaredLogger
+	ProfileService *application.ProfileService
+}
+
+func (h *ProfileHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	vars := mux.Vars(r)
+	username, found := vars["user"]
+	if !found {
+		http.Error(w, model.ErrUserInvalidHTTP.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	profile, err := h.ProfileService.GetProfile(r.Context(), username)
+	if err == model.ErrUserNotFound {
+		http.Error(w, helpers.HTTPError(err), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.SendResponse(w, http.StatusOK, profile)
+}
+
+func (h *ProfileHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var data struct {
+		Bio    string `json:"bio"`
+		Avatar string `json:"avatar"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	profile, err := h.ProfileService.UpdateProfile(r.Context(), data.Bio, data.Avatar)
+	if err == model.ErrBioTooLong {
+		msg, err := model.NewErrorStack("body", "bio", data.Bio, "must be at most "+strconv.Itoa(model.BioMaxLength)+" characters long")
+		if err != nil {
+			http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+			return
+		}
+		http.Error(w, msg, http.StatusUnprocessableEntity)
+		return
+	}
+	if err == model.ErrInvalidAvatar {
+		msg, err := model.NewErrorStack("body", "avatar", data.Avatar, "must be an uploaded image")
+		if err != nil {
+			http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+			return
+		}
+		http.Error(w, msg, http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.SendResponse(w, http.StatusOK, profile)
+}
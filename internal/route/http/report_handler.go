@@ -0,0 +1,133 @@
+package route
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/application"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/route/helpers"
+	"github.com/gorilla/mux"
+
+	"go.uber.org/zap"
+)
+
+type ReportHandler struct {
+	Logger        *zap.SugaredLogger
+	ReportService *application.ReportService
+}
+
+func (h *ReportHandler) Report(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var data struct {
+		Post    string `json:"post"`
+		Comment string `json:"comment"`
+		Reason  string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusBadRequest)
+		return
+	}
+	if data.Post == "" {
+		msg, err := model.NewErrorStack("body", "post", "", "is required")
+		if err != nil {
+			http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+			return
+		}
+		http.Error(w, msg, http.StatusUnprocessableEntity)
+		return
+	}
+
+	report, err := h.ReportService.Report(r.Context(), data.Post, data.Comment, data.Reason)
+	if err == model.ErrReasonEmpty || err == model.ErrReasonTooLong {
+		problem := "is required"
+		if err == model.ErrReasonTooLong {
+			problem = "must be at most 500 characters long"
+		}
+		msg, err := model.NewErrorStack("body", "reason", data.Reason, problem)
+		if err != nil {
+			http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+			return
+		}
+		http.Error(w, msg, http.StatusUnprocessableEntity)
+		return
+	}
+	if err == model.ErrInvalidPostID || err == model.ErrInvalidCommentID {
+		http.Error(w, helpers.HTTPError(err), http.StatusBadRequest)
+		return
+	}
+	if err == model.ErrAlreadyReported {
+		http.Error(w, helpers.HTTPError(err), http.StatusConflict)
+		return
+	}
+	if err == model.ErrPostNotFound || err == model.ErrCommentNotFound {
+		http.Error(w, helpers.HTTPError(err), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.SendResponse(w, http.StatusCreated, report)
+}
+
+// GetQueue lists a page of the open reports grouped by the reported post or comment.
+func (h *ReportHandler) GetQueue(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	offset, limit, err := pageParams(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	queue, err := h.ReportService.GetQueue(r.Context(), offset, limit)
+	if err == model.ErrUnAuthorized {
+		http.Error(w, helpers.HTTPError(err), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.SendResponse(w, http.StatusOK, queue)
+}
+
+// Resolve dismisses, removes or warns about the reported post or comment, the action is the last path segment.
+func (h *ReportHandler) Resolve(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	vars := mux.Vars(r)
+	postID, found := vars["postID"]
+	if !found {
+		http.Error(w, model.ErrPostInvalidHTTP.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	var data struct {
+		Note string `json:"note"`
+		Rule int    `json:"rule"`
+	}
+	if err := helpers.DecodeOptionalBody(r, &data); err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusBadRequest)
+		return
+	}
+
+	reports, err := h.ReportService.Resolve(r.Context(), postID, vars["commentID"], filepath.Base(filepath.Clean(r.URL.Path)), data.Note, data.Rule)
+	if err == model.ErrUnAuthorized {
+		http.Error(w, helpers.HTTPError(err), http.StatusForbidden)
+		return
+	}
+	if err == model.ErrModerateActionNotImplement || err == model.ErrInvalidPostID || err == model.ErrInvalidCommentID || err == model.ErrInvalidRule {
+		http.Error(w, helpers.HTTPError(err), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusNotFound)
+		return
+	}
+
+	helpers.SendResponse(w, http.StatusOK, reports)
+}
@@ -1,9 +1,30 @@
 package route
 
 import (
+	"io"
+	"mime"
 	"net/http"
+	"path"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
 )
 
 func StaticHandler() http.Handler {
 	return http.StripPrefix("/static/", http.FileServer(http.Dir("./web/template/static")))
 }
+
+func UploadsHandler(prefix string, blobStorage model.IBlobStorage) http.Handler {
+	return http.StripPrefix(prefix, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		blob, err := blobStorage.Get(r.Context(), r.URL.Path)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		defer blob.Close()
+
+		w.Header().Set("Content-Type", mime.TypeByExtension(path.Ext(r.URL.Path)))
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		io.Copy(w, blob)
+	}))
+}
@@ -0,0 +1,70 @@
+package route
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/application"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/route/helpers"
+
+	"go.uber.org/zap"
+)
+
+// multipartOverhead leaves room for the form boundaries and headers around the file.
+const multipartOverhead = 64 << 10
+
+type UploadHandler struct {
+	Logger       *zap.SugaredLogger
+	ImageService *application.ImageService
+}
+
+func (h *UploadHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	maxSize := h.ImageService.MaxSize()
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+multipartOverhead)
+
+	file, _, err := r.FormFile("image")
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		http.Error(w, helpers.HTTPError(model.ErrImageTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		msg, err := model.NewErrorStack("body", "image", "", "is required")
+		if err != nil {
+			http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+			return
+		}
+		http.Error(w, msg, http.StatusUnprocessableEntity)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		http.Error(w, helpers.HTTPError(err), http.StatusBadRequest)
+		return
+	}
+
+	image, err := h.ImageService.Upload(r.Context(), data)
+	switch err {
+	case nil:
+	case model.ErrImageTooLarge:
+		http.Error(w, helpers.HTTPError(err), http.StatusRequestEntityTooLarge)
+		return
+	case model.ErrImageUnsupported:
+		http.Error(w, helpers.HTTPError(err), http.StatusUnsupportedMediaType)
+		return
+	case model.ErrInvalidImage:
+		http.Error(w, helpers.HTTPError(err), http.StatusUnprocessableEntity)
+		return
+	default:
+		h.Logger.Errorw("image upload failed", "error", err)
+		http.Error(w, helpers.HTTPError(err), http.StatusInternalServerError)
+		return
+	}
+
+	helpers.SendResponse(w, http.StatusCreated, image)
+}
@@ -2,6 +2,7 @@ package route
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
@@ -67,6 +68,11 @@ func (h *UserHandler) LogIn(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, helpers.HTTPError(err), http.StatusUnauthorized)
 			return
 		}
+		var banErr *model.BanError
+		if errors.As(err, &banErr) {
+			http.Error(w, banErr.Error(), http.StatusForbidden)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -3,13 +3,255 @@ package route
 import (
 	"html/template"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/application"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/route/helpers"
+	"go.uber.org/zap"
+)
+
+const (
+	webTemplates      = "./web/template/*.html"
+	webListSize       = 25
+	webSummaryLength  = 200
+	webItemTextLength = 140
 )
 
-func WebHandler(w http.ResponseWriter, r *http.Request) {
-	template := template.Must(template.ParseGlob("./web/template/*.html"))
-	err := template.ExecuteTemplate(w, "index.html", nil)
+// WebHandler serves the frontend shell with meta tags and a noscript summary rendered for the requested page.
+type WebHandler struct {
+	Logger      *zap.SugaredLogger
+	PostService *application.PostService
+	// BaseURL is the public address of the site. It must be set, the Host header is up to the client
+	// and the pages are cached publicly.
+	BaseURL string
+	// Templates is the glob the shell is parsed from, webTemplates when empty.
+	Templates string
+}
+
+type webPage struct {
+	Title       string
+	Description string
+	URL         string
+	Type        string
+	Image       string
+	Feed        string
+	Heading     string
+	Body        template.HTML
+	Items       []*webItem
+}
+
+type webItem struct {
+	Title  string
+	URL    string
+	Author string
+	Text   string
+}
+
+func (h *WebHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	base := publicURL(h.BaseURL)
+	page := &webPage{
+		Title:       "asperitas",
+		Description: "A reddit-like place to share links and talk about them",
+		URL:         base + r.URL.EscapedPath(),
+		Type:        "website",
+		Heading:     "asperitas",
+	}
+
+	status := http.StatusOK
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	var err error
+	switch {
+	case r.URL.Path == "/":
+		page.Feed = base + "/feeds/rss"
+		err = h.listing(r, page, base, func() ([]*model.Post, error) {
+			return h.PostService.GetAllPosts(r.Context())
+		})
+	case len(segments) == 2 && segments[0] == "a":
+		page.Title = segments[1] + " - asperitas"
+		page.Heading = segments[1]
+		page.Description = "Posts in " + segments[1] + " on asperitas"
+		page.Feed = base + "/feeds/rss/a/" + url.PathEscape(segments[1])
+		err = h.listing(r, page, base, func() ([]*model.Post, error) {
+			return h.PostService.GetPostsByCategory(r.Context(), segments[1])
+		})
+	case len(segments) == 2 && segments[0] == "u":
+		page.Title = segments[1] + " - asperitas"
+		page.Heading = segments[1]
+		page.Description = "Posts by " + segments[1] + " on asperitas"
+		page.Feed = base + "/feeds/rss/u/" + url.PathEscape(segments[1])
+		err = h.listing(r, page, base, func() ([]*model.Post, error) {
+			return h.PostService.GetPostsByUser(r.Context(), segments[1])
+		})
+	case len(segments) == 3 && segments[0] == "a":
+		err = h.post(r, page, base, segments[2])
+		if err == model.ErrPostNotFound || err == model.ErrInvalidPostID {
+			page.Title = "Post not found - asperitas"
+			page.Heading = "Post not found"
+			status = http.StatusNotFound
+			err = nil
+		}
+	}
+	if err != nil {
+		h.Logger.Warnw("page data unavailable, serving the bare shell", "url", r.URL.String(), "error", err)
+	}
+
+	glob := h.Templates
+	if glob == "" {
+		glob = webTemplates
+	}
+	tmpl, err := template.ParseGlob(glob)
 	if err != nil {
 		http.Error(w, `Template errror`, http.StatusInternalServerError)
 		return
 	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := tmpl.ExecuteTemplate(w, "index.html", page); err != nil {
+		h.Logger.Errorw("template execution failed", "url", r.URL.String(), "error", err)
+	}
+}
+
+func (h *WebHandler) listing(r *http.Request, page *webPage, base string, load func() ([]*model.Post, error)) error {
+	posts, err := load()
+	if err != nil {
+		return err
+	}
+	if len(posts) > webListSize {
+		posts = posts[:webListSize]
+	}
+
+	page.Items = make([]*webItem, 0, len(posts))
+	for _, post := range posts {
+		item := &webItem{
+			Title: post.Title,
+			URL:   postURL(base, post),
+			Text:  summarize(postSummary(post), webItemTextLength),
+		}
+		if post.Author != nil {
+			item.Author = post.Author.Username
+		}
+		page.Items = append(page.Items, item)
+	}
+	if page.Image == "" && len(posts) > 0 && posts[0].Image != nil {
+		page.Image = absoluteURL(base, posts[0].Image.URL)
+	}
+	return nil
+}
+
+func (h *WebHandler) post(r *http.Request, page *webPage, base string, postID string) error {
+	post, err := h.PostService.GetPostComments(r.Context(), postID)
+	if err != nil {
+		return err
+	}
+
+	page.Title = post.Title + " - asperitas"
+	page.Heading = post.Title
+	page.Description = summarize(postSummary(post), webSummaryLength)
+	page.URL = postURL(base, post)
+	page.Type = "article"
+	page.Feed = base + "/feeds/rss/post/" + post.ID.Hex()
+	page.Body = template.HTML(post.HTML)
+	if post.Image != nil {
+		page.Image = absoluteURL(base, post.Image.URL)
+	}
+
+	comments := post.Comments
+	if len(comments) > webListSize {
+		comments = comments[:webListSize]
+	}
+	page.Items = make([]*webItem, 0, len(comments))
+	for _, comment := range comments {
+		item := &webItem{
+			Title: "Comment",
+			URL:   page.URL + "#" + comment.ID.Hex(),
+			Text:  summarize(comment.Body, webItemTextLength),
+		}
+		if comment.Author != nil {
+			item.Author = comment.Author.Username
+		}
+		page.Items = append(page.Items, item)
+	}
+	return nil
+}
+
+// Sitemap lists the front page, every category with posts and every post.
+func (h *WebHandler) Sitemap(w http.ResponseWriter, r *http.Request) {
+	posts, err := h.PostService.GetAllPosts(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	base := publicURL(h.BaseURL)
+	home := &helpers.SitemapURL{Loc: base + "/"}
+	urls := []*helpers.SitemapURL{home}
+	categories := make(map[string]*helpers.SitemapURL)
+	for _, post := range posts {
+		modified := lastModified(post)
+		if modified.After(home.LastMod) {
+			home.LastMod = modified
+		}
+
+		category, ok := categories[post.Category]
+		if !ok {
+			category = &helpers.SitemapURL{Loc: base + "/a/" + url.PathEscape(post.Category)}
+			categories[post.Category] = category
+			urls = append(urls, category)
+		}
+		if modified.After(category.LastMod) {
+			category.LastMod = modified
+		}
+
+		urls = append(urls, &helpers.SitemapURL{Loc: postURL(base, post), LastMod: modified})
+	}
+
+	body, err := helpers.RenderSitemap(urls)
+	if err != nil {
+		h.Logger.Errorw("sitemap rendering failed", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Write(body)
+}
+
+// lastModified is the time of the newest comment, or of the post itself.
+func lastModified(post *model.Post) time.Time {
+	modified, _ := post.CreatedAt()
+	for _, comment := range post.Comments {
+		created, err := comment.CreatedAt()
+		if err == nil && created.After(modified) {
+			modified = created
+		}
+	}
+	return modified
+}
+
+func postSummary(post *model.Post) string {
+	if post.Type == "link" {
+		return post.Url
+	}
+	return post.Text
+}
+
+// summarize collapses whitespace and cuts text to at most length runes.
+func summarize(text string, length int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= length {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:length-1])) + "…"
+}
+
+func absoluteURL(base string, link string) string {
+	if strings.HasPrefix(link, "/") {
+		return base + link
+	}
+	return link
 }
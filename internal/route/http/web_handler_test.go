@@ -0,0 +1,89 @@
+package route
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/application"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+func TestWebHandler(t *testing.T) {
+	post := &model.Post{
+		ID:       primitive.NewObjectID(),
+		Category: "music",
+		Title:    `Best "albums" <2023>`,
+		Type:     "text",
+		Text:     "Share   your\nfavourites",
+		Created:  "2023-05-01T10:00:00.000Z",
+		Author:   &model.Author{ID: "id1", Username: "alice"},
+		Image:    &model.Image{URL: "/uploads/cover.png"},
+		Comments: []*model.Comment{
+			{ID: primitive.NewObjectID(), Body: "mine", Created: "2023-05-03T10:00:00.000Z", Author: &model.Author{ID: "id2", Username: "bob"}},
+		},
+	}
+	postService := application.NewPostService(&FakePostStorage{Posts: []*model.Post{post}}, nil, nil)
+
+	handler := &WebHandler{
+		Logger:      zap.NewNop().Sugar(),
+		PostService: postService,
+		BaseURL:     "https://asperitas.test",
+		Templates:   "../../../web/template/*.html",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/sitemap.xml" {
+			handler.Sitemap(w, r)
+			return
+		}
+		handler.Serve(w, r)
+	}))
+	defer server.Close()
+
+	get := func(t *testing.T, path string) (int, string) {
+		resp, err := http.Get(server.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	t.Run("Post", func(t *testing.T) {
+		status, body := get(t, "/a/music/"+post.ID.Hex())
+		require.Equal(t, http.StatusOK, status)
+		require.Contains(t, body, `<title>Best &#34;albums&#34; &lt;2023&gt; - asperitas</title>`)
+		require.Contains(t, body, `<meta property="og:type" content="article">`)
+		require.Contains(t, body, `<meta property="og:description" content="Share your favourites">`)
+		require.Contains(t, body, `<meta property="og:image" content="https://asperitas.test/uploads/cover.png">`)
+		require.Contains(t, body, `<link rel="canonical" href="https://asperitas.test/a/music/`+post.ID.Hex()+`">`)
+		require.Contains(t, body, `by bob: mine`)
+	})
+
+	t.Run("Category", func(t *testing.T) {
+		status, body := get(t, "/a/music")
+		require.Equal(t, http.StatusOK, status)
+		require.Contains(t, body, `<title>music - asperitas</title>`)
+		require.Contains(t, body, `href="https://asperitas.test/feeds/rss/a/music"`)
+	})
+
+	t.Run("Unknown Post", func(t *testing.T) {
+		status, body := get(t, "/a/music/"+primitive.NewObjectID().Hex())
+		require.Equal(t, http.StatusNotFound, status)
+		require.Contains(t, body, `<title>Post not found - asperitas</title>`)
+		require.Contains(t, body, `<div id="root"></div>`)
+	})
+
+	t.Run("Sitemap", func(t *testing.T) {
+		status, body := get(t, "/sitemap.xml")
+		require.Equal(t, http.StatusOK, status)
+		require.Contains(t, body, `<loc>https://asperitas.test/</loc>`)
+		require.Contains(t, body, `<loc>https://asperitas.test/a/music</loc>`)
+		require.Contains(t, body, `<loc>https://asperitas.test/a/music/`+post.ID.Hex()+`</loc>`)
+		require.Contains(t, body, `<lastmod>2023-05-03T10:00:00Z</lastmod>`)
+	})
+}
@@ -0,0 +1,273 @@
+package route
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/application"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/middleware"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/route/websocket"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+const (
+	wsPongWait         = 60 * time.Second
+	wsPingPeriod       = 25 * time.Second
+	wsSendBuffer       = 64
+	wsMaxSubscriptions = 50
+	wsMaxMessageSize   = 16 << 10
+)
+
+type WebSocketHandler struct {
+	Logger       *zap.SugaredLogger
+	PostService  *application.PostService
+	Broker       model.IEventBroker
+	JWTService   model.IJWTService
+	TokenStorage model.ITokenStorage
+}
+
+// wsRequest is a client message, id is echoed back in the matching ack or error.
+type wsRequest struct {
+	Type   string `json:"type"`
+	ID     string `json:"id,omitempty"`
+	PostID string `json:"postId"`
+	Body   string `json:"body,omitempty"`
+	Parent string `json:"parent,omitempty"`
+	Vote   string `json:"vote,omitempty"`
+}
+
+type wsResponse struct {
+	Type   string      `json:"type"`
+	ID     string      `json:"id,omitempty"`
+	PostID string      `json:"postId,omitempty"`
+	Event  string      `json:"event,omitempty"`
+	Data   interface{} `json:"data,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+type wsSession struct {
+	handler       *WebSocketHandler
+	conn          *websocket.Conn
+	ctx           context.Context
+	cancel        context.CancelFunc
+	send          chan []byte
+	subscriptions map[string]func()
+	closeOnce     *sync.Once
+	closeMu       *sync.Mutex
+	closeCode     int
+	closeReason   string
+}
+
+func (h *WebSocketHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		token = r.URL.Query().Get("token")
+	}
+	author, err := middleware.Authenticate(r.Context(), h.JWTService, h.TokenStorage, token)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		http.Error(w, model.ErrUnAuthorizedHTTP.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	conn, err := websocket.Upgrade(w, r)
+	if err != nil {
+		return
+	}
+	conn.SetReadLimit(wsMaxMessageSize)
+
+	ctx, cancel := context.WithCancel(context.WithValue(r.Context(), middleware.AuthorContextKey, author))
+	session := &wsSession{
+		handler:       h,
+		conn:          conn,
+		ctx:           ctx,
+		cancel:        cancel,
+		send:          make(chan []byte, wsSendBuffer),
+		subscriptions: make(map[string]func()),
+		closeOnce:     new(sync.Once),
+		closeMu:       new(sync.Mutex),
+		closeCode:     websocket.CloseNormal,
+	}
+
+	done := make(chan struct{})
+	go func() {
+		session.writeLoop()
+		close(done)
+	}()
+	session.readLoop()
+
+	session.close(websocket.CloseNormal, "")
+	<-done
+	for _, unsubscribe := range session.subscriptions {
+		unsubscribe()
+	}
+	conn.Close()
+}
+
+func (s *wsSession) readLoop() {
+	s.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	s.conn.SetPongHandler(func() {
+		s.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		messageType, data, err := s.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		s.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+
+		if messageType != websocket.TextMessage {
+			s.close(websocket.CloseUnsupportedData, "text messages only")
+			return
+		}
+
+		request := new(wsRequest)
+		if err := json.Unmarshal(data, request); err != nil {
+			s.reply(&wsResponse{Type: "error", Error: "invalid message"})
+			continue
+		}
+		s.dispatch(request)
+
+		if s.ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (s *wsSession) writeLoop() {
+	ping := time.NewTicker(wsPingPeriod)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			s.closeMu.Lock()
+			code, reason := s.closeCode, s.closeReason
+			s.closeMu.Unlock()
+			s.conn.WriteClose(code, reason)
+			return
+		case message := <-s.send:
+			if err := s.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				s.cancel()
+				s.conn.Close()
+				return
+			}
+		case <-ping.C:
+			if err := s.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				s.cancel()
+				s.conn.Close()
+				return
+			}
+		}
+	}
+}
+
+func (s *wsSession) dispatch(request *wsRequest) {
+	switch request.Type {
+	case "subscribe":
+		s.subscribe(request)
+	case "unsubscribe":
+		postObjectID, err := primitive.ObjectIDFromHex(request.PostID)
+		if err != nil {
+			s.replyError(request, model.ErrInvalidPostID)
+			return
+		}
+		if unsubscribe, ok := s.subscriptions[postObjectID.Hex()]; ok {
+			unsubscribe()
+			delete(s.subscriptions, postObjectID.Hex())
+		}
+		s.reply(&wsResponse{Type: "ack", ID: request.ID, PostID: postObjectID.Hex()})
+	case "comment":
+		post, err := s.handler.PostService.AddComment(s.ctx, request.PostID, request.Body, request.Parent)
+		if err != nil {
+			s.replyError(request, err)
+			return
+		}
+		s.reply(&wsResponse{Type: "ack", ID: request.ID, PostID: post.ID.Hex(), Data: post})
+	case "vote":
+		post, err := s.handler.PostService.Vote(s.ctx, request.PostID, request.Vote)
+		if err != nil {
+			s.replyError(request, err)
+			return
+		}
+		s.reply(&wsResponse{Type: "ack", ID: request.ID, PostID: post.ID.Hex(), Data: post})
+	default:
+		s.reply(&wsResponse{Type: "error", ID: request.ID, Error: "unknown message type"})
+	}
+}
+
+func (s *wsSession) subscribe(request *wsRequest) {
+	postObjectID, err := primitive.ObjectIDFromHex(request.PostID)
+	if err != nil {
+		s.replyError(request, model.ErrInvalidPostID)
+		return
+	}
+	postID := postObjectID.Hex()
+
+	if _, ok := s.subscriptions[postID]; !ok {
+		if len(s.subscriptions) >= wsMaxSubscriptions {
+			s.reply(&wsResponse{Type: "error", ID: request.ID, PostID: postID, Error: "too many subscriptions"})
+			return
+		}
+		// only the posts the user can see may be followed
+		if _, err := s.handler.PostService.GetPostComments(s.ctx, postID); err != nil {
+			s.replyError(request, err)
+			return
+		}
+
+		events, unsubscribe, err := s.handler.Broker.Subscribe(s.ctx, model.PostTopic(postID))
+		if err != nil {
+			s.handler.Logger.Errorw("websocket subscription failed", "post", postID, "error", err)
+			s.replyError(request, err)
+			return
+		}
+		s.subscriptions[postID] = unsubscribe
+
+		go func() {
+			for event := range events {
+				if visible, err := s.handler.PostService.CanSeeEvent(s.ctx, event); err != nil || !visible {
+					continue
+				}
+				s.reply(&wsResponse{Type: "event", PostID: event.PostID, Event: event.Type, Data: event.Data})
+			}
+		}()
+	}
+
+	s.reply(&wsResponse{Type: "ack", ID: request.ID, PostID: postID})
+}
+
+func (s *wsSession) replyError(request *wsRequest, err error) {
+	s.reply(&wsResponse{Type: "error", ID: request.ID, PostID: request.PostID, Error: err.Error()})
+}
+
+// reply queues a message for the writer, a client that cannot keep up is disconnected.
+func (s *wsSession) reply(response *wsResponse) {
+	message, err := json.Marshal(response)
+	if err != nil {
+		return
+	}
+
+	select {
+	case <-s.ctx.Done():
+	case s.send <- message:
+	default:
+		s.close(websocket.ClosePolicyViolation, "client too slow")
+	}
+}
+
+func (s *wsSession) close(code int, reason string) {
+	s.closeOnce.Do(func() {
+		s.closeMu.Lock()
+		s.closeCode = code
+		s.closeReason = reason
+		s.closeMu.Unlock()
+		s.cancel()
+	})
+}
@@ -0,0 +1,253 @@
+package route
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Totus-Floreo/asperitas-on-go/internal/application"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/model/mocks"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/repository/inmemory"
+	"github.com/Totus-Floreo/asperitas-on-go/internal/route/websocket"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// wsClient is a bare websocket client speaking just enough of the protocol to test the handler.
+type wsClient struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func dialWebSocket(t *testing.T, server *httptest.Server, token string) *wsClient {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, err)
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	request := "GET /api/ws?token=" + token + " HTTP/1.1\r\n" +
+		"Host: localhost\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n" +
+		"Sec-WebSocket-Version: 13\r\n\r\n"
+	_, err = conn.Write([]byte(request))
+	require.NoError(t, err)
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+
+	return &wsClient{t: t, conn: conn, reader: reader}
+}
+
+// send writes a masked text frame, client frames must be masked.
+func (c *wsClient) send(request interface{}) {
+	payload, err := json.Marshal(request)
+	require.NoError(c.t, err)
+	require.Less(c.t, len(payload), 1<<16)
+
+	frame := []byte{0x80 | websocket.TextMessage}
+	if len(payload) < 126 {
+		frame = append(frame, 0x80|byte(len(payload)))
+	} else {
+		frame = append(frame, 0x80|126, byte(len(payload)>>8), byte(len(payload)))
+	}
+	mask := []byte{1, 2, 3, 4}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	_, err = c.conn.Write(frame)
+	require.NoError(c.t, err)
+}
+
+func (c *wsClient) readFrame() (int, []byte) {
+	header := make([]byte, 2)
+	_, err := io.ReadFull(c.reader, header)
+	require.NoError(c.t, err)
+
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		extended := make([]byte, 2)
+		_, err = io.ReadFull(c.reader, extended)
+		length = uint64(binary.BigEndian.Uint16(extended))
+	case 127:
+		extended := make([]byte, 8)
+		_, err = io.ReadFull(c.reader, extended)
+		length = binary.BigEndian.Uint64(extended)
+	}
+	require.NoError(c.t, err)
+	payload := make([]byte, length)
+	_, err = io.ReadFull(c.reader, payload)
+	require.NoError(c.t, err)
+	return int(header[0] & 0x0f), payload
+}
+
+// receive returns the next message of the given type, skipping the others.
+func (c *wsClient) receive(kind string) *wsResponse {
+	for {
+		opcode, payload := c.readFrame()
+		require.Equal(c.t, websocket.TextMessage, opcode)
+		response := new(wsResponse)
+		require.NoError(c.t, json.Unmarshal(payload, response))
+		if response.Type == kind {
+			return response
+		}
+	}
+}
+
+func TestWebSocketHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	alice := &model.Author{ID: "id1", Username: "alice"}
+	jwtService := mocks.NewMockIJWTService(ctrl)
+	jwtService.EXPECT().VerifyToken("token").Return(alice, nil).AnyTimes()
+	jwtService.EXPECT().VerifyToken("bad").Return(nil, model.ErrUnAuthorized).AnyTimes()
+	tokenStorage := mocks.NewMockITokenStorage(ctrl)
+	tokenStorage.EXPECT().GetToken(gomock.Any(), "id1").Return("token", nil).AnyTimes()
+
+	post := &model.Post{
+		ID:       primitive.NewObjectID(),
+		Category: "music",
+		Title:    "Live",
+		Type:     "text",
+		Text:     "hi",
+		Author:   &model.Author{ID: "id2", Username: "bob"},
+		Comments: []*model.Comment{},
+		Votes:    []*model.Vote{},
+	}
+	postID := post.ID.Hex()
+	held := &model.Post{ID: primitive.NewObjectID(), Category: "music", Author: &model.Author{ID: "id2", Username: "bob"}, Status: model.PostStatusHeld}
+	broker := inmemory.NewEventBroker()
+	blockStorage := inmemory.NewBlockStorage()
+	require.NoError(t, blockStorage.AddBlock(context.Background(), model.NewBlock(alice.ID, &model.Author{ID: "id5", Username: "carol"}, model.BlockMute)))
+	postService := application.NewPostService(&FakePostStorage{Posts: []*model.Post{post, held}}, inmemory.NewModeratorStorage(), model.TimeControllerFunc(time.Now))
+	postService.SetBroker(broker)
+	postService.SetBlockStorage(blockStorage)
+
+	handler := &WebSocketHandler{
+		Logger:       zap.NewNop().Sugar(),
+		PostService:  postService,
+		Broker:       broker,
+		JWTService:   jwtService,
+		TokenStorage: tokenStorage,
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler.Serve))
+	defer server.Close()
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/api/ws?token=bad")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Subscriptions", func(t *testing.T) {
+		client := dialWebSocket(t, server, "token")
+		defer client.conn.Close()
+
+		client.send(&wsRequest{Type: "subscribe", ID: "1", PostID: "bad"})
+		response := client.receive("error")
+		require.Equal(t, "1", response.ID)
+		require.Equal(t, model.ErrInvalidPostID.Error(), response.Error)
+
+		client.send(&wsRequest{Type: "subscribe", ID: "2", PostID: held.ID.Hex()})
+		response = client.receive("error")
+		require.Equal(t, "2", response.ID)
+		require.Equal(t, model.ErrPostNotFound.Error(), response.Error)
+
+		client.send(&wsRequest{Type: "subscribe", ID: "2", PostID: postID})
+		response = client.receive("ack")
+		require.Equal(t, "2", response.ID)
+		require.Equal(t, postID, response.PostID)
+
+		// the comments of a muted user are not passed on
+		muted, err := model.NewEvent(model.EventCommentAdded, postID, map[string]string{"body": "hi"})
+		require.NoError(t, err)
+		muted.AuthorID = "id5"
+		require.NoError(t, broker.Publish(context.Background(), model.PostTopic(postID), muted))
+
+		event, err := model.NewEvent(model.EventScoreChanged, postID, map[string]int64{"score": 2})
+		require.NoError(t, err)
+		require.NoError(t, broker.Publish(context.Background(), model.PostTopic(primitive.NewObjectID().Hex()), event))
+		require.NoError(t, broker.Publish(context.Background(), model.PostTopic(postID), event))
+		response = client.receive("event")
+		require.Equal(t, postID, response.PostID)
+		require.Equal(t, model.EventScoreChanged, response.Event)
+		require.Equal(t, map[string]interface{}{"score": float64(2)}, response.Data)
+
+		client.send(&wsRequest{Type: "unsubscribe", ID: "3", PostID: postID})
+		require.Equal(t, "3", client.receive("ack").ID)
+		client.send(&wsRequest{Type: "shout", ID: "4"})
+		require.Equal(t, "unknown message type", client.receive("error").Error)
+	})
+
+	t.Run("Comment And Vote", func(t *testing.T) {
+		client := dialWebSocket(t, server, "token")
+		defer client.conn.Close()
+
+		client.send(&wsRequest{Type: "subscribe", ID: "1", PostID: postID})
+		client.receive("ack")
+
+		client.send(&wsRequest{Type: "comment", ID: "2", PostID: postID, Body: "hello"})
+		ack := client.receive("ack")
+		require.Equal(t, "2", ack.ID)
+		require.Equal(t, "hello", ack.Data.(map[string]interface{})["comments"].([]interface{})[0].(map[string]interface{})["body"])
+		require.Equal(t, model.EventCommentAdded, client.receive("event").Event)
+
+		client.send(&wsRequest{Type: "vote", ID: "3", PostID: postID, Vote: "upvote"})
+		ack = client.receive("ack")
+		require.Equal(t, "3", ack.ID)
+		require.Equal(t, float64(1), ack.Data.(map[string]interface{})["score"])
+		event := client.receive("event")
+		require.Equal(t, model.EventScoreChanged, event.Event)
+		require.Equal(t, float64(1), event.Data.(map[string]interface{})["score"])
+
+		client.send(&wsRequest{Type: "vote", ID: "4", PostID: postID, Vote: "sidevote"})
+		response := client.receive("error")
+		require.Equal(t, "4", response.ID)
+		require.Equal(t, model.ErrVotesActionNotImplement.Error(), response.Error)
+	})
+
+	t.Run("Slow Client", func(t *testing.T) {
+		client := dialWebSocket(t, server, "token")
+		defer client.conn.Close()
+
+		client.send(&wsRequest{Type: "subscribe", ID: "1", PostID: postID})
+		client.receive("ack")
+
+		// the client stops reading, once the socket buffers and the send buffer fill up the server gives up on it
+		event, err := model.NewEvent(model.EventScoreChanged, postID, strings.Repeat("x", 16<<10))
+		require.NoError(t, err)
+		for i := 0; i < 4000; i++ {
+			require.NoError(t, broker.Publish(context.Background(), model.PostTopic(postID), event))
+			if i%wsSendBuffer == 0 {
+				time.Sleep(time.Millisecond)
+			}
+		}
+
+		for {
+			opcode, payload := client.readFrame()
+			if opcode == websocket.CloseMessage {
+				require.Equal(t, websocket.ClosePolicyViolation, int(binary.BigEndian.Uint16(payload)))
+				require.Equal(t, "client too slow", string(payload[2:]))
+				return
+			}
+		}
+	})
+}
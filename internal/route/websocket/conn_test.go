@@ -0,0 +1,165 @@
+package websocket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// dial opens a raw client connection and performs the opening handshake.
+func dial(t *testing.T, server *httptest.Server) (net.Conn, *bufio.Reader) {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, err)
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	request := "GET / HTTP/1.1\r\n" +
+		"Host: localhost\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: keep-alive, Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n" +
+		"Sec-WebSocket-Version: 13\r\n\r\n"
+	_, err = conn.Write([]byte(request))
+	require.NoError(t, err)
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	require.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", resp.Header.Get("Sec-WebSocket-Accept"))
+
+	return conn, reader
+}
+
+func writeFrame(t *testing.T, conn net.Conn, fin bool, opcode int, payload []byte) {
+	first := byte(opcode)
+	if fin {
+		first |= 0x80
+	}
+	frame := []byte{first, 0x80 | byte(len(payload))}
+	mask := []byte{1, 2, 3, 4}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	_, err := conn.Write(frame)
+	require.NoError(t, err)
+}
+
+func readFrame(t *testing.T, reader *bufio.Reader) (int, []byte) {
+	header := make([]byte, 2)
+	_, err := io.ReadFull(reader, header)
+	require.NoError(t, err)
+	require.Zero(t, header[1]&0x80, "server frames must not be masked")
+
+	length := int(header[1] & 0x7f)
+	if length == 126 {
+		extended := make([]byte, 2)
+		_, err := io.ReadFull(reader, extended)
+		require.NoError(t, err)
+		length = int(binary.BigEndian.Uint16(extended))
+	}
+	payload := make([]byte, length)
+	_, err = io.ReadFull(reader, payload)
+	require.NoError(t, err)
+	return int(header[0] & 0x0f), payload
+}
+
+func TestConn(t *testing.T) {
+	pongs := make(chan struct{}, 1)
+	serverErrors := make(chan error, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetReadLimit(32)
+		conn.SetPongHandler(func() { pongs <- struct{}{} })
+
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				serverErrors <- err
+				return
+			}
+			if err := conn.WriteMessage(messageType, data); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	t.Run("Bad Handshake", func(t *testing.T) {
+		resp, err := http.Get(server.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusUpgradeRequired, resp.StatusCode)
+	})
+
+	t.Run("Echo Fragmented Message", func(t *testing.T) {
+		conn, reader := dial(t, server)
+		defer conn.Close()
+
+		writeFrame(t, conn, false, TextMessage, []byte("hel"))
+		writeFrame(t, conn, true, PingMessage, []byte("p"))
+		writeFrame(t, conn, true, ContinuationMessage, []byte("lo"))
+
+		opcode, payload := readFrame(t, reader)
+		require.Equal(t, PongMessage, opcode)
+		require.Equal(t, "p", string(payload))
+
+		opcode, payload = readFrame(t, reader)
+		require.Equal(t, TextMessage, opcode)
+		require.Equal(t, "hello", string(payload))
+
+		writeFrame(t, conn, true, PongMessage, nil)
+		select {
+		case <-pongs:
+		case <-time.After(time.Second):
+			t.Fatal("pong handler was not called")
+		}
+
+		writeFrame(t, conn, true, CloseMessage, []byte{0x03, 0xe8})
+		opcode, payload = readFrame(t, reader)
+		require.Equal(t, CloseMessage, opcode)
+		require.Equal(t, CloseNormal, int(binary.BigEndian.Uint16(payload)))
+
+		var closeErr *CloseError
+		require.True(t, errors.As(<-serverErrors, &closeErr))
+		require.Equal(t, CloseNormal, closeErr.Code)
+	})
+
+	t.Run("Message Too Big", func(t *testing.T) {
+		conn, reader := dial(t, server)
+		defer conn.Close()
+
+		writeFrame(t, conn, true, TextMessage, []byte(strings.Repeat("a", 64)))
+
+		opcode, payload := readFrame(t, reader)
+		require.Equal(t, CloseMessage, opcode)
+		require.Equal(t, CloseMessageTooBig, int(binary.BigEndian.Uint16(payload)))
+		<-serverErrors
+	})
+
+	t.Run("Invalid UTF-8", func(t *testing.T) {
+		conn, reader := dial(t, server)
+		defer conn.Close()
+
+		writeFrame(t, conn, true, TextMessage, []byte{0xff, 0xfe})
+
+		opcode, payload := readFrame(t, reader)
+		require.Equal(t, CloseMessage, opcode)
+		require.Equal(t, CloseInvalidPayload, int(binary.BigEndian.Uint16(payload)))
+		<-serverErrors
+	})
+}